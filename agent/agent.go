@@ -26,6 +26,29 @@ var languageMap = map[string]string{
 	"ru":      "Пожалуйста, ответьте на русском языке.",
 }
 
+// GetSamplingParams 返回 agent 使用的采样参数，优先级为 overrides > agent 元数据 > 全局配置
+func GetSamplingParams(name string, overrides llm.SamplingParams) (llm.SamplingParams, error) {
+	values := make(map[string]string, len(llm.SamplingParamNames))
+	for _, param := range llm.SamplingParamNames {
+		values[param] = config.GetConfig(param)
+	}
+	params, err := llm.ParseSamplingParams(values)
+	if err != nil {
+		return overrides, err
+	}
+
+	if name == "" {
+		name = config.GetConfig("default_agent")
+		if name == "" {
+			name = DEFAULT_AGENT
+		}
+	}
+	if a, ok := GetAgent(name); ok {
+		params = params.Merge(a.SamplingParams)
+	}
+	return params.Merge(overrides), nil
+}
+
 // GetAgentMessages 返回预设的 agent 系统消息
 func GetAgentMessages(name string) []llm.Message {
 
@@ -37,6 +60,10 @@ func GetAgentMessages(name string) []llm.Message {
 	}
 	messages := make([]llm.Message, 0)
 	content := ShowAgentContent(name)
+	if a, ok := GetAgent(name); ok {
+		// 去掉元数据，参数使用默认值
+		content, _ = a.Render(nil)
+	}
 	// 添加系统角色消息，定义 agent 的行为和能力
 	messages = append(messages, llm.Message{
 		Role:    "system",
@@ -0,0 +1,130 @@
+package agent
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/sjzsdu/wn/llm"
+	"gopkg.in/yaml.v3"
+)
+
+// Argument agent 元数据中声明的参数，在正文中以 {{name}} 引用
+type Argument struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Required    bool   `yaml:"required"`
+	Default     string `yaml:"default"`
+}
+
+// Metadata agent 文件开头两行 --- 之间的 YAML 元数据
+type Metadata struct {
+	Description string     `yaml:"description"`
+	Arguments   []Argument `yaml:"arguments"`
+	// 采样参数，如 temperature: 0，覆盖全局配置
+	llm.SamplingParams `yaml:",inline"`
+}
+
+// Agent 解析后的 agent
+type Agent struct {
+	Name   string
+	System bool // 是否为内置的系统 agent
+	Metadata
+	Body string
+}
+
+var placeholderRe = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_-]+)\s*\}\}`)
+
+// ParseAgent 解析 agent 文件内容，没有元数据时整个文件都是正文
+func ParseAgent(name, content string) (Agent, error) {
+	a := Agent{Name: name, Body: content}
+
+	normalized := strings.ReplaceAll(content, "\r\n", "\n")
+	if !strings.HasPrefix(normalized, "---\n") {
+		return a, nil
+	}
+	end := strings.Index(normalized[4:], "\n---")
+	if end < 0 {
+		return a, nil
+	}
+
+	header := normalized[4 : 4+end]
+	body := strings.TrimPrefix(normalized[4+end+4:], "\n")
+	if err := yaml.Unmarshal([]byte(header), &a.Metadata); err != nil {
+		return a, fmt.Errorf("invalid metadata in agent %s: %v", name, err)
+	}
+	a.Body = body
+	return a, nil
+}
+
+// GetAgent 获取解析后的 agent，优先从用户目录获取
+func GetAgent(name string) (Agent, bool) {
+	content, system := userAgents[name], false
+	if content == "" {
+		content, system = systemAgents[name], true
+	}
+	if content == "" {
+		return Agent{}, false
+	}
+
+	a, err := ParseAgent(name, content)
+	if err != nil {
+		fmt.Printf("Warning - %v\n", err)
+	}
+	a.System = system
+	return a, true
+}
+
+// AllAgents 返回所有 agent，同名时用户 agent 覆盖系统 agent，按名称排序
+func AllAgents() []Agent {
+	names := make(map[string]bool)
+	for name := range systemAgents {
+		names[name] = true
+	}
+	for name := range userAgents {
+		names[name] = true
+	}
+
+	agents := make([]Agent, 0, len(names))
+	for name := range names {
+		if a, ok := GetAgent(name); ok {
+			agents = append(agents, a)
+		}
+	}
+	sort.Slice(agents, func(i, j int) bool {
+		return agents[i].Name < agents[j].Name
+	})
+	return agents
+}
+
+// Render 用参数替换正文中的 {{name}} 占位符
+// 未提供的参数使用默认值，缺少必填参数时返回错误，但仍返回替换后的正文
+func (a Agent) Render(args map[string]string) (string, error) {
+	values := make(map[string]string, len(a.Arguments))
+	var missing []string
+	for _, arg := range a.Arguments {
+		value, ok := args[arg.Name]
+		if !ok || value == "" {
+			value = arg.Default
+		}
+		if value == "" && arg.Required {
+			missing = append(missing, arg.Name)
+		}
+		values[arg.Name] = value
+	}
+
+	body := placeholderRe.ReplaceAllStringFunc(a.Body, func(m string) string {
+		name := placeholderRe.FindStringSubmatch(m)[1]
+		if value, ok := values[name]; ok {
+			return value
+		}
+		return m
+	})
+	body = strings.TrimSpace(body)
+
+	if len(missing) > 0 {
+		return body, fmt.Errorf("missing required arguments: %s", strings.Join(missing, ", "))
+	}
+	return body, nil
+}
@@ -0,0 +1,65 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/sjzsdu/wn/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAgent(t *testing.T) {
+	content := "---\ndescription: 审查代码\narguments:\n  - name: code\n    description: 代码\n    required: true\n  - name: lang\n    default: go\n---\n审查下面的 {{lang}} 代码：\n\n{{ code }}\n{{unknown}}\n"
+
+	a, err := ParseAgent("review", content)
+	assert.NoError(t, err)
+	assert.Equal(t, "审查代码", a.Description)
+	assert.Len(t, a.Arguments, 2)
+	assert.True(t, a.Arguments[0].Required)
+
+	text, err := a.Render(map[string]string{"code": "x := 1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "审查下面的 go 代码：\n\nx := 1\n{{unknown}}", text)
+
+	text, err = a.Render(nil)
+	assert.Error(t, err)
+	assert.Equal(t, "审查下面的 go 代码：\n\n\n{{unknown}}", text)
+}
+
+func TestParseAgent_NoMetadata(t *testing.T) {
+	a, err := ParseAgent("chat", "你是一个AI助手。\n---\n")
+	assert.NoError(t, err)
+	assert.Empty(t, a.Arguments)
+	assert.Equal(t, "你是一个AI助手。\n---\n", a.Body)
+
+	for name := range systemAgents {
+		_, err := ParseAgent(name, systemAgents[name])
+		assert.NoError(t, err, name)
+	}
+}
+
+func TestParseAgent_SamplingParams(t *testing.T) {
+	a, err := ParseAgent("review", "---\ndescription: 审查代码\ntemperature: 0\nseed: 7\nstop:\n  - END\n---\n审查代码")
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, *a.Temperature)
+	assert.Equal(t, 7, *a.Seed)
+	assert.Equal(t, []string{"END"}, a.Stop)
+	assert.Nil(t, a.TopP)
+}
+
+func TestGetSamplingParams(t *testing.T) {
+	userAgents["sampling-test"] = "---\ntemperature: 0.2\ntop_p: 0.5\n---\n测试"
+	defer delete(userAgents, "sampling-test")
+	t.Setenv("WN_TEMPERATURE", "0.8")
+	t.Setenv("WN_SEED", "1")
+
+	override := 0.9
+	params, err := GetSamplingParams("sampling-test", llm.SamplingParams{TopP: &override})
+	assert.NoError(t, err)
+	assert.Equal(t, 0.2, *params.Temperature)
+	assert.Equal(t, 0.9, *params.TopP)
+	assert.Equal(t, 1, *params.Seed)
+
+	t.Setenv("WN_SEED", "x")
+	_, err = GetSamplingParams("sampling-test", llm.SamplingParams{})
+	assert.Error(t, err)
+}
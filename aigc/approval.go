@@ -0,0 +1,212 @@
+package aigc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/sjzsdu/wn/helper"
+	"github.com/sjzsdu/wn/lang"
+	"github.com/sjzsdu/wn/llm"
+	"github.com/sjzsdu/wn/wnmcp"
+)
+
+// ApprovalDecision 用户对工具调用的授权结果
+type ApprovalDecision int
+
+const (
+	ApprovalDeny   ApprovalDecision = iota // 拒绝执行
+	ApprovalOnce                           // 仅允许本次
+	ApprovalAlways                         // 本次会话内始终允许
+)
+
+// ToolApproval 描述一次等待授权的工具调用
+type ToolApproval struct {
+	Server    string                 // 工具所属的 MCP 服务器
+	Tool      string                 // 服务器上的原始工具名
+	Name      string                 // 暴露给大模型的工具名
+	Arguments map[string]interface{} // 调用参数
+}
+
+// Approver 询问是否允许执行工具调用，拒绝时可以返回发送给大模型的原因
+type Approver func(ctx context.Context, req ToolApproval) (ApprovalDecision, string, error)
+
+// toolGate 位于大模型与 Host.CallTool 之间的权限层
+type toolGate struct {
+	host     *wnmcp.Host
+	yolo     bool
+	approver Approver
+	always   map[string]bool
+	mu       sync.Mutex
+}
+
+func newToolGate(host *wnmcp.Host, yolo bool, approver Approver) *toolGate {
+	if approver == nil {
+		approver = TerminalApprover
+	}
+	return &toolGate{
+		host:     host,
+		yolo:     yolo,
+		approver: approver,
+		always:   make(map[string]bool),
+	}
+}
+
+// check 返回是否允许执行工具调用，以及拒绝的原因
+func (g *toolGate) check(ctx context.Context, toolCall llm.ToolCall) (bool, string, error) {
+	if g.yolo {
+		return true, "", nil
+	}
+
+	server, tool, err := g.host.ResolveTool(ctx, toolCall.Function)
+	if err != nil {
+		// 工具不存在时交给 CallTool 返回错误
+		return true, "", nil
+	}
+	if g.host.IsAutoApproved(server, tool) {
+		return true, "", nil
+	}
+
+	key := server + "/" + tool
+	g.mu.Lock()
+	allowed := g.always[key]
+	g.mu.Unlock()
+	if allowed {
+		return true, "", nil
+	}
+
+	decision, reason, err := g.approver(ctx, ToolApproval{
+		Server:    server,
+		Tool:      tool,
+		Name:      toolCall.Function,
+		Arguments: toolCall.Arguments,
+	})
+	if err != nil {
+		return false, "", err
+	}
+
+	switch decision {
+	case ApprovalAlways:
+		g.mu.Lock()
+		g.always[key] = true
+		g.mu.Unlock()
+		return true, "", nil
+	case ApprovalOnce:
+		return true, "", nil
+	default:
+		return false, reason, nil
+	}
+}
+
+// TerminalApprover 在终端中显示工具名和参数，并询问用户是否执行
+func TerminalApprover(ctx context.Context, req ToolApproval) (ApprovalDecision, string, error) {
+	fmt.Printf("\n%s: %s (%s)\n", lang.T("Tool call requires approval"), req.Name, req.Server)
+	args, err := json.MarshalIndent(req.Arguments, "", "  ")
+	if err == nil {
+		fmt.Println(string(args))
+	}
+
+	for {
+		if ctx.Err() != nil {
+			return ApprovalDeny, "", ctx.Err()
+		}
+
+		input, err := helper.InputString(lang.T("Allow? [y] once / [a] always / [n] deny") + ": ")
+		if errors.Is(err, helper.ErrEmptyInput) {
+			continue
+		}
+		// stdin 关闭或没有终端时无法确认，拒绝执行
+		if err != nil {
+			return ApprovalDeny, "", err
+		}
+
+		switch strings.ToLower(strings.TrimSpace(input)) {
+		case "y", "yes":
+			return ApprovalOnce, "", nil
+		case "a", "always":
+			return ApprovalAlways, "", nil
+		case "n", "no", "quit":
+			reason, _ := helper.InputString(lang.T("Reason for the model (optional)") + ": ")
+			return ApprovalDeny, strings.TrimSpace(reason), nil
+		}
+	}
+}
+
+// NewSamplingApprover 与工具调用的授权方式一致，yolo 为 true 时不询问直接允许
+func NewSamplingApprover(yolo bool) wnmcp.SamplingApprover {
+	if yolo {
+		return nil
+	}
+	return TerminalSamplingApprover
+}
+
+// TerminalSamplingApprover 在终端中显示服务器发来的 sampling 请求，并询问用户是否调用大模型
+func TerminalSamplingApprover(ctx context.Context, server string, req mcp.CreateMessageRequest) (bool, error) {
+	fmt.Printf("\n%s: %s\n", lang.T("MCP server requests LLM sampling"), server)
+	if req.Params.SystemPrompt != "" {
+		fmt.Printf("[system] %s\n", req.Params.SystemPrompt)
+	}
+	for _, msg := range req.Params.Messages {
+		content, err := json.Marshal(msg.Content)
+		if err != nil {
+			continue
+		}
+		fmt.Printf("[%s] %s\n", msg.Role, content)
+	}
+
+	for {
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
+
+		input, err := helper.InputString(lang.T("Allow? [y] yes / [n] no") + ": ")
+		if errors.Is(err, helper.ErrEmptyInput) {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+
+		switch strings.ToLower(strings.TrimSpace(input)) {
+		case "y", "yes":
+			return true, nil
+		case "n", "no", "quit":
+			return false, nil
+		}
+	}
+}
+
+// callTool 经过权限检查后执行工具调用，并返回发送给大模型的 tool 消息
+func (c *Chat) callTool(ctx context.Context, toolCall llm.ToolCall) (llm.Message, error) {
+	msg := llm.Message{
+		Role:       "tool",
+		ToolCallId: toolCall.ID,
+	}
+
+	approved, reason, err := c.gate.check(ctx, toolCall)
+	if err != nil {
+		// 无法确认授权时按拒绝处理，仍然返回 tool 消息以保持对话完整
+		msg.Content = fmt.Sprintf("The call to tool %s was denied: %v", toolCall.Function, err)
+		msg.IsError = true
+		return msg, err
+	}
+	if !approved {
+		msg.Content = fmt.Sprintf("The user denied the call to tool %s.", toolCall.Function)
+		if reason != "" {
+			msg.Content += " Reason: " + reason
+		}
+		return msg, nil
+	}
+
+	toolContent, err := c.host.CallTool(ctx, wnmcp.NewToolCallRequest(toolCall.Function, toolCall.Arguments))
+	if err != nil {
+		msg.Content = fmt.Sprintf("Tool %s failed: %v", toolCall.Function, err)
+		msg.IsError = true
+		return msg, err
+	}
+	return wnmcp.ToolResultMessage(toolCall.ID, toolContent), nil
+}
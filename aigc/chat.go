@@ -23,6 +23,7 @@ func defaultOptions() ChatOptions {
 			Model:          "",
 			MaxTokens:      0,
 			ResponseFormat: "text",
+			Task:           llm.TaskChat,
 		},
 	}
 }
@@ -37,11 +38,18 @@ func NewChat(opts ChatOptions, host *wnmcp.Host) (*Chat, error) {
 		return nil, fmt.Errorf("failed to get LLM provider: %v", err)
 	}
 
+	// 合并全局配置和 agent 元数据中的采样参数
+	options.Request.SamplingParams, err = agent.GetSamplingParams(options.UseAgent, options.Request.SamplingParams)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sampling parameters: %v", err)
+	}
+
 	return &Chat{
 		options:    options,
 		msgManager: message.New(),
 		provider:   provider,
 		host:       host,
+		gate:       newToolGate(host, options.Yolo, options.Approver),
 	}, nil
 }
 
@@ -104,21 +112,16 @@ func (c *Chat) Complete(ctx context.Context, content string) (string, error) {
 				return "", ctx.Err()
 			}
 
-			toolContent, err := c.host.CallTool(ctx, wnmcp.NewToolCallRequest(toolCall.Function, toolCall.Arguments))
+			msg, err := c.callTool(ctx, toolCall)
 			if err != nil {
 				return "", fmt.Errorf("工具调用失败: %w", err)
 			}
 
 			if share.GetDebug() {
-				helper.PrintWithLabel("Tool call", toolCall, toolContent)
+				helper.PrintWithLabel("Tool call", toolCall, msg.Content)
 			}
 
-			msg := &llm.Message{
-				Role:       "tool",
-				Content:    wnmcp.ToolCallResultToString(toolContent),
-				ToolCallId: toolCall.ID,
-			}
-			c.msgManager.Append(*msg)
+			c.msgManager.Append(msg)
 		}
 
 		// 使用相同的上下文继续递归调用
@@ -161,6 +164,11 @@ func (c *Chat) getContextMessages() []llm.Message {
 	return messages
 }
 
+// GetProvider 返回聊天使用的提供商
+func (c *Chat) GetProvider() llm.Provider {
+	return c.provider
+}
+
 // GetMessages 获取聊天历史
 func (c *Chat) GetMessages() []llm.Message {
 	return c.msgManager.GetAll()
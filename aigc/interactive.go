@@ -3,6 +3,8 @@ package aigc
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/sjzsdu/wn/helper"
@@ -10,7 +12,6 @@ import (
 	"github.com/sjzsdu/wn/lang"
 	"github.com/sjzsdu/wn/llm"
 	"github.com/sjzsdu/wn/share"
-	"github.com/sjzsdu/wn/wnmcp"
 )
 
 type InteractiveOptions struct {
@@ -26,6 +27,7 @@ func (c *Chat) StartInteractiveSession(ctx context.Context, opts InteractiveOpti
 
 	fmt.Println(lang.T("Start chatting with AI") + " (" + lang.T("Enter 'quit' or 'q' to end the conversation") + ")")
 	fmt.Println(lang.T("Tips: Type 'vim' or press Ctrl+V to open vim for multi-line input"))
+	fmt.Println(lang.T("Tips: Type '/image <path>' to attach an image to the next message"))
 	fmt.Println(lang.T("Using model")+":", c.provider.GetModel())
 
 	messages := c.GetMessages()
@@ -48,6 +50,10 @@ func (c *Chat) StartInteractiveSession(ctx context.Context, opts InteractiveOpti
 		}
 
 		// 特殊命令处理
+		if command, path, _ := strings.Cut(input, " "); command == "/image" {
+			c.attachImage(path)
+			continue
+		}
 		switch input {
 		case "debug":
 			c.outputDebug()
@@ -73,6 +79,10 @@ func (c *Chat) processInteraction(ctx context.Context, input string, opts Intera
 			Role:    "user",
 			Content: input,
 		}
+		if len(c.attachments) > 0 {
+			*msg = llm.NewUserMessage(append(c.attachments, llm.TextPart(input))...)
+			c.attachments = nil
+		}
 		if c.options.Hooks.BeforeSend != nil {
 			if err := c.options.Hooks.BeforeSend(ctx, msg); err != nil {
 				return err
@@ -100,17 +110,25 @@ func (c *Chat) processInteraction(ctx context.Context, input string, opts Intera
 		// 执行响应前钩子
 		if c.options.Hooks.BeforeResponse != nil {
 			if err := c.options.Hooks.BeforeResponse(ctx, &req); err != nil {
+				completed <- err
+				loadingDone <- true
 				return
 			}
 		}
 
+		// hookErr 响应后钩子返回的错误，此时不保存助手消息
+		var hookErr error
 		err := c.provider.CompleteStream(ctx, req, func(resp llm.StreamResponse) {
 			if !responseStarted {
 				loadingDone <- true
 				responseStarted = true
 				<-loadingDone
 			}
-			if !resp.Done {
+			if resp.Reasoning != "" {
+				if err := opts.Renderer.WriteReasoning(resp.Reasoning); err != nil {
+					helper.PrintWithLabel("Error writing stream", err)
+				}
+			} else if !resp.Done {
 				fullContent.WriteString(resp.Content)
 				if err := opts.Renderer.WriteStream(resp.Content); err != nil {
 					helper.PrintWithLabel("Error writing stream", err)
@@ -131,15 +149,13 @@ func (c *Chat) processInteraction(ctx context.Context, input string, opts Intera
 					c.msgManager.Append(*msg)
 					for _, toolCall := range resp.Response.ToolCalls {
 						helper.PrintWithLabel("Tool call", toolCall)
-						toolContent, _ := c.host.CallTool(ctx, wnmcp.NewToolCallRequest(toolCall.Function, toolCall.Arguments))
-						toolResult := wnmcp.ToolCallResultToString(toolContent)
-						helper.PrintWithLabel("Tool call result", toolResult)
-						msg := &llm.Message{
-							Role:       "tool",
-							Content:    toolResult,
-							ToolCallId: toolCall.ID,
+						msg, err := c.callTool(ctx, toolCall)
+						if err != nil && ctx.Err() != nil {
+							completed <- err
+							return
 						}
-						c.msgManager.Append(*msg)
+						helper.PrintWithLabel("Tool call result", msg.Content)
+						c.msgManager.Append(msg)
 					}
 					// 递归调用以获取最终响应
 					if err := c.processInteraction(ctx, "", opts); err != nil {
@@ -150,7 +166,9 @@ func (c *Chat) processInteraction(ctx context.Context, input string, opts Intera
 				}
 
 				if c.options.Hooks.AfterResponse != nil {
-					c.options.Hooks.AfterResponse(ctx, &req, resp.Response)
+					if hookErr = c.options.Hooks.AfterResponse(ctx, &req, resp.Response); hookErr != nil {
+						return
+					}
 				}
 				c.msgManager.Append(llm.Message{
 					Role:    "assistant",
@@ -158,6 +176,9 @@ func (c *Chat) processInteraction(ctx context.Context, input string, opts Intera
 				})
 			}
 		})
+		if err == nil {
+			err = hookErr
+		}
 		completed <- err
 		if !responseStarted {
 			loadingDone <- true
@@ -165,6 +186,12 @@ func (c *Chat) processInteraction(ctx context.Context, input string, opts Intera
 	}()
 
 	if err := <-completed; err != nil {
+		// 请求失败时不保留带图片的消息，避免模型不支持图片时之后的请求都失败
+		if messages := c.GetMessages(); len(messages) > 0 {
+			if last := messages[len(messages)-1]; last.Role == "user" && last.HasMedia() {
+				c.msgManager.RemoveLast()
+			}
+		}
 		return c.handleStreamError(err, responseStarted)
 	}
 
@@ -172,6 +199,28 @@ func (c *Chat) processInteraction(ctx context.Context, input string, opts Intera
 	return nil
 }
 
+// attachImage 读取本地图片，随下一条消息发送
+func (c *Chat) attachImage(path string) {
+	path = strings.Trim(strings.TrimSpace(path), `"'`)
+	if path == "" {
+		fmt.Println(lang.T("Usage: /image <path>"))
+		return
+	}
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, rest)
+		}
+	}
+
+	part, err := llm.ImagePartFromFile(path)
+	if err != nil {
+		fmt.Printf(lang.T("Failed to attach image: %v\n"), err)
+		return
+	}
+	c.attachments = append(c.attachments, part)
+	fmt.Printf(lang.T("Attached %s, it will be sent with the next message\n"), path)
+}
+
 func (c *Chat) handleStreamError(err error, responseStarted bool) error {
 	if err == nil {
 		return nil
@@ -29,6 +29,10 @@ type ChatOptions struct {
 	UseAgent     string
 	Hooks        *Hooks
 	Request      llm.CompletionRequest
+	// Yolo 为 true 时跳过工具调用授权，用于非交互式运行
+	Yolo bool
+	// Approver 询问是否允许执行工具调用，为空时使用 TerminalApprover
+	Approver Approver
 }
 
 // Chat 表示一个AI聊天会话
@@ -37,4 +41,7 @@ type Chat struct {
 	msgManager *message.Manager
 	provider   llm.Provider
 	host       *wnmcp.Host
+	gate       *toolGate
+	// attachments 通过 /image 添加、随下一条消息发送的图片
+	attachments []llm.ContentPart
 }
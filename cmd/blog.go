@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"context"
-	"encoding/json" // 添加 JSON 包的导入
 	"fmt"
 	"strings"
 
@@ -28,37 +27,24 @@ func init() {
 	rootCmd.AddCommand(blogCmd)
 }
 
-// parseUpdateOperations 解析响应内容为更新操作数组
-func parseUpdateOperations(resp string) ([]helper.UpdateOperation, error) {
-	resp = strings.TrimSpace(resp)
-	if resp == "" {
-		return nil, nil
-	}
+// blogRepairRetries 修改操作校验失败时要求模型修正的最多次数
+const blogRepairRetries = 2
 
-	// 处理被 ```json 包裹的内容
-	if strings.HasPrefix(resp, "```json") {
-		endIndex := strings.LastIndex(resp, "```")
-		if endIndex > 6 { // "```json" 长度为 7
-			resp = strings.TrimSpace(resp[7:endIndex])
-		}
-	}
+// blogEdit blog agent 返回的修改操作
+type blogEdit struct {
+	Operations []helper.UpdateOperation `json:"operations"`
+}
 
-	// 尝试解析为数组
-	var changes []helper.UpdateOperation
-	err := json.Unmarshal([]byte(resp), &changes)
-	if err == nil {
-		return changes, nil
+// parseUpdateOperations 校验响应内容并解析为更新操作数组，不合法时要求模型修正
+func parseUpdateOperations(ctx context.Context, provider llm.Provider, req llm.CompletionRequest, resp string) ([]helper.UpdateOperation, error) {
+	if strings.TrimSpace(resp) == "" {
+		return nil, nil
 	}
-
-	// 如果解析数组失败，尝试解析单个对象
-	var singleChange helper.UpdateOperation
-	err = json.Unmarshal([]byte(resp), &singleChange)
+	edit, err := llm.RepairJSON[blogEdit](ctx, provider, req, resp, blogRepairRetries)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse response: %v", err)
+		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
-
-	// 将单个对象转换为数组返回
-	return []helper.UpdateOperation{singleChange}, nil
+	return edit.Operations, nil
 }
 
 func runBlog(cmd *cobra.Command, args []string) {
@@ -66,6 +52,11 @@ func runBlog(cmd *cobra.Command, args []string) {
 		fmt.Println("the output paramter is required")
 		return
 	}
+	sampling, err := GetSamplingParams()
+	if err != nil {
+		fmt.Printf("invalid sampling parameters: %v\n", err)
+		return
+	}
 
 	fileContent, err := helper.GetFileContent(output)
 
@@ -87,15 +78,19 @@ func runBlog(cmd *cobra.Command, args []string) {
 	fmt.Printf("预览地址: %s\n", previewURL)
 
 	// 创建聊天实例
-	chat, err := aigc.NewChat(aigc.ChatOptions{
+	var chat *aigc.Chat
+	chat, err = aigc.NewChat(aigc.ChatOptions{
 		UseAgent: "blog",
 		Hooks: &aigc.Hooks{
+			// 按修改操作的 schema 请求结构化输出
+			BeforeResponse: func(ctx context.Context, req *llm.CompletionRequest) error {
+				*req = llm.WithJSONSchema(*req, llm.SchemaFor[blogEdit]())
+				return nil
+			},
 			AfterResponse: func(ctx context.Context, req *llm.CompletionRequest, resp *llm.CompletionResponse) error {
-				content := resp.Content
-				changes, errParse := parseUpdateOperations(content)
+				changes, errParse := parseUpdateOperations(ctx, chat.GetProvider(), *req, resp.Content)
 				if errParse != nil {
-					fmt.Printf("解析响应失败: %v\n", errParse)
-					return errParse
+					return fmt.Errorf("解析响应失败: %w", errParse)
 				}
 
 				if changes != nil {
@@ -118,6 +113,7 @@ func runBlog(cmd *cobra.Command, args []string) {
 		},
 		Request: llm.CompletionRequest{
 			ResponseFormat: "json_object",
+			SamplingParams: sampling,
 		},
 	}, nil)
 	if err != nil {
@@ -2,11 +2,15 @@ package cmd
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/sjzsdu/wn/aigc"
 	"github.com/sjzsdu/wn/helper"
 	"github.com/sjzsdu/wn/lang"
+	"github.com/sjzsdu/wn/share"
+	"github.com/sjzsdu/wn/wnmcp"
 	"github.com/spf13/cobra"
 )
 
@@ -17,26 +21,42 @@ var chatCmd = &cobra.Command{
 	Run:   runChat,
 }
 
-var configFile string
+var (
+	configFile string
+	chatYolo   bool
+)
 
 func init() {
 	rootCmd.AddCommand(chatCmd)
 	chatCmd.Flags().StringVar(&configFile, "config", "", lang.T("Config file"))
+	chatCmd.Flags().BoolVar(&chatYolo, "yolo", false, lang.T("Run MCP tools without asking for approval"))
 }
 
 func runChat(cmd *cobra.Command, args []string) {
+	chatOption, err := GetChatOptions()
+	if err != nil {
+		fmt.Printf("%s: %v\n", lang.T("Error"), err)
+		return
+	}
+
 	// 使用 GetMcpHost 获取 host
 	host := GetMcpHost()
 	if host != nil {
 		defer host.Close()
 	}
 
-	tools := host.GetTools(context.Background(), mcp.ListToolsRequest{})
-	chatOption := GetChatOptions()
+	ctx := context.Background()
+	host.StartHealthCheck(ctx, share.DEFAULT_MCP_PING_INTERVAL*time.Second)
+	tools := host.GetTools(ctx, mcp.ListToolsRequest{})
+	printMcpStatus(host, len(tools))
 	chatOption.Request.Tools = tools
-	chat, _ := aigc.NewChat(*chatOption, host)
+	chatOption.Yolo = chatYolo
+	chat, err := aigc.NewChat(*chatOption, host)
+	if err != nil {
+		fmt.Printf("%s: %v\n", lang.T("Error"), err)
+		return
+	}
 	// 启动交互式会话
-	ctx := context.Background()
 	// res, _ := chat.Complete(ctx, "你是什么模型")
 	// println(res)
 
@@ -45,3 +65,21 @@ func runChat(cmd *cobra.Command, args []string) {
 		Debug:    false,
 	})
 }
+
+// printMcpStatus 输出可用的工具数量以及启动失败的服务器
+func printMcpStatus(host *wnmcp.Host, tools int) {
+	statuses := host.Status()
+	if len(statuses) == 0 {
+		return
+	}
+
+	ready := 0
+	for _, status := range statuses {
+		if status.State == wnmcp.StateReady {
+			ready++
+			continue
+		}
+		fmt.Printf("%s %s: %s %s\n", lang.T("MCP server"), status.Server, status.State, status.LastError)
+	}
+	fmt.Printf(lang.T("MCP servers ready: %d/%d, tools available: %d")+"\n", ready, len(statuses), tools)
+}
@@ -3,9 +3,16 @@ package cmd
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/sjzsdu/wn/helper"
 	"github.com/sjzsdu/wn/lang"
 	"github.com/sjzsdu/wn/wnmcp"
 	"github.com/spf13/cobra"
@@ -15,6 +22,8 @@ var (
 	mcpServer string
 	mcpAction string
 	mcpArgs   []string
+	mcpJSON   bool
+	mcpTrace  bool
 )
 
 var clientCmd = &cobra.Command{
@@ -26,147 +35,653 @@ var clientCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(clientCmd)
-	clientCmd.Flags().StringSliceVar(&mcpArgs, "args", nil, lang.T("MCP server command arguments"))
+	clientCmd.Flags().StringArrayVar(&mcpArgs, "args", nil, lang.T("MCP server command arguments"))
 	clientCmd.Flags().StringVar(&mcpAction, "action", "", lang.T("MCP server action"))
 	clientCmd.Flags().StringVar(&mcpServer, "server", "", lang.T("MCP server name"))
+	clientCmd.Flags().BoolVar(&mcpJSON, "json", false, lang.T("Output results as JSON"))
+	clientCmd.Flags().BoolVar(&mcpTrace, "trace", false, lang.T("Print raw MCP requests and responses"))
 }
 
 func runClient(cmd *cobra.Command, args []string) {
-	// 使用 GetMcpHost 获取 host
-	host := GetMcpHost()
+	tracer := wnmcp.NewTracer(os.Stderr)
+	tracer.SetEnabled(mcpTrace)
+
+	metrics := wnmcp.NewMetrics()
+	host := GetMcpHost(wnmcp.WithServerHook(tracer.Hook), wnmcp.WithServerHook(metrics.Hook))
 	if host == nil {
+		fmt.Println(lang.T("No MCP servers configured"))
 		return
 	}
+	defer host.Close()
+	// 注册空的通知处理函数，使钩子能收到服务器通知
+	host.OnNotification(nil)
+
+	ins := &inspector{
+		host:    host,
+		tracer:  tracer,
+		metrics: metrics,
+		out:     os.Stdout,
+		json:    mcpJSON,
+		server:  mcpServer,
+		input:   helper.InputString,
+	}
 
-	// 执行指定的操作
+	ctx := context.Background()
 	if mcpAction == "" {
-		fmt.Println("未指定操作，执行默认操作...")
-		executeDefaultActions(host)
+		ins.repl(ctx)
 		return
 	}
 
-	// 准备参数
-	ctx := context.Background()
-	var actionErr error
+	if err := ins.run(ctx, mcpAction, mcpArgs); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", lang.T("Action failed"), err)
+		os.Exit(1)
+	}
+}
 
-	// 根据 mcpServer 选择客户端
-	if mcpServer != "" {
-		client := host.GetClient(mcpServer)
-		if client == nil {
-			fmt.Printf("未找到指定的服务器 %s\n", mcpServer)
+// inspector 基于 Host 的 MCP 调试工具，支持交互模式和单次执行
+type inspector struct {
+	host        *wnmcp.Host
+	tracer      *wnmcp.Tracer
+	metrics     *wnmcp.Metrics
+	out         io.Writer
+	json        bool
+	server      string // 非空时只操作该服务器
+	interactive bool
+	input       func(prompt string) (string, error)
+}
+
+const inspectorHelp = `servers                   list servers and their status
+ping                      ping all servers
+tools [name]              list tools, or show the schema of a tool
+resources                 list resources
+templates                 list resource templates
+prompts                   list prompts
+call <tool> [json]        call a tool, prompting for arguments when json is omitted
+read <uri>                read a resource
+prompt <name> [json]      render a prompt
+trace on|off              print raw requests and responses
+stats                     show request counts and latency of this session
+json on|off               print results as JSON
+quit                      exit`
+
+// repl 启动交互模式
+func (ins *inspector) repl(ctx context.Context) {
+	ins.interactive = true
+	fmt.Fprintln(ins.out, lang.T("MCP inspector, type 'help' for commands, 'quit' to exit"))
+
+	for {
+		line, err := ins.input("mcp> ")
+		if errors.Is(err, helper.ErrEmptyInput) {
+			continue
+		}
+		if err != nil {
+			fmt.Fprintf(ins.out, "%s: %v\n", lang.T("Error"), err)
 			return
 		}
-		_, actionErr = executeAction(ctx, client, mcpAction, mcpArgs)
-	} else {
-		// 对所有客户端执行操作
-		for name, client := range host.GetAllClients() {
-			fmt.Printf("\n执行 %s 客户端操作...\n", name)
-			if _, err := executeAction(ctx, client, mcpAction, mcpArgs); err != nil {
-				actionErr = err
-			}
+
+		action, args := splitCommand(line)
+		switch action {
+		case "":
+			continue
+		case "quit", "q", "exit":
+			return
+		}
+
+		if err := ins.run(ctx, action, args); err != nil {
+			fmt.Fprintf(ins.out, "%s: %v\n", lang.T("Error"), err)
 		}
 	}
+}
 
-	if actionErr != nil {
-		fmt.Printf("执行操作失败: %v\n", actionErr)
+// splitCommand 把一行输入拆分为命令、第一个参数和剩余部分，剩余部分可以是包含空格的 JSON
+// 参数之间可以有多个空白字符，剩余部分保留原文
+func splitCommand(line string) (string, []string) {
+	action, rest := cutField(line)
+	if action == "" {
+		return "", nil
 	}
+	var args []string
+	if first, rest := cutField(rest); first != "" {
+		args = append(args, first)
+		if rest != "" {
+			args = append(args, rest)
+		}
+	}
+	return action, args
 }
 
-func executeAction(ctx context.Context, client *wnmcp.Client, action string, args []string) (interface{}, error) {
+// cutField 返回第一个以空白分隔的字段和去掉首尾空白的剩余部分
+func cutField(s string) (string, string) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return "", ""
+	}
+	s = strings.TrimSpace(s)
+	return fields[0], strings.TrimSpace(s[len(fields[0]):])
+}
+
+// run 执行一个命令，同时兼容旧版 --action 的名称
+func (ins *inspector) run(ctx context.Context, action string, args []string) error {
 	switch action {
+	case "help":
+		fmt.Fprintln(ins.out, inspectorHelp)
+		return nil
+	case "servers", "status":
+		return ins.listServers()
 	case "ping":
-		return nil, client.Ping(ctx)
-	case "list-resources":
-		return client.ListResources(ctx, mcp.ListResourcesRequest{})
-	case "read-resources":
-		return client.ReadResource(ctx, mcp.ReadResourceRequest{
-			Params: struct {
-				URI       string                 `json:"uri"`
-				Arguments map[string]interface{} `json:"arguments,omitempty"`
-			}{
-				URI: "files://" + client.GetProjectName(),
-			},
-		})
-	case "list-templates":
-		return client.ListResourceTemplates(ctx, mcp.ListResourceTemplatesRequest{})
-	case "list-prompts":
-		return client.ListPrompts(ctx, mcp.ListPromptsRequest{})
-	case "list-tools":
-		return client.ListTools(ctx, mcp.ListToolsRequest{})
-	case "read-resource":
+		return ins.ping(ctx)
+	case "tools", "list-tools":
+		if len(args) > 0 {
+			return ins.showTool(ctx, args[0])
+		}
+		return ins.listTools(ctx)
+	case "resources", "list-resources":
+		return ins.listResources(ctx)
+	case "templates", "list-templates":
+		return ins.listTemplates(ctx)
+	case "prompts", "list-prompts":
+		return ins.listPrompts(ctx)
+	case "call", "call-tool":
 		if len(args) < 1 {
-			return nil, fmt.Errorf("read-resource 需要指定资源路径")
-		}
-		return client.ReadResource(ctx, mcp.ReadResourceRequest{
-			Params: struct {
-				URI       string                 `json:"uri"`
-				Arguments map[string]interface{} `json:"arguments,omitempty"`
-			}{
-				URI: args[0],
-			},
-		})
-	case "call-tool":
-		if len(args) < 2 {
-			return nil, fmt.Errorf("call-tool 需要指定工具名称和参数")
+			return fmt.Errorf("usage: call <tool> [json]")
 		}
-		toolArgs := make(map[string]interface{})
-		if err := json.Unmarshal([]byte(args[1]), &toolArgs); err != nil {
-			return nil, fmt.Errorf("解析工具参数失败: %v", err)
+		return ins.callTool(ctx, args[0], strings.Join(args[1:], " "))
+	case "read", "read-resource", "read-resources":
+		if len(args) < 1 {
+			return fmt.Errorf("usage: read <uri>")
 		}
-		return client.CallTool(ctx, mcp.CallToolRequest{
-			Request: mcp.Request{},
-			Params:  mcp.CallToolRequest{}.Params, // 使用零值初始化内嵌结构体
+		return ins.readResource(ctx, args[0])
+	case "prompt", "get-prompt":
+		if len(args) < 1 {
+			return fmt.Errorf("usage: prompt <name> [json]")
+		}
+		return ins.getPrompt(ctx, args[0], strings.Join(args[1:], " "))
+	case "stats":
+		stats := ins.metrics.Snapshot()
+		return ins.emit(stats, func(w io.Writer) {
+			printMethodStats(w, stats)
 		})
+	case "trace", "json":
+		if len(args) < 1 || (args[0] != "on" && args[0] != "off") {
+			return fmt.Errorf("usage: %s on|off", action)
+		}
+		if action == "trace" {
+			ins.tracer.SetEnabled(args[0] == "on")
+		} else {
+			ins.json = args[0] == "on"
+		}
+		return nil
 	default:
-		return nil, fmt.Errorf("不支持的操作: %s", action)
+		return fmt.Errorf("不支持的操作: %s", action)
 	}
 }
 
-func executeDefaultActions(host *wnmcp.Host) {
-	ctx := context.Background()
-	for name, client := range host.GetAllClients() {
-		fmt.Printf("\n执行 %s 客户端操作...\n", name)
+// emit 在 JSON 模式下输出 value，否则调用 text 输出可读文本
+func (ins *inspector) emit(value interface{}, text func(w io.Writer)) error {
+	if !ins.json {
+		text(ins.out)
+		return nil
+	}
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(ins.out, string(data))
+	return nil
+}
 
-		// 列出资源
-		if result, err := client.ListResources(ctx, mcp.ListResourcesRequest{}); err != nil {
-			fmt.Printf("ListResources 失败: %v\n", err)
-		} else {
-			fmt.Printf("获取到 %d 个资源\n", len(result.Resources))
-		}
-
-		// 读取资源
-		if result, err := client.ReadResource(ctx, mcp.ReadResourceRequest{
-			Params: struct {
-				URI       string                 `json:"uri"`
-				Arguments map[string]interface{} `json:"arguments,omitempty"`
-			}{
-				URI: "files://" + client.GetProjectName(),
-			},
-		}); err != nil {
-			fmt.Printf("ReadResource 失败: %v\n", err)
-		} else {
-			fmt.Printf("读取资源成功: %v\n", result)
+// listServers 输出服务器及其连接状态，还未使用的服务器状态为 idle
+func (ins *inspector) listServers() error {
+	var statuses []wnmcp.ServerStatus
+	for _, status := range ins.host.Status() {
+		if ins.server == "" || status.Server == ins.server {
+			statuses = append(statuses, status)
 		}
+	}
+	return ins.emit(statuses, func(w io.Writer) {
+		for _, status := range statuses {
+			fmt.Fprintf(w, "%s: %s %s\n", status.Server, status.State, status.LastError)
+		}
+	})
+}
 
-		// 列出资源模板
-		if result, err := client.ListResourceTemplates(ctx, mcp.ListResourceTemplatesRequest{}); err != nil {
-			fmt.Printf("ListResourceTemplates 失败: %v\n", err)
+func (ins *inspector) servers() []string {
+	if ins.server != "" {
+		return []string{ins.server}
+	}
+	return ins.host.Servers()
+}
+
+// client 返回 --server 指定的客户端，未指定时返回 nil
+func (ins *inspector) client() (*wnmcp.Client, error) {
+	if ins.server == "" {
+		return nil, nil
+	}
+	client := ins.host.GetClient(ins.server)
+	if client == nil {
+		return nil, fmt.Errorf("未找到指定的服务器 %s", ins.server)
+	}
+	return client, nil
+}
+
+func (ins *inspector) ping(ctx context.Context) error {
+	results := make(map[string]string)
+	for _, server := range ins.servers() {
+		client := ins.host.GetClient(server)
+		if client == nil {
+			results[server] = "not found"
+		} else if err := client.Ping(ctx); err != nil {
+			results[server] = err.Error()
 		} else {
-			fmt.Printf("获取到 %d 个资源模板\n", len(result.ResourceTemplates))
+			results[server] = "ok"
 		}
+	}
+	return ins.emit(results, func(w io.Writer) {
+		for _, server := range ins.servers() {
+			fmt.Fprintf(w, "%s: %s\n", server, results[server])
+		}
+	})
+}
 
-		// 列出提示
-		if result, err := client.ListPrompts(ctx, mcp.ListPromptsRequest{}); err != nil {
-			fmt.Printf("ListPrompts 失败: %v\n", err)
-		} else {
-			fmt.Printf("获取到 %d 个提示\n", len(result.Prompts))
+// tools 返回所有工具，指定了服务器时直接返回该服务器的原始工具名
+func (ins *inspector) tools(ctx context.Context) ([]wnmcp.ServerTool, error) {
+	client, err := ins.client()
+	if err != nil {
+		return nil, err
+	}
+	if client == nil {
+		return ins.host.ListServerTools(ctx)
+	}
+
+	result, err := client.ListTools(ctx, mcp.ListToolsRequest{})
+	if err != nil {
+		return nil, err
+	}
+	tools := make([]wnmcp.ServerTool, 0, len(result.Tools))
+	for _, tool := range result.Tools {
+		tools = append(tools, wnmcp.ServerTool{Server: ins.server, Tool: tool})
+	}
+	return tools, nil
+}
+
+// prompts 返回所有提示，指定了服务器时直接返回该服务器的原始提示名
+func (ins *inspector) prompts(ctx context.Context) ([]wnmcp.ServerPrompt, error) {
+	client, err := ins.client()
+	if err != nil {
+		return nil, err
+	}
+	if client == nil {
+		return ins.host.ListPrompts(ctx, mcp.ListPromptsRequest{})
+	}
+
+	result, err := client.ListPrompts(ctx, mcp.ListPromptsRequest{})
+	if err != nil {
+		return nil, err
+	}
+	prompts := make([]wnmcp.ServerPrompt, 0, len(result.Prompts))
+	for _, prompt := range result.Prompts {
+		prompts = append(prompts, wnmcp.ServerPrompt{Server: ins.server, Prompt: prompt})
+	}
+	return prompts, nil
+}
+
+func (ins *inspector) findTool(ctx context.Context, name string) (*wnmcp.ServerTool, error) {
+	tools, err := ins.tools(ctx)
+	for _, tool := range tools {
+		if tool.Name == name {
+			return &tool, nil
 		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("未找到工具: %s", name)
+}
 
-		// 列出工具
-		if result, err := client.ListTools(ctx, mcp.ListToolsRequest{}); err != nil {
-			fmt.Printf("ListTools 失败: %v\n", err)
-		} else {
-			fmt.Printf("获取到 %d 个工具\n", len(result.Tools))
+func (ins *inspector) listTools(ctx context.Context) error {
+	tools, err := ins.tools(ctx)
+	if err != nil && len(tools) == 0 {
+		return err
+	}
+	return ins.emit(tools, func(w io.Writer) {
+		for _, tool := range tools {
+			fmt.Fprintf(w, "%s [%s] %s\n", tool.Name, tool.Server, firstLine(tool.Description))
+		}
+		printPartialError(w, err)
+	})
+}
+
+func (ins *inspector) showTool(ctx context.Context, name string) error {
+	tool, err := ins.findTool(ctx, name)
+	if err != nil {
+		return err
+	}
+	return ins.emit(tool, func(w io.Writer) {
+		fmt.Fprintf(w, "%s [%s]\n", tool.Name, tool.Server)
+		if tool.Description != "" {
+			fmt.Fprintln(w, tool.Description)
+		}
+		schema, _ := json.MarshalIndent(tool.InputSchema, "", "  ")
+		fmt.Fprintln(w, string(schema))
+	})
+}
+
+func (ins *inspector) listResources(ctx context.Context) error {
+	resources, err := ins.host.ListResources(ctx, mcp.ListResourcesRequest{})
+	if err != nil && len(resources) == 0 {
+		return err
+	}
+	resources = filterServer(resources, ins.server, func(r wnmcp.ServerResource) string { return r.Server })
+	return ins.emit(resources, func(w io.Writer) {
+		for _, resource := range resources {
+			fmt.Fprintf(w, "%s [%s] %s\n", resource.URI, resource.Server, resource.Name)
+		}
+		printPartialError(w, err)
+	})
+}
+
+func (ins *inspector) listTemplates(ctx context.Context) error {
+	templates, err := ins.host.ListResourceTemplates(ctx, mcp.ListResourceTemplatesRequest{})
+	if err != nil && len(templates) == 0 {
+		return err
+	}
+	templates = filterServer(templates, ins.server, func(t wnmcp.ServerResourceTemplate) string { return t.Server })
+	return ins.emit(templates, func(w io.Writer) {
+		for _, template := range templates {
+			raw := ""
+			if template.URITemplate != nil {
+				raw = template.URITemplate.Raw()
+			}
+			fmt.Fprintf(w, "%s [%s] %s\n", raw, template.Server, template.Name)
+		}
+		printPartialError(w, err)
+	})
+}
+
+func (ins *inspector) listPrompts(ctx context.Context) error {
+	prompts, err := ins.prompts(ctx)
+	if err != nil && len(prompts) == 0 {
+		return err
+	}
+	return ins.emit(prompts, func(w io.Writer) {
+		for _, prompt := range prompts {
+			fmt.Fprintf(w, "%s [%s] %s\n", prompt.Name, prompt.Server, firstLine(prompt.Description))
+			for _, arg := range prompt.Arguments {
+				required := ""
+				if arg.Required {
+					required = ", required"
+				}
+				fmt.Fprintf(w, "    %s (string%s) %s\n", arg.Name, required, arg.Description)
+			}
+		}
+		printPartialError(w, err)
+	})
+}
+
+func (ins *inspector) callTool(ctx context.Context, name, rawArgs string) error {
+	var args map[string]interface{}
+	if rawArgs != "" {
+		if err := json.Unmarshal([]byte(rawArgs), &args); err != nil {
+			return fmt.Errorf("解析工具参数失败: %v", err)
+		}
+	} else if ins.interactive {
+		tool, err := ins.findTool(ctx, name)
+		if err != nil {
+			return err
+		}
+		if args, err = promptArguments(tool.InputSchema, ins.input, ins.out); err != nil {
+			return err
+		}
+	}
+
+	request := wnmcp.NewToolCallRequest(name, args)
+	var result *mcp.CallToolResult
+	client, err := ins.client()
+	if err != nil {
+		return err
+	}
+	if client != nil {
+		result, err = client.CallTool(ctx, request)
+	} else {
+		result, err = ins.host.CallTool(ctx, request)
+	}
+	if err != nil {
+		return err
+	}
+
+	return ins.emit(result, func(w io.Writer) {
+		if result.IsError {
+			fmt.Fprintln(w, lang.T("Tool returned an error")+":")
+		}
+		for _, content := range result.Content {
+			fmt.Fprintln(w, wnmcp.ContentToString(content))
+		}
+	})
+}
+
+func (ins *inspector) readResource(ctx context.Context, uri string) error {
+	request := wnmcp.NewReadResourceRequest(uri, nil)
+	var result *mcp.ReadResourceResult
+	client, err := ins.client()
+	if err != nil {
+		return err
+	}
+	if client != nil {
+		result, err = client.ReadResource(ctx, request)
+	} else {
+		result, err = ins.host.ReadResource(ctx, request)
+	}
+	if err != nil {
+		return err
+	}
+
+	return ins.emit(result, func(w io.Writer) {
+		for _, contents := range result.Contents {
+			fmt.Fprintln(w, formatResourceContents(contents))
+		}
+	})
+}
+
+func (ins *inspector) getPrompt(ctx context.Context, name, rawArgs string) error {
+	var args map[string]string
+	if rawArgs != "" {
+		if err := json.Unmarshal([]byte(rawArgs), &args); err != nil {
+			return fmt.Errorf("解析提示参数失败: %v", err)
+		}
+	} else if ins.interactive {
+		prompts, _ := ins.prompts(ctx)
+		for _, prompt := range prompts {
+			if prompt.Name == name {
+				var err error
+				if args, err = promptPromptArguments(prompt.Arguments, ins.input, ins.out); err != nil {
+					return err
+				}
+				break
+			}
+		}
+	}
+
+	request := wnmcp.NewPromptRequest(name, args)
+	var result *mcp.GetPromptResult
+	client, err := ins.client()
+	if err != nil {
+		return err
+	}
+	if client != nil {
+		result, err = client.GetPrompt(ctx, request)
+	} else {
+		result, err = ins.host.GetPrompt(ctx, request)
+	}
+	if err != nil {
+		return err
+	}
+
+	return ins.emit(result, func(w io.Writer) {
+		if result.Description != "" {
+			fmt.Fprintln(w, result.Description)
+		}
+		for _, message := range result.Messages {
+			fmt.Fprintf(w, "[%s] %s\n", message.Role, wnmcp.ContentToString(message.Content))
+		}
+	})
+}
+
+// promptArguments 按照工具的 JSON Schema 逐个询问参数，必填参数优先
+func promptArguments(schema mcp.ToolInputSchema, input func(string) (string, error), out io.Writer) (map[string]interface{}, error) {
+	required := make(map[string]bool)
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if required[names[i]] != required[names[j]] {
+			return required[names[i]]
 		}
+		return names[i] < names[j]
+	})
+
+	args := make(map[string]interface{})
+	for _, name := range names {
+		property, _ := schema.Properties[name].(map[string]interface{})
+		typ, _ := property["type"].(string)
+		if typ == "" {
+			typ = "string"
+		}
+		description, _ := property["description"].(string)
+
+		label := name + " (" + typ
+		if required[name] {
+			label += ", required"
+		}
+		label += ")"
+		if description != "" {
+			label += " " + description
+		}
+
+		for {
+			text, err := readArgument(input, label+": ")
+			if err != nil {
+				return nil, err
+			}
+			if text == "" {
+				if required[name] {
+					continue
+				}
+				break
+			}
+			value, err := parseArgument(typ, text)
+			if err != nil {
+				fmt.Fprintf(out, "%s: %v\n", lang.T("Invalid value"), err)
+				continue
+			}
+			args[name] = value
+			break
+		}
+	}
+	return args, nil
+}
+
+// promptPromptArguments 逐个询问提示的参数
+func promptPromptArguments(arguments []mcp.PromptArgument, input func(string) (string, error), out io.Writer) (map[string]string, error) {
+	args := make(map[string]string)
+	for _, arg := range arguments {
+		label := arg.Name
+		if arg.Required {
+			label += " (required)"
+		}
+		if arg.Description != "" {
+			label += " " + arg.Description
+		}
+		for {
+			text, err := readArgument(input, label+": ")
+			if err != nil {
+				return nil, err
+			}
+			if text == "" && arg.Required {
+				continue
+			}
+			if text != "" {
+				args[arg.Name] = text
+			}
+			break
+		}
+	}
+	return args, nil
+}
+
+// errInputCanceled 输入参数时按下 Ctrl-C 或输入 quit
+var errInputCanceled = errors.New("input canceled")
+
+// readArgument 读取一个参数值，直接回车时返回空字符串，输入 quit 或读取失败时返回错误
+func readArgument(input func(string) (string, error), label string) (string, error) {
+	text, err := input(label)
+	if errors.Is(err, helper.ErrEmptyInput) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if text == "quit" {
+		return "", errInputCanceled
+	}
+	return text, nil
+}
+
+// parseArgument 按照 JSON Schema 的类型把输入转换为对应的值
+func parseArgument(typ, text string) (interface{}, error) {
+	switch typ {
+	case "integer":
+		return strconv.ParseInt(text, 10, 64)
+	case "number":
+		return strconv.ParseFloat(text, 64)
+	case "boolean":
+		return strconv.ParseBool(text)
+	case "array", "object":
+		var value interface{}
+		if err := json.Unmarshal([]byte(text), &value); err != nil {
+			return nil, err
+		}
+		return value, nil
+	default:
+		return text, nil
+	}
+}
+
+// formatResourceContents 文本资源直接输出内容，二进制资源只显示摘要
+func formatResourceContents(contents mcp.ResourceContents) string {
+	if c, ok := contents.(mcp.TextResourceContents); ok {
+		return c.Text
+	}
+	return wnmcp.ResourceContentsToString(contents)
+}
+
+func filterServer[T any](items []T, server string, serverOf func(T) string) []T {
+	if server == "" {
+		return items
+	}
+	var filtered []T
+	for _, item := range items {
+		if serverOf(item) == server {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered
+}
+
+func firstLine(text string) string {
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		return text[:i]
+	}
+	return text
+}
+
+// printPartialError 部分服务器失败时在列表后输出错误
+func printPartialError(w io.Writer, err error) {
+	if err != nil {
+		fmt.Fprintf(w, "%s: %v\n", lang.T("Error"), err)
 	}
 }
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/sjzsdu/wn/helper"
+	"github.com/stretchr/testify/assert"
+)
+
+// scriptedInput 依次返回预设的输入，空字符串模拟直接回车
+func scriptedInput(answers ...string) (func(string) (string, error), *[]string) {
+	var prompts []string
+	return func(prompt string) (string, error) {
+		prompts = append(prompts, prompt)
+		if len(answers) == 0 {
+			return "", fmt.Errorf("no more input")
+		}
+		answer := answers[0]
+		answers = answers[1:]
+		if answer == "" {
+			return "", helper.ErrEmptyInput
+		}
+		return answer, nil
+	}, &prompts
+}
+
+func TestSplitCommand(t *testing.T) {
+	action, args := splitCommand(`  call write_file {"path": "a.txt", "content": "x y"}`)
+	assert.Equal(t, "call", action)
+	assert.Equal(t, []string{"write_file", `{"path": "a.txt", "content": "x y"}`}, args)
+
+	action, args = splitCommand("tools")
+	assert.Equal(t, "tools", action)
+	assert.Empty(t, args)
+
+	action, _ = splitCommand("   ")
+	assert.Equal(t, "", action)
+
+	// 多个空白字符不会产生空参数
+	action, args = splitCommand("call  server\t tool  {\"a\":  1}")
+	assert.Equal(t, "call", action)
+	assert.Equal(t, []string{"server", `tool  {"a":  1}`}, args)
+}
+
+func TestPromptArguments(t *testing.T) {
+	schema := mcp.ToolInputSchema{
+		Type: "object",
+		Properties: map[string]interface{}{
+			"path":    map[string]interface{}{"type": "string", "description": "file path"},
+			"limit":   map[string]interface{}{"type": "integer"},
+			"recurse": map[string]interface{}{"type": "boolean"},
+			"tags":    map[string]interface{}{"type": "array"},
+		},
+		Required: []string{"path"},
+	}
+
+	// path 为空时重新询问，limit 非法时重新询问，recurse 跳过
+	input, prompts := scriptedInput("", "src", "abc", "10", "", `["a","b"]`)
+	out := &bytes.Buffer{}
+	args, err := promptArguments(schema, input, out)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"path":  "src",
+		"limit": int64(10),
+		"tags":  []interface{}{"a", "b"},
+	}, args)
+	assert.Equal(t, "path (string, required) file path: ", (*prompts)[0])
+	assert.Contains(t, out.String(), "abc")
+}
+
+func TestPromptArguments_Cancel(t *testing.T) {
+	schema := mcp.ToolInputSchema{
+		Type:       "object",
+		Properties: map[string]interface{}{"path": map[string]interface{}{"type": "string"}},
+		Required:   []string{"path"},
+	}
+
+	// Ctrl-C 会被读取为 quit
+	input, _ := scriptedInput("", "quit")
+	_, err := promptArguments(schema, input, &bytes.Buffer{})
+	assert.ErrorIs(t, err, errInputCanceled)
+
+	// 读取失败时不再重复询问必填参数
+	input, prompts := scriptedInput("")
+	_, err = promptArguments(schema, input, &bytes.Buffer{})
+	assert.EqualError(t, err, "no more input")
+	assert.Len(t, *prompts, 2)
+
+	arguments := []mcp.PromptArgument{{Name: "topic", Required: true}, {Name: "style"}}
+	input, _ = scriptedInput("go", "")
+	args, err := promptPromptArguments(arguments, input, &bytes.Buffer{})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"topic": "go"}, args)
+
+	input, _ = scriptedInput("quit")
+	_, err = promptPromptArguments(arguments, input, &bytes.Buffer{})
+	assert.ErrorIs(t, err, errInputCanceled)
+}
+
+func TestParseArgument(t *testing.T) {
+	value, err := parseArgument("number", "1.5")
+	assert.NoError(t, err)
+	assert.Equal(t, 1.5, value)
+
+	value, err = parseArgument("object", `{"a":1}`)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"a": float64(1)}, value)
+
+	_, err = parseArgument("boolean", "maybe")
+	assert.Error(t, err)
+
+	value, err = parseArgument("string", "42")
+	assert.NoError(t, err)
+	assert.Equal(t, "42", value)
+}
@@ -17,18 +17,48 @@ var configCmd = &cobra.Command{
 
 var (
 	flagKeys = map[string]string{
-		"lang":             "Set language",
-		"render":           "Set llm response render type",
-		"default_provider": "Set default LLM provider",
-		"default_agent":    "Set default agent",
-		"deepseek_apikey":  "Set DeepSeek API Key",
-		"deepseek_model":   "Set DeepSeek default model",
-		"openai_apikey":    "Set Openai API Key",
-		"openai_model":     "Set Openai default model",
-		"claude_apikey":    "Set Claude API Key",
-		"claude_model":     "Set Claude default model",
-		"qwen_apikey":      "Set Qwen API Key",
-		"qwen_model":       "Set Qwen default model",
+		"lang":                   "Set language",
+		"render":                 "Set llm response render type",
+		"reasoning":              "Set reasoning display: show, collapse or hide",
+		"default_provider":       "Set default LLM provider",
+		"default_agent":          "Set default agent",
+		"deepseek_apikey":        "Set DeepSeek API Key",
+		"deepseek_model":         "Set DeepSeek default model",
+		"openai_apikey":          "Set Openai API Key",
+		"openai_model":           "Set Openai default model",
+		"openai_embedding_model": "Set Openai embedding model",
+		"claude_apikey":          "Set Claude API Key",
+		"claude_model":           "Set Claude default model",
+		"claude_thinking_budget": "Set Claude extended thinking token budget, 0 to disable",
+		"qwen_apikey":            "Set Qwen API Key",
+		"qwen_model":             "Set Qwen default model",
+		"qwen_embedding_model":   "Set Qwen embedding model",
+		"gemini_apikey":          "Set Gemini API Key",
+		"gemini_model":           "Set Gemini default model",
+		"ollama_endpoint":        "Set Ollama or local OpenAI-compatible server url",
+		"ollama_model":           "Set Ollama default model",
+		"ollama_embedding_model": "Set Ollama embedding model",
+		"ollama_api":             "Set local server api: ollama or openai",
+		"deepseek_rpm":           "Set requests per minute limit, 0 for unlimited",
+		"deepseek_tpm":           "Set tokens per minute limit, 0 for unlimited",
+		"openai_rpm":             "Set requests per minute limit, 0 for unlimited",
+		"openai_tpm":             "Set tokens per minute limit, 0 for unlimited",
+		"claude_rpm":             "Set requests per minute limit, 0 for unlimited",
+		"claude_tpm":             "Set tokens per minute limit, 0 for unlimited",
+		"qwen_rpm":               "Set requests per minute limit, 0 for unlimited",
+		"qwen_tpm":               "Set tokens per minute limit, 0 for unlimited",
+		"gemini_rpm":             "Set requests per minute limit, 0 for unlimited",
+		"gemini_tpm":             "Set tokens per minute limit, 0 for unlimited",
+		"temperature":            "Set default LLM sampling temperature",
+		"top_p":                  "Set default LLM nucleus sampling top_p",
+		"stop":                   "Set default LLM stop sequences, comma separated",
+		"seed":                   "Set default LLM sampling seed",
+		"presence_penalty":       "Set default LLM presence penalty",
+		"frequency_penalty":      "Set default LLM frequency penalty",
+		"tool_choice":            "Set default LLM tool choice: auto, none, required or a tool name",
+		"server_tokens":          "Set bearer tokens for wn server sse, comma separated",
+		"sampling_max_tokens":    "Set max tokens for MCP sampling requests",
+		"mcp_audit":              "Set false to disable the MCP audit log in ~/.wn/logs (response bodies are not recorded, logs are kept for 14 days)",
 	}
 	listFlag bool
 )
@@ -2,10 +2,12 @@ package cmd
 
 import (
 	"fmt"
+	"strconv"
 
 	"github.com/sjzsdu/wn/aigc"
 	"github.com/sjzsdu/wn/config"
 	"github.com/sjzsdu/wn/helper"
+	"github.com/sjzsdu/wn/lang"
 	"github.com/sjzsdu/wn/llm"
 	"github.com/sjzsdu/wn/project"
 	"github.com/sjzsdu/wn/share"
@@ -26,7 +28,7 @@ func GetProject() *project.Project {
 	return project
 }
 
-func GetMcpHost() *wnmcp.Host {
+func GetMcpHost(opts ...wnmcp.HostOption) *wnmcp.Host {
 	targetPath, ferr := helper.GetTargetPath(cmdPath, gitURL)
 	if ferr != nil {
 		return nil
@@ -35,7 +37,16 @@ func GetMcpHost() *wnmcp.Host {
 
 	project := GetProject()
 
-	host, err := wnmcp.NewHost(mcpConfig, project)
+	sampler, err := GetSampler()
+	if err != nil {
+		fmt.Printf("%s: %v\n", lang.T("Error"), err)
+		return nil
+	}
+	opts = append([]wnmcp.HostOption{wnmcp.WithSampler(sampler)}, opts...)
+	if config.GetConfig("mcp_audit") != "false" {
+		opts = append(opts, wnmcp.WithServerHook(wnmcp.NewAuditLog(wnmcp.DefaultAuditDir()).Hook))
+	}
+	host, err := wnmcp.NewHost(mcpConfig, project, opts...)
 	if err != nil {
 		fmt.Printf("创建客户端失败: %v\n", err)
 		return nil
@@ -44,7 +55,18 @@ func GetMcpHost() *wnmcp.Host {
 	return host
 }
 
-func GetChatOptions() *aigc.ChatOptions {
+// GetSampler 使用默认的大模型处理 MCP 服务器的 sampling 请求，指定 --yolo 时不询问用户
+func GetSampler() (*wnmcp.Sampler, error) {
+	options, err := GetChatOptions()
+	if err != nil {
+		return nil, err
+	}
+	maxTokens, _ := strconv.Atoi(config.GetConfig("sampling_max_tokens"))
+	return wnmcp.NewSampler(options.ProviderName, options.Request.Model, maxTokens, aigc.NewSamplingApprover(chatYolo)), nil
+}
+
+// GetChatOptions 根据命令行参数和配置返回对话选项，采样参数无效时返回错误
+func GetChatOptions() (*aigc.ChatOptions, error) {
 	// 设置默认值
 	if llmName == "" {
 		// 优先从配置中获取默认提供商
@@ -57,7 +79,8 @@ func GetChatOptions() *aigc.ChatOptions {
 		// 根据提供商获取对应的默认模型
 		modelKey := fmt.Sprintf("%s_model", llmName)
 		llmModel = config.GetConfig(modelKey)
-		if llmModel == "" {
+		// 其他提供商未配置模型时使用提供商自己的默认模型
+		if llmModel == "" && llmName == share.DEFAULT_LLM_NAME {
 			llmModel = share.DEFAULT_LLM_MODEL
 		}
 	}
@@ -68,6 +91,11 @@ func GetChatOptions() *aigc.ChatOptions {
 		llmMessageLimit = share.DEFAULT_LLM_MESSAGES_LIMIT
 	}
 
+	sampling, err := GetSamplingParams()
+	if err != nil {
+		return nil, err
+	}
+
 	return &aigc.ChatOptions{
 		ProviderName: llmName,
 		MessageLimit: llmMessageLimit,
@@ -77,6 +105,16 @@ func GetChatOptions() *aigc.ChatOptions {
 			Model:          llmModel,
 			MaxTokens:      0,
 			ResponseFormat: "text",
+			SamplingParams: sampling,
 		},
+	}, nil
+}
+
+// GetSamplingParams 返回命令行指定的采样参数，参数无效时返回错误
+func GetSamplingParams() (llm.SamplingParams, error) {
+	values := make(map[string]string, len(llmSampling))
+	for name, value := range llmSampling {
+		values[name] = *value
 	}
+	return llm.ParseSamplingParams(values)
 }
@@ -0,0 +1,336 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sjzsdu/wn/helper"
+	"github.com/sjzsdu/wn/lang"
+	"github.com/sjzsdu/wn/wnmcp"
+	"github.com/spf13/cobra"
+)
+
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: lang.T("Manage MCP servers"),
+	Long:  lang.T("Manage MCP servers in the global and project configuration"),
+}
+
+var mcpListCmd = &cobra.Command{
+	Use:   "list",
+	Short: lang.T("List MCP servers"),
+	Args:  cobra.NoArgs,
+	Run:   withError(runMcpList),
+}
+
+var mcpEnableCmd = &cobra.Command{
+	Use:   "enable <name>",
+	Short: lang.T("Enable an MCP server"),
+	Args:  cobra.ExactArgs(1),
+	Run: withError(func(cmd *cobra.Command, args []string) error {
+		return setMcpDisabled(args[0], false)
+	}),
+}
+
+var mcpDisableCmd = &cobra.Command{
+	Use:   "disable <name>",
+	Short: lang.T("Disable an MCP server"),
+	Args:  cobra.ExactArgs(1),
+	Run: withError(func(cmd *cobra.Command, args []string) error {
+		return setMcpDisabled(args[0], true)
+	}),
+}
+
+var mcpAddCmd = &cobra.Command{
+	Use:   "add <name> [-- command args...]",
+	Short: lang.T("Add an MCP server"),
+	Args:  cobra.MinimumNArgs(1),
+	Run:   withError(runMcpAdd),
+}
+
+var mcpStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: lang.T("Summarize MCP request counts and latency from the audit log"),
+	Args:  cobra.NoArgs,
+	Run:   withError(runMcpStats),
+}
+
+var (
+	mcpStatsDays int
+	mcpGlobal    bool
+	mcpURL       string
+	mcpEnv       []string
+	mcpHeaders   []string
+	mcpTimeout   int
+	mcpTransport string
+)
+
+func init() {
+	rootCmd.AddCommand(mcpCmd)
+	mcpCmd.AddCommand(mcpListCmd, mcpEnableCmd, mcpDisableCmd, mcpAddCmd, mcpStatsCmd)
+
+	mcpCmd.PersistentFlags().StringVar(&configFile, "config", "", lang.T("Config file"))
+	for _, cmd := range []*cobra.Command{mcpEnableCmd, mcpDisableCmd, mcpAddCmd} {
+		cmd.Flags().BoolVar(&mcpGlobal, "global", false, lang.T("Edit the global config ~/.wn/mcp.json"))
+	}
+
+	mcpAddCmd.Flags().StringVar(&mcpURL, "url", "", lang.T("SSE server url"))
+	mcpAddCmd.Flags().StringArrayVar(&mcpEnv, "env", nil, lang.T("Environment variable KEY=VALUE"))
+	mcpAddCmd.Flags().StringArrayVar(&mcpHeaders, "header", nil, lang.T("HTTP header Key=Value for SSE servers"))
+	mcpAddCmd.Flags().IntVar(&mcpTimeout, "timeout", 0, lang.T("Request timeout in seconds"))
+	mcpAddCmd.Flags().StringVar(&mcpTransport, "transport", "", lang.T("Transport type: stdio or sse"))
+
+	mcpStatsCmd.Flags().IntVar(&mcpStatsDays, "days", 7, lang.T("Number of days to include"))
+	mcpStatsCmd.Flags().StringVar(&mcpServer, "server", "", lang.T("MCP server name"))
+	mcpStatsCmd.Flags().BoolVar(&mcpJSON, "json", false, lang.T("Output results as JSON"))
+}
+
+// withError 输出命令返回的错误
+func withError(run func(cmd *cobra.Command, args []string) error) func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		if err := run(cmd, args); err != nil {
+			fmt.Printf("%s: %v\n", lang.T("Error"), err)
+		}
+	}
+}
+
+// mcpConfigPaths 返回全局配置和项目配置文件路径
+func mcpConfigPaths() (string, string, error) {
+	targetPath, err := helper.GetTargetPath(cmdPath, gitURL)
+	if err != nil {
+		return "", "", err
+	}
+	return wnmcp.GlobalMCPConfigPath(), wnmcp.ProjectMCPConfigPath(targetPath, configFile), nil
+}
+
+// openMcpConfigFile 打开 --global 指定的配置文件
+func openMcpConfigFile() (*wnmcp.MCPConfigFile, error) {
+	globalPath, projectPath, err := mcpConfigPaths()
+	if err != nil {
+		return nil, err
+	}
+	if mcpGlobal {
+		return wnmcp.OpenMCPConfigFile(globalPath)
+	}
+	return wnmcp.OpenMCPConfigFile(projectPath)
+}
+
+func runMcpList(cmd *cobra.Command, args []string) error {
+	targetPath, err := helper.GetTargetPath(cmdPath, gitURL)
+	if err != nil {
+		return err
+	}
+	globalPath, projectPath, err := mcpConfigPaths()
+	if err != nil {
+		return err
+	}
+
+	config, err := wnmcp.LoadMCPConfig(targetPath, configFile)
+	if err != nil {
+		fmt.Println(lang.T("No MCP servers configured"))
+		return nil
+	}
+
+	global, err := wnmcp.OpenMCPConfigFile(globalPath)
+	if err != nil {
+		return err
+	}
+	project, err := wnmcp.OpenMCPConfigFile(projectPath)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(config.MCPServers))
+	for name := range config.MCPServers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		server := config.MCPServers[name]
+		state := "enabled"
+		if server.Disabled {
+			state = "disabled"
+		}
+
+		var sources []string
+		if global.HasServer(name) {
+			sources = append(sources, "global")
+		}
+		if project.HasServer(name) {
+			sources = append(sources, "project")
+		}
+
+		target := server.Url
+		if server.TransportType != "sse" {
+			target = strings.TrimSpace(server.Command + " " + strings.Join(server.Args, " "))
+		}
+		fmt.Printf("%s\t%s\t%s\t%s\t%s\n", name, state, server.TransportType, strings.Join(sources, "+"), target)
+	}
+	return nil
+}
+
+// setMcpDisabled 修改服务器的 disabled 字段
+// 在项目配置中修改只定义在全局配置中的服务器时，会写入一个只包含 disabled 的覆盖项
+func setMcpDisabled(name string, disabled bool) error {
+	targetPath, err := helper.GetTargetPath(cmdPath, gitURL)
+	if err != nil {
+		return err
+	}
+	config, _ := wnmcp.LoadMCPConfig(targetPath, configFile)
+	if config == nil || !hasMcpServer(config, name) {
+		return fmt.Errorf("未找到 MCP 服务器: %s", name)
+	}
+
+	file, err := openMcpConfigFile()
+	if err != nil {
+		return err
+	}
+	if mcpGlobal && !file.HasServer(name) {
+		return fmt.Errorf("服务器 %s 不在全局配置 %s 中", name, file.Path)
+	}
+	file.SetDisabled(name, disabled)
+	if err := file.Save(); err != nil {
+		return err
+	}
+	fmt.Printf("%s: %s\n", file.Path, name)
+	return nil
+}
+
+func hasMcpServer(config *wnmcp.MCPConfig, name string) bool {
+	_, ok := config.MCPServers[name]
+	return ok
+}
+
+func runMcpAdd(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	server, err := newMcpServerConfig(args[1:], mcpURL, mcpTransport, mcpEnv, mcpHeaders, mcpTimeout)
+	if err != nil {
+		return err
+	}
+
+	file, err := openMcpConfigFile()
+	if err != nil {
+		return err
+	}
+	if err := file.AddServer(name, server); err != nil {
+		return err
+	}
+	if err := file.Save(); err != nil {
+		return err
+	}
+	fmt.Printf("%s: %s\n", file.Path, name)
+	return nil
+}
+
+// newMcpServerConfig 根据命令行参数创建服务器配置，指定了 url 时默认使用 sse
+func newMcpServerConfig(command []string, url, transport string, env, headers []string, timeout int) (wnmcp.MCPServerConfig, error) {
+	server := wnmcp.MCPServerConfig{
+		TransportType: transport,
+		Url:           url,
+		Env:           env,
+		Timeout:       timeout,
+	}
+	if server.TransportType == "" {
+		server.TransportType = "stdio"
+		if url != "" {
+			server.TransportType = "sse"
+		}
+	}
+
+	switch server.TransportType {
+	case "stdio":
+		if len(command) == 0 {
+			return server, fmt.Errorf("stdio 服务器需要指定命令，如 wn mcp add <name> -- npx -y <package>")
+		}
+		server.Command = command[0]
+		server.Args = command[1:]
+	case "sse":
+		if url == "" {
+			return server, fmt.Errorf("sse 服务器需要指定 --url")
+		}
+	default:
+		return server, fmt.Errorf("不支持的传输类型: %s", server.TransportType)
+	}
+
+	for _, env := range env {
+		if !strings.Contains(env, "=") {
+			return server, fmt.Errorf("环境变量格式应为 KEY=VALUE: %s", env)
+		}
+	}
+	for _, header := range headers {
+		key, value, ok := strings.Cut(header, "=")
+		if !ok {
+			return server, fmt.Errorf("header 格式应为 Key=Value: %s", header)
+		}
+		if server.Headers == nil {
+			server.Headers = make(map[string]string)
+		}
+		server.Headers[key] = value
+	}
+	return server, nil
+}
+
+func runMcpStats(cmd *cobra.Command, args []string) error {
+	since := time.Now().AddDate(0, 0, -mcpStatsDays)
+	records, err := wnmcp.ReadAuditLog(wnmcp.DefaultAuditDir(), since)
+	if err != nil {
+		return err
+	}
+
+	var filtered []wnmcp.AuditRecord
+	for _, record := range records {
+		if mcpServer == "" || record.Server == mcpServer {
+			filtered = append(filtered, record)
+		}
+	}
+	metrics := wnmcp.MetricsFromAudit(filtered)
+	stats := metrics.Snapshot()
+
+	if mcpJSON {
+		data, err := json.MarshalIndent(map[string]interface{}{
+			"methods":       stats,
+			"notifications": metrics.Notifications(),
+		}, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(stats) == 0 {
+		fmt.Println(lang.T("No MCP requests recorded"))
+		return nil
+	}
+	printMethodStats(os.Stdout, stats)
+	for server, count := range metrics.Notifications() {
+		fmt.Printf("%s: %d notifications\n", server, count)
+	}
+	return nil
+}
+
+// printMethodStats 以表格形式输出每个服务器和方法的调用统计
+func printMethodStats(w io.Writer, stats []wnmcp.MethodStats) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SERVER\tMETHOD\tCOUNT\tERRORS\tAVG\tP50\tP90\tP99\tMAX")
+	for _, s := range stats {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%s\t%s\t%s\t%s\t%s\n",
+			s.Server, s.Method, s.Count, s.Errors,
+			roundDuration(s.Average), roundDuration(s.P50), roundDuration(s.P90), roundDuration(s.P99), roundDuration(s.Max))
+	}
+	tw.Flush()
+}
+
+func roundDuration(d time.Duration) time.Duration {
+	if d > time.Second {
+		return d.Round(time.Millisecond)
+	}
+	return d.Round(time.Microsecond)
+}
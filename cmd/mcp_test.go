@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMcpServerConfig(t *testing.T) {
+	server, err := newMcpServerConfig([]string{"npx", "-y", "server-fs", "${workspaceFolder}"}, "", "", []string{"DEBUG=1"}, nil, 30)
+	assert.NoError(t, err)
+	assert.Equal(t, "stdio", server.TransportType)
+	assert.Equal(t, "npx", server.Command)
+	assert.Equal(t, []string{"-y", "server-fs", "${workspaceFolder}"}, server.Args)
+	assert.Equal(t, 30, server.Timeout)
+
+	server, err = newMcpServerConfig(nil, "http://localhost:3000/sse", "", nil, []string{"Authorization=Bearer ${env:TOKEN}"}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "sse", server.TransportType)
+	assert.Equal(t, map[string]string{"Authorization": "Bearer ${env:TOKEN}"}, server.Headers)
+
+	_, err = newMcpServerConfig(nil, "", "", nil, nil, 0)
+	assert.Error(t, err)
+	_, err = newMcpServerConfig(nil, "", "sse", nil, nil, 0)
+	assert.Error(t, err)
+	_, err = newMcpServerConfig([]string{"cmd"}, "", "", []string{"NOVALUE"}, nil, 0)
+	assert.Error(t, err)
+	_, err = newMcpServerConfig([]string{"cmd"}, "", "ws", nil, nil, 0)
+	assert.Error(t, err)
+}
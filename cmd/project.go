@@ -29,6 +29,11 @@ func runproject(cmd *cobra.Command, args []string) {
 		fmt.Printf("failed to get target path: %v\n", err)
 		return
 	}
+	sampling, err := GetSamplingParams()
+	if err != nil {
+		fmt.Printf("invalid sampling parameters: %v\n", err)
+		return
+	}
 
 	options := helper.WalkDirOptions{
 		DisableGitIgnore: disableGitIgnore,
@@ -45,6 +50,7 @@ func runproject(cmd *cobra.Command, args []string) {
 
 	// 获取项目分析结果
 	traverser := project.NewBaseChatter(doc)
+	traverser.SetSamplingParams(sampling)
 	traverser.ChatWithLLM()
 	data := doc.GetLLMResponse()
 	if data == "" {
@@ -61,6 +67,7 @@ func runproject(cmd *cobra.Command, args []string) {
 			Model:          "", // 使用默认 model
 			MaxTokens:      0,  // 使用默认 token 限制
 			ResponseFormat: "text",
+			SamplingParams: sampling,
 		},
 	}, nil)
 	if err != nil {
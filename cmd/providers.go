@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sjzsdu/wn/helper"
+	"github.com/sjzsdu/wn/llm"
+	"github.com/sjzsdu/wn/llm/providers/openai"
+	"github.com/sjzsdu/wn/share"
+)
+
+// providersFile 提供商配置文件 ~/.wn/providers.json 的内容
+type providersFile struct {
+	// Providers 自定义的 OpenAI 兼容提供商
+	Providers map[string]openai.CustomConfig `json:"providers,omitempty"`
+	// Routers 由其他提供商组成的虚拟提供商
+	Routers map[string]llm.RouterConfig `json:"routers,omitempty"`
+}
+
+// providersPath 返回提供商配置文件路径
+func providersPath() string {
+	return helper.GetPath(share.PROVIDERS_CONFIG_FILE)
+}
+
+// loadProvidersFile 读取提供商配置文件，文件不存在时返回空配置
+func loadProvidersFile(path string) (*providersFile, error) {
+	var file providersFile
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &file, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &file, nil
+}
+
+// registerProviders 读取配置文件，先注册自定义提供商，再注册引用它们的虚拟提供商
+func registerProviders(path string) error {
+	file, err := loadProvidersFile(path)
+	if err != nil {
+		return err
+	}
+
+	var errs []string
+	if err := openai.RegisterCustomProviders(file.Providers); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if err := llm.RegisterRouters(file.Routers); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s: %s", path, strings.Join(errs, "; "))
+	}
+	return nil
+}
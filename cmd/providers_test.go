@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sjzsdu/wn/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterProviders(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "providers.json")
+	assert.NoError(t, registerProviders(path))
+
+	assert.NoError(t, os.WriteFile(path, []byte(`{
+		"providers": {
+			"cmd-gateway": {"baseUrl": "http://localhost:8080/v1", "model": "m1"},
+			"cmd-broken": {}
+		},
+		"routers": {
+			"cmd-router": {"chain": [{"provider": "cmd-gateway"}]}
+		}
+	}`), 0644))
+
+	err := registerProviders(path)
+	assert.ErrorContains(t, err, path)
+	assert.ErrorContains(t, err, "cmd-broken")
+	// 虚拟提供商可以引用同一文件中的自定义提供商
+	assert.True(t, llm.Registered("cmd-gateway"))
+	assert.True(t, llm.Registered("cmd-router"))
+
+	assert.NoError(t, os.WriteFile(path, []byte(`{`), 0644))
+	_, err = loadProvidersFile(path)
+	assert.ErrorContains(t, err, path)
+}
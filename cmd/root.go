@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/sjzsdu/wn/lang"
 	"github.com/sjzsdu/wn/llm"
@@ -11,7 +12,8 @@ import (
 
 	_ "github.com/sjzsdu/wn/llm/providers/claude"
 	_ "github.com/sjzsdu/wn/llm/providers/deepseek"
-	_ "github.com/sjzsdu/wn/llm/providers/openai"
+	_ "github.com/sjzsdu/wn/llm/providers/gemini"
+	_ "github.com/sjzsdu/wn/llm/providers/ollama"
 	_ "github.com/sjzsdu/wn/llm/providers/qwen"
 )
 
@@ -27,6 +29,8 @@ var (
 	llmModel         string
 	llmAgent         string
 	llmMessageLimit  int
+	// llmSampling 采样参数命令行参数，键为参数名称
+	llmSampling = make(map[string]*string)
 )
 
 var RootCmd = rootCmd
@@ -71,9 +75,17 @@ func init() {
 	rootCmd.PersistentFlags().StringVarP(&llmModel, "llm-model", "m", "", lang.T("LLM model to use"))
 	rootCmd.PersistentFlags().StringVarP(&llmAgent, "llm-agent", "a", "", lang.T("AI use agent name"))
 	rootCmd.PersistentFlags().IntVarP(&llmMessageLimit, "llm-message-limit", "l", 1000, lang.T("LLM message limit"))
+	for _, name := range llm.SamplingParamNames {
+		flag := strings.ReplaceAll(name, "_", "-")
+		llmSampling[name] = rootCmd.PersistentFlags().String(flag, "", lang.T("LLM sampling parameter")+" "+name)
+	}
 	// 设置全局 debug 模式
 	rootCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
 		share.SetDebug(inDebug)
 	}
+	// 自定义提供商和虚拟提供商需要在创建默认提供商之前注册
+	if err := registerProviders(providersPath()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
 	llm.Init()
 }
@@ -1,10 +1,19 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
 
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/sjzsdu/wn/config"
 	"github.com/sjzsdu/wn/helper"
 	"github.com/sjzsdu/wn/lang"
 	"github.com/sjzsdu/wn/project"
@@ -14,8 +23,11 @@ import (
 )
 
 var (
-	mcpLayer string
-	mcpPort  string
+	mcpLayer      string
+	mcpPort       string
+	mcpBind       string
+	mcpWatch      time.Duration
+	mcpWorkspaces []string
 )
 
 var serverCmd = &cobra.Command{
@@ -29,6 +41,9 @@ func init() {
 	rootCmd.AddCommand(serverCmd)
 	serverCmd.Flags().StringVar(&mcpLayer, "layer", "stdio", lang.T("MCP transfer layer"))
 	serverCmd.Flags().StringVar(&mcpPort, "port", "9595", lang.T("MCP sse port"))
+	serverCmd.Flags().StringVar(&mcpBind, "bind", "", lang.T("MCP sse bind address, e.g. 127.0.0.1"))
+	serverCmd.Flags().DurationVar(&mcpWatch, "watch", 2*time.Second, lang.T("Interval for polling file changes, 0 to disable"))
+	serverCmd.Flags().StringSliceVar(&mcpWorkspaces, "workspace", []string{}, lang.T("Additional workspace directories to serve over sse"))
 }
 
 func runServer(cmd *cobra.Command, args []string) {
@@ -44,31 +59,95 @@ func runServer(cmd *cobra.Command, args []string) {
 		Excludes:         excludes,
 	}
 
-	project, perr := project.BuildProjectTree(targetPath, options)
-	if perr != nil {
-		fmt.Printf("failed to build project tree: %v\n", perr)
+	if mcpLayer == "stdio" && len(mcpWorkspaces) > 0 {
+		log.Fatalf("stdio 传输层只支持一个工作区")
+	}
+
+	workspaces, err := buildWorkspaces(append([]string{targetPath}, mcpWorkspaces...), options)
+	if err != nil {
+		fmt.Printf("failed to build project tree: %v\n", err)
 		return
 	}
-	servers.NewResource(project)
-	servers.NewTool(project)
-	servers.NewPrompt(project)
 
-	fmt.Printf("Starting MCP server at %s...\n", targetPath)
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	for _, ws := range workspaces {
+		fmt.Fprintf(os.Stderr, "Starting MCP server at %s...\n", ws.Project.GetAbsolutePath(""))
+		if mcpWatch > 0 {
+			go ws.Watch(ctx, options, mcpWatch)
+		}
+	}
 
-	var err error
 	switch mcpLayer {
 	case "sse":
-		if !helper.IsValidPort(mcpPort) {
-			log.Fatalf("无效的端口号: %s", mcpPort)
-		}
-		sseServer := server.NewSSEServer(wnmcp.McpServer())
-		err = sseServer.Start(":" + mcpPort)
+		err = serveSSE(workspaces)
 	case "stdio":
-		err = server.ServeStdio(wnmcp.McpServer())
+		err = workspaces[0].Subs.ServeStdio(ctx, os.Stdin, os.Stdout)
 	default:
 		log.Fatalf("不支持的传输层: %s", mcpLayer)
 	}
 	if err != nil {
 		log.Fatalf("服务器错误: %v", err)
 	}
-}
\ No newline at end of file
+}
+
+// buildWorkspaces 为每个目录构建项目树，目录名重复时在命名空间后追加序号
+func buildWorkspaces(paths []string, options helper.WalkDirOptions) ([]*servers.Workspace, error) {
+	used := make(map[string]int)
+	workspaces := make([]*servers.Workspace, 0, len(paths))
+	for _, path := range paths {
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return nil, err
+		}
+		proj, err := project.BuildProjectTree(absPath, options)
+		if err != nil {
+			return nil, err
+		}
+
+		name := proj.GetName()
+		used[name]++
+		if used[name] > 1 {
+			name = fmt.Sprintf("%s-%d", name, used[name])
+		}
+		workspaces = append(workspaces, servers.NewWorkspace(name, proj))
+	}
+	return workspaces, nil
+}
+
+// serveSSE 启动 SSE 服务，只有一个工作区时使用 /sse，多个工作区时使用 /<name>/sse
+func serveSSE(workspaces []*servers.Workspace) error {
+	if !helper.IsValidPort(mcpPort) {
+		log.Fatalf("无效的端口号: %s", mcpPort)
+	}
+
+	mux := http.NewServeMux()
+	for _, ws := range workspaces {
+		basePath := ""
+		if len(workspaces) > 1 {
+			basePath = "/" + ws.Name
+		}
+		sseServer := server.NewSSEServer(ws.Server,
+			server.WithBasePath(basePath),
+			server.WithSSEContextFunc(ws.Subs.SSEContextFunc),
+		)
+		mux.Handle(basePath+"/", ws.Subs.WrapSSE(sseServer))
+		fmt.Fprintf(os.Stderr, "%s: %s\n", ws.Name, sseServer.CompleteSsePath())
+	}
+
+	tokens := wnmcp.ParseTokens(config.GetConfig("server_tokens"))
+	if len(tokens) == 0 && !isLoopback(mcpBind) {
+		fmt.Fprintln(os.Stderr, lang.T("Warning: SSE server is reachable from the network without authentication, set tokens with wn config --server_tokens"))
+	}
+
+	return http.ListenAndServe(net.JoinHostPort(mcpBind, mcpPort), wnmcp.BearerAuth(tokens, mux))
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
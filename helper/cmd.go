@@ -1,6 +1,7 @@
 package helper
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -119,6 +120,9 @@ func ReadFromVim() (string, error) {
 	return userInput, nil
 }
 
+// ErrEmptyInput 用户直接回车时由 InputString 返回，其他错误表示无法读取输入
+var ErrEmptyInput = errors.New("empty input")
+
 func InputString(promptText string) (string, error) {
 	input, err := ReadFromTerminal(promptText)
 	if err != nil {
@@ -127,7 +131,7 @@ func InputString(promptText string) (string, error) {
 
 	input = strings.TrimSpace(input)
 	if input == "" {
-		return "", fmt.Errorf("empty input")
+		return "", ErrEmptyInput
 	}
 
 	if input == "vim" {
@@ -4,9 +4,9 @@ import "strings"
 
 // UpdateOperation 定义更新操作的结构体
 type UpdateOperation struct {
-	Operation string // 操作类型：insert, delete, replace, replaceAll
-	Target    string // 源文档中的内容块
-	Content   string // 新增或更新的内容
+	Operation string `json:"operation" enum:"insert,delete,replace,replaceAll"` // 操作类型：insert, delete, replace, replaceAll
+	Target    string `json:"target,omitempty"`                                  // 源文档中的内容块
+	Content   string `json:"content,omitempty"`                                 // 新增或更新的内容
 }
 
 // ApplyChanges 利用更新数组完成对原来文档的更新
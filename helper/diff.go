@@ -0,0 +1,23 @@
+package helper
+
+import (
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// UnifiedDiff 生成 oldContent 到 newContent 的统一格式 diff，内容相同时返回空字符串
+func UnifiedDiff(path string, oldContent string, newContent string) string {
+	if oldContent == newContent {
+		return ""
+	}
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(oldContent),
+		B:        difflib.SplitLines(newContent),
+		FromFile: "a/" + path,
+		ToFile:   "b/" + path,
+		Context:  3,
+	})
+	if err != nil {
+		return ""
+	}
+	return diff
+}
@@ -0,0 +1,42 @@
+package helper
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// MatchGlob 判断路径是否匹配 glob 模式，支持 ** 匹配任意层级目录
+// 模式中不包含路径分隔符时只匹配文件名，如 *.go
+func MatchGlob(pattern, path string) bool {
+	pattern = filepath.ToSlash(strings.TrimPrefix(pattern, "/"))
+	path = filepath.ToSlash(strings.TrimPrefix(path, "/"))
+
+	if !strings.Contains(pattern, "/") {
+		matched, err := filepath.Match(pattern, filepath.Base(path))
+		return err == nil && matched
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(path, "/"))
+}
+
+func matchSegments(pattern, path []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			rest := pattern[1:]
+			for i := 0; i <= len(path); i++ {
+				if matchSegments(rest, path[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(path) == 0 {
+			return false
+		}
+		matched, err := filepath.Match(pattern[0], path[0])
+		if err != nil || !matched {
+			return false
+		}
+		pattern, path = pattern[1:], path[1:]
+	}
+	return len(path) == 0
+}
@@ -0,0 +1,32 @@
+package helper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{"*.go", "cmd/root.go", true},
+		{"*.go", "README.md", false},
+		{"cmd/*.go", "cmd/root.go", true},
+		{"cmd/*.go", "cmd/sub/root.go", false},
+		{"**/*.go", "root.go", true},
+		{"**/*.go", "a/b/c.go", true},
+		{"llm/**", "llm/providers/base/handler.go", true},
+		{"llm/**/handler.go", "llm/providers/base/handler.go", true},
+		{"llm/**/handler.go", "cmd/handler.go", false},
+		{"/cmd/*.go", "/cmd/root.go", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.expected, MatchGlob(tt.pattern, tt.path))
+		})
+	}
+}
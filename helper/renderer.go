@@ -16,7 +16,14 @@ func GetDefaultRenderer() renders.Renderer {
 	return NewRenderer(render)
 }
 
+// NewRenderer 创建渲染器，思考过程的展示方式由 WN_REASONING 配置
 func NewRenderer(renderer string) renders.Renderer {
+	r := newRenderer(renderer)
+	r.SetReasoningMode(renders.ParseReasoningMode(os.Getenv("WN_REASONING")))
+	return r
+}
+
+func newRenderer(renderer string) renders.Renderer {
 	switch renderer {
 	case "text":
 		return renders.NewTextRenderer()
@@ -11,6 +11,7 @@ import (
 
 // MarkdownRenderer 实现 Renderer 接口，提供 Markdown 渲染功能
 type MarkdownRenderer struct {
+	reasoningView
 	renderer    *glamour.TermRenderer
 	buffer      strings.Builder
 	mu          sync.Mutex
@@ -42,6 +43,7 @@ func (m *MarkdownRenderer) WriteStream(content string) error {
 
 	// 第一次写入时显示提示
 	if !m.isOutputing {
+		m.end()
 		fmt.Print(lang.T("Preparing..."))
 		m.isOutputing = true
 	}
@@ -51,11 +53,23 @@ func (m *MarkdownRenderer) WriteStream(content string) error {
 	return nil
 }
 
+// WriteReasoning 实现 Renderer 接口，思考过程不做 Markdown 渲染，直接按展示方式输出
+func (m *MarkdownRenderer) WriteReasoning(content string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.write(content)
+	return nil
+}
+
 // Done 实现 Renderer 接口，完成输出并渲染 Markdown
 func (m *MarkdownRenderer) Done() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	// 只有思考过程没有正文时（如工具调用），结束思考过程
+	m.end()
+
 	// 如果没有开始输出，直接返回
 	if !m.isOutputing {
 		return
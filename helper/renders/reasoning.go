@@ -0,0 +1,91 @@
+package renders
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"unicode/utf8"
+
+	"github.com/sjzsdu/wn/lang"
+)
+
+// ReasoningMode 思考过程的展示方式
+type ReasoningMode string
+
+const (
+	// ReasoningShow 暗色显示完整的思考过程
+	ReasoningShow ReasoningMode = "show"
+	// ReasoningCollapse 只显示一行进度，结束后折叠为摘要
+	ReasoningCollapse ReasoningMode = "collapse"
+	// ReasoningHide 不显示思考过程
+	ReasoningHide ReasoningMode = "hide"
+)
+
+const (
+	ansiDim   = "\033[2m"
+	ansiReset = "\033[0m"
+)
+
+// ParseReasoningMode 解析展示方式，未知的值使用 ReasoningCollapse
+func ParseReasoningMode(mode string) ReasoningMode {
+	switch m := ReasoningMode(mode); m {
+	case ReasoningShow, ReasoningHide:
+		return m
+	default:
+		return ReasoningCollapse
+	}
+}
+
+// reasoningView 按展示方式输出思考过程，供各渲染器嵌入
+// 思考过程总在正文之前，渲染器在输出正文和完成时调用 end 结束思考过程
+type reasoningView struct {
+	out    io.Writer
+	mode   ReasoningMode
+	active bool
+	chars  int
+}
+
+// SetReasoningMode 设置思考过程的展示方式
+func (v *reasoningView) SetReasoningMode(mode ReasoningMode) {
+	v.mode = mode
+}
+
+func (v *reasoningView) writer() io.Writer {
+	if v.out == nil {
+		return os.Stdout
+	}
+	return v.out
+}
+
+func (v *reasoningView) write(content string) {
+	if v.mode == ReasoningHide || content == "" {
+		return
+	}
+	w := v.writer()
+	v.chars += utf8.RuneCountInString(content)
+	switch v.mode {
+	case ReasoningShow:
+		if !v.active {
+			fmt.Fprintf(w, "%s%s\n", ansiDim, lang.T("Thinking..."))
+		}
+		fmt.Fprint(w, ansiDim+content+ansiReset)
+	default:
+		fmt.Fprintf(w, "\r%s%s (%d)%s", ansiDim, lang.T("Thinking..."), v.chars, ansiReset)
+	}
+	v.active = true
+}
+
+func (v *reasoningView) end() {
+	if !v.active {
+		return
+	}
+	w := v.writer()
+	switch v.mode {
+	case ReasoningShow:
+		fmt.Fprint(w, "\n\n")
+	default:
+		fmt.Fprintf(w, "\r\033[K%s%s (%d)%s\n\n", ansiDim, lang.T("Thought"), v.chars, ansiReset)
+	}
+	v.active = false
+	v.chars = 0
+}
@@ -0,0 +1,41 @@
+package renders
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseReasoningMode(t *testing.T) {
+	assert.Equal(t, ReasoningShow, ParseReasoningMode("show"))
+	assert.Equal(t, ReasoningHide, ParseReasoningMode("hide"))
+	assert.Equal(t, ReasoningCollapse, ParseReasoningMode(""))
+	assert.Equal(t, ReasoningCollapse, ParseReasoningMode("unknown"))
+}
+
+func TestReasoningView(t *testing.T) {
+	var out strings.Builder
+
+	// 折叠模式只更新进度，结束后显示摘要
+	v := reasoningView{out: &out, mode: ReasoningCollapse}
+	v.write("先想")
+	v.write("一想")
+	v.end()
+	v.end()
+	assert.NotContains(t, out.String(), "先想")
+	assert.Contains(t, out.String(), "(4)")
+	assert.True(t, strings.HasSuffix(out.String(), ansiReset+"\n\n"))
+
+	out.Reset()
+	v = reasoningView{out: &out, mode: ReasoningShow}
+	v.write("先想")
+	v.end()
+	assert.Contains(t, out.String(), ansiDim+"先想"+ansiReset)
+
+	out.Reset()
+	v = reasoningView{out: &out, mode: ReasoningHide}
+	v.write("先想")
+	v.end()
+	assert.Empty(t, out.String())
+}
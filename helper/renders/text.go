@@ -6,6 +6,7 @@ import (
 
 // TextRenderer 实现 Renderer 接口，提供纯文本渲染功能
 type TextRenderer struct {
+	reasoningView
 }
 
 // NewTextRenderer 创建一个新的文本渲染器
@@ -16,11 +17,19 @@ func NewTextRenderer() *TextRenderer {
 // WriteStream 实现 Renderer 接口，将内容写入缓冲区
 // 如果是第一次写入，会显示 "output...." 提示
 func (t *TextRenderer) WriteStream(content string) error {
+	t.end()
 	fmt.Print(content)
 	return nil
 }
 
+// WriteReasoning 实现 Renderer 接口，按展示方式输出思考过程
+func (t *TextRenderer) WriteReasoning(content string) error {
+	t.write(content)
+	return nil
+}
+
 // Done 实现 Renderer 接口，完成输出并显示文本
 func (t *TextRenderer) Done() {
+	t.end()
 	fmt.Println()
 }
@@ -4,6 +4,10 @@ package renders
 type Renderer interface {
 	// 输出文字
 	WriteStream(content string) error
+	// 输出思考过程
+	WriteReasoning(content string) error
+	// 设置思考过程的展示方式
+	SetReasoningMode(mode ReasoningMode)
 	// 完成输出
 	Done()
 }
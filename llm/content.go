@@ -0,0 +1,87 @@
+package llm
+
+import (
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxAttachmentSize 单个图片或文件附件的大小上限
+const MaxAttachmentSize = 20 << 20
+
+// TextPart 创建文本片段
+func TextPart(text string) ContentPart {
+	return ContentPart{Type: ContentTypeText, Text: text}
+}
+
+// ImagePart 创建 base64 编码的图片片段
+func ImagePart(mimeType string, data []byte) ContentPart {
+	return ContentPart{Type: ContentTypeImage, MIMEType: mimeType, Data: base64.StdEncoding.EncodeToString(data)}
+}
+
+// ImageURLPart 创建引用远程图片的片段
+func ImageURLPart(url string) ContentPart {
+	return ContentPart{Type: ContentTypeImage, URL: url}
+}
+
+// FilePart 创建 base64 编码的文件片段，如 PDF 文档
+func FilePart(name, mimeType string, data []byte) ContentPart {
+	return ContentPart{Type: ContentTypeFile, Name: name, MIMEType: mimeType, Data: base64.StdEncoding.EncodeToString(data)}
+}
+
+// ImagePartFromFile 读取本地图片，不是图片时返回错误
+func ImagePartFromFile(path string) (ContentPart, error) {
+	data, mimeType, err := readAttachment(path)
+	if err != nil {
+		return ContentPart{}, err
+	}
+	if !strings.HasPrefix(mimeType, "image/") {
+		return ContentPart{}, fmt.Errorf("%s 不是图片文件（%s）", path, mimeType)
+	}
+	return ImagePart(mimeType, data), nil
+}
+
+// FilePartFromFile 读取本地文件，图片返回图片片段，文本文件以带文件名的文本片段内联，其他文件返回文件片段
+func FilePartFromFile(path string) (ContentPart, error) {
+	data, mimeType, err := readAttachment(path)
+	if err != nil {
+		return ContentPart{}, err
+	}
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		return ImagePart(mimeType, data), nil
+	case strings.HasPrefix(mimeType, "text/") || utf8.Valid(data) && !strings.Contains(string(data), "\x00"):
+		return TextPart(fmt.Sprintf("[file %s]\n%s", filepath.Base(path), data)), nil
+	default:
+		return FilePart(filepath.Base(path), mimeType, data), nil
+	}
+}
+
+// readAttachment 读取附件并识别 MIME 类型，优先使用扩展名，无法识别时根据内容判断
+func readAttachment(path string) ([]byte, string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, "", err
+	}
+	if info.Size() > MaxAttachmentSize {
+		return nil, "", fmt.Errorf("%s 超过附件大小上限 %d MB", path, MaxAttachmentSize>>20)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	mimeType := mime.TypeByExtension(strings.ToLower(filepath.Ext(path)))
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+	if mediaType, _, err := mime.ParseMediaType(mimeType); err == nil {
+		mimeType = mediaType
+	}
+	return data, mimeType, nil
+}
@@ -0,0 +1,64 @@
+package llm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPartFromFile(t *testing.T) {
+	dir := t.TempDir()
+	png := filepath.Join(dir, "shot.png")
+	notes := filepath.Join(dir, "notes")
+	pdf := filepath.Join(dir, "spec.pdf")
+	assert.NoError(t, os.WriteFile(png, []byte("\x89PNG\r\n\x1a\n"), 0644))
+	assert.NoError(t, os.WriteFile(notes, []byte("todo"), 0644))
+	assert.NoError(t, os.WriteFile(pdf, []byte("%PDF-1.4\x00\x01"), 0644))
+
+	image, err := ImagePartFromFile(png)
+	assert.NoError(t, err)
+	assert.Equal(t, ContentPart{Type: ContentTypeImage, MIMEType: "image/png", Data: "iVBORw0KGgo="}, image)
+
+	_, err = ImagePartFromFile(notes)
+	assert.Error(t, err)
+	_, err = ImagePartFromFile(filepath.Join(dir, "missing.png"))
+	assert.Error(t, err)
+
+	// 文本文件以文本片段内联
+	part, err := FilePartFromFile(notes)
+	assert.NoError(t, err)
+	assert.Equal(t, TextPart("[file notes]\ntodo"), part)
+
+	part, err = FilePartFromFile(pdf)
+	assert.NoError(t, err)
+	assert.Equal(t, ContentTypeFile, part.Type)
+	assert.Equal(t, "spec.pdf", part.Name)
+	assert.Equal(t, "application/pdf", part.MIMEType)
+}
+
+func TestNewUserMessage(t *testing.T) {
+	msg := NewUserMessage(
+		ImagePart("image/png", []byte("abc")),
+		ImageURLPart("https://example.com/a.png"),
+		FilePart("a.pdf", "application/pdf", []byte("abcd")),
+		TextPart("what is this?"),
+	)
+	assert.Equal(t, "user", msg.Role)
+	assert.Equal(t, "[image image/png, 3 bytes]\n[image https://example.com/a.png]\n[file a.pdf application/pdf, 4 bytes]\nwhat is this?", msg.Content)
+	assert.True(t, msg.HasMedia())
+	assert.Equal(t, "data:image/png;base64,YWJj", msg.Parts[0].DataURL())
+	assert.Equal(t, "https://example.com/a.png", msg.Parts[1].DataURL())
+}
+
+func TestRejectMedia(t *testing.T) {
+	req := CompletionRequest{Messages: []Message{
+		{Role: "user", Content: "hi"},
+		{Role: "tool", Parts: []ContentPart{ImagePart("image/png", []byte("abc"))}},
+	}}
+	assert.NoError(t, req.RejectMedia("test", "model"))
+
+	req.Messages = append(req.Messages, NewUserMessage(TextPart("look"), ImagePart("image/png", []byte("abc"))))
+	assert.ErrorContains(t, req.RejectMedia("test", "model"), "model")
+}
@@ -0,0 +1,145 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Embedder 把文本转换为向量，支持 embeddings 的提供商实现该接口
+type Embedder interface {
+	// Embed 返回与输入顺序一致的向量，输入较多时由实现分批请求
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+	// Dimensions 返回向量维度，未知模型在第一次请求前返回 0
+	Dimensions() int
+	// EmbeddingUsage 返回累计使用的 token 数
+	EmbeddingUsage() Usage
+}
+
+// LocalEmbedder 本地哈希向量化的名称，不需要网络和 API Key
+const LocalEmbedder = "local"
+
+// GetEmbedder 返回提供商的 Embedder，name 为 local 时返回本地哈希实现
+func GetEmbedder(name string, options map[string]interface{}) (Embedder, error) {
+	if name == LocalEmbedder {
+		return NewHashEmbedder(0), nil
+	}
+	provider, err := CreateProvider(name, options)
+	if err != nil {
+		return nil, err
+	}
+	embedder, ok := provider.(Embedder)
+	if !ok {
+		return nil, fmt.Errorf("llm: provider %s does not support embeddings", name)
+	}
+	return embedder, nil
+}
+
+// DefaultHashDimensions HashEmbedder 的默认维度
+const DefaultHashDimensions = 256
+
+// HashEmbedder 本地的确定性向量化实现，把单词和字符三元组哈希到固定维度后归一化
+// 结果只反映字面上的相似度，适合测试和离线使用
+type HashEmbedder struct {
+	dimensions int
+	mu         sync.Mutex
+	usage      Usage
+}
+
+// NewHashEmbedder 创建指定维度的 HashEmbedder，dimensions 不大于 0 时使用默认维度
+func NewHashEmbedder(dimensions int) *HashEmbedder {
+	if dimensions <= 0 {
+		dimensions = DefaultHashDimensions
+	}
+	return &HashEmbedder{dimensions: dimensions}
+}
+
+// Embed 实现 Embedder，用量按单词数计算
+func (e *HashEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	tokens := 0
+	for i, text := range texts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+		})
+		vectors[i] = e.vector(words)
+		tokens += len(words)
+	}
+
+	e.mu.Lock()
+	e.usage.PromptTokens += tokens
+	e.usage.TotalTokens += tokens
+	e.mu.Unlock()
+	return vectors, nil
+}
+
+func (e *HashEmbedder) vector(words []string) []float32 {
+	vector := make([]float32, e.dimensions)
+	for _, word := range words {
+		e.add(vector, word, 1)
+		// 三元组让词形变化和没有空格分隔的中文也能匹配
+		runes := []rune(" " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			e.add(vector, string(runes[i:i+3]), 0.5)
+		}
+	}
+
+	var norm float64
+	for _, v := range vector {
+		norm += float64(v) * float64(v)
+	}
+	if norm > 0 {
+		scale := float32(1 / math.Sqrt(norm))
+		for i := range vector {
+			vector[i] *= scale
+		}
+	}
+	return vector
+}
+
+// add 把特征哈希到一个维度，用哈希的最高位决定符号以减少碰撞的影响
+func (e *HashEmbedder) add(vector []float32, feature string, weight float32) {
+	h := fnv.New32a()
+	h.Write([]byte(feature))
+	sum := h.Sum32()
+	if sum&(1<<31) != 0 {
+		weight = -weight
+	}
+	vector[sum%uint32(e.dimensions)] += weight
+}
+
+// Dimensions 实现 Embedder
+func (e *HashEmbedder) Dimensions() int {
+	return e.dimensions
+}
+
+// EmbeddingUsage 实现 Embedder
+func (e *HashEmbedder) EmbeddingUsage() Usage {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.usage
+}
+
+// CosineSimilarity 返回两个向量的余弦相似度，长度不同或有零向量时返回 0
+func CosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(normA*normB)
+}
@@ -0,0 +1,53 @@
+package llm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashEmbedder(t *testing.T) {
+	e := NewHashEmbedder(0)
+	assert.Equal(t, DefaultHashDimensions, e.Dimensions())
+
+	texts := []string{
+		"Parse the config file",
+		"parsing config files",
+		"render markdown output",
+		"",
+	}
+	vectors, err := e.Embed(context.Background(), texts)
+	assert.NoError(t, err)
+	assert.Len(t, vectors, len(texts))
+	for _, vector := range vectors {
+		assert.Len(t, vector, DefaultHashDimensions)
+	}
+
+	// 相同输入得到相同向量，字面相近的文本更相似
+	again, _ := e.Embed(context.Background(), texts[:1])
+	assert.Equal(t, vectors[0], again[0])
+	assert.InDelta(t, 1.0, CosineSimilarity(vectors[0], vectors[0]), 1e-6)
+	assert.Greater(t, CosineSimilarity(vectors[0], vectors[1]), CosineSimilarity(vectors[0], vectors[2]))
+	assert.Zero(t, CosineSimilarity(vectors[0], vectors[3]))
+
+	assert.Equal(t, Usage{PromptTokens: 14, TotalTokens: 14}, e.EmbeddingUsage())
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	assert.InDelta(t, 0.0, CosineSimilarity([]float32{1, 0}, []float32{0, 1}), 1e-9)
+	assert.InDelta(t, -1.0, CosineSimilarity([]float32{1, 2}, []float32{-2, -4}), 1e-9)
+	assert.Zero(t, CosineSimilarity([]float32{1}, []float32{1, 2}))
+}
+
+func TestGetEmbedder(t *testing.T) {
+	e, err := GetEmbedder(LocalEmbedder, nil)
+	assert.NoError(t, err)
+	assert.IsType(t, &HashEmbedder{}, e)
+
+	Register("no-embeddings", func(options map[string]interface{}) (Provider, error) {
+		return &mockProvider{}, nil
+	})
+	_, err = GetEmbedder("no-embeddings", nil)
+	assert.ErrorContains(t, err, "does not support embeddings")
+}
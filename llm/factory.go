@@ -1,7 +1,10 @@
 package llm
 
 import (
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/sjzsdu/wn/config"
 )
@@ -23,6 +26,44 @@ func Register(name string, newProvider NewProvider) {
 	providers[name] = newProvider
 }
 
+// RegisterConfigs 按名称顺序注册配置文件中定义的提供商，create 校验配置并返回工厂方法
+// 与已有提供商重名或配置无效的提供商会被跳过，并在返回的错误中列出
+func RegisterConfigs[T any](configs map[string]T, create func(name string, config T) (NewProvider, error)) error {
+	var errs []string
+	for _, name := range sortedNames(configs) {
+		if Registered(name) {
+			errs = append(errs, fmt.Sprintf("提供商 %s 已存在", name))
+			continue
+		}
+		newProvider, err := create(name, configs[name])
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		Register(name, newProvider)
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// sortedNames 返回按名称排序的键
+func sortedNames[T any](configs map[string]T) []string {
+	names := make([]string, 0, len(configs))
+	for name := range configs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Registered 判断提供商是否已注册
+func Registered(name string) bool {
+	_, ok := providers[name]
+	return ok
+}
+
 // GetProvider 获取指定名称的大模型提供商
 func CreateProvider(name string, options map[string]interface{}) (Provider, error) {
 	// 移除了锁相关代码
@@ -65,5 +106,6 @@ func Providers() []string {
 	for name := range providers {
 		list = append(list, name)
 	}
+	sort.Strings(list)
 	return list
 }
@@ -0,0 +1,397 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// JSONSchema 结构化输出的 schema，Name 只能包含字母、数字、下划线和连字符
+type JSONSchema struct {
+	Name   string                 `json:"name"`
+	Schema map[string]interface{} `json:"schema"`
+	// Strict 要求模型严格遵守 schema，需要所有字段都是必填的
+	Strict bool `json:"strict,omitempty"`
+}
+
+// IsObject 判断根节点是否为对象，部分提供商只支持对象作为根节点
+func (s *JSONSchema) IsObject() bool {
+	return s != nil && s.Schema["type"] == "object"
+}
+
+// Validator 由需要额外语义校验的结构化输出类型实现，返回的错误会发给模型要求修正
+type Validator interface {
+	Validate() error
+}
+
+// ValidationError JSON 不符合 schema 时返回，Errors 列出每一处问题
+type ValidationError struct {
+	Errors []string
+}
+
+func (e *ValidationError) Error() string {
+	return "JSON 校验失败: " + strings.Join(e.Errors, "; ")
+}
+
+var schemaNamePattern = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// SchemaFor 根据 T 的类型生成 JSON Schema，字段名取自 json 标签，没有 omitempty 的字段为必填，
+// jsonschema 标签可以用 required 或 optional 覆盖这一规则而不影响序列化，
+// 字符串字段可以用 enum 标签以逗号分隔列出可选值
+func SchemaFor[T any]() *JSONSchema {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	name := schemaNamePattern.ReplaceAllString(t.Name(), "_")
+	if name == "" {
+		name = "response"
+	}
+	return &JSONSchema{
+		Name:   strings.ToLower(name),
+		Schema: schemaOf(t, map[reflect.Type]bool{}),
+	}
+}
+
+func schemaOf(t reflect.Type, visiting map[reflect.Type]bool) map[string]interface{} {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		// []byte 按 encoding/json 的规则编码为 base64 字符串
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string"}
+		}
+		return map[string]interface{}{"type": "array", "items": schemaOf(t.Elem(), visiting)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": schemaOf(t.Elem(), visiting)}
+	case reflect.Struct:
+		// 递归类型不再展开
+		if visiting[t] {
+			return map[string]interface{}{"type": "object"}
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+
+		properties := map[string]interface{}{}
+		required := []string{}
+		addFields(t, properties, &required, visiting)
+		return map[string]interface{}{
+			"type":                 "object",
+			"properties":           properties,
+			"required":             required,
+			"additionalProperties": false,
+		}
+	default:
+		// interface{} 等无法确定类型的字段接受任意值
+		return map[string]interface{}{}
+	}
+}
+
+func addFields(t reflect.Type, properties map[string]interface{}, required *[]string, visiting map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		// 没有 json 标签的匿名结构体字段展开到外层
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				addFields(ft, properties, required, visiting)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		schema := schemaOf(field.Type, visiting)
+		if enum := field.Tag.Get("enum"); enum != "" {
+			schema["enum"] = strings.Split(enum, ",")
+		}
+		properties[name] = schema
+		if isRequired(field.Tag.Get("jsonschema"), opts) {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// isRequired 根据 jsonschema 标签和 json 标签的选项判断字段是否必填
+func isRequired(schemaTag, jsonOpts string) bool {
+	switch schemaTag {
+	case "required":
+		return true
+	case "optional":
+		return false
+	}
+	return !strings.Contains(jsonOpts, "omitempty")
+}
+
+// ValidateJSON 按 schema 校验 JSON 值，返回所有问题，路径以 $ 表示根节点
+func ValidateJSON(schema map[string]interface{}, value interface{}) []string {
+	var errs []string
+	validate(schema, value, "$", &errs)
+	return errs
+}
+
+func validate(schema map[string]interface{}, value interface{}, path string, errs *[]string) {
+	if enum, ok := schema["enum"].([]string); ok {
+		if s, isString := value.(string); !isString || !contains(enum, s) {
+			*errs = append(*errs, fmt.Sprintf("%s 必须是 %s 之一", path, strings.Join(enum, "、")))
+			return
+		}
+	}
+
+	switch schema["type"] {
+	case "string":
+		if _, ok := value.(string); !ok {
+			*errs = append(*errs, fmt.Sprintf("%s 应为字符串，实际为 %s", path, jsonType(value)))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			*errs = append(*errs, fmt.Sprintf("%s 应为布尔值，实际为 %s", path, jsonType(value)))
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			*errs = append(*errs, fmt.Sprintf("%s 应为数字，实际为 %s", path, jsonType(value)))
+		}
+	case "integer":
+		if n, ok := value.(float64); !ok || n != float64(int64(n)) {
+			*errs = append(*errs, fmt.Sprintf("%s 应为整数，实际为 %s", path, jsonType(value)))
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			*errs = append(*errs, fmt.Sprintf("%s 应为数组，实际为 %s", path, jsonType(value)))
+			return
+		}
+		if itemSchema, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range items {
+				validate(itemSchema, item, fmt.Sprintf("%s[%d]", path, i), errs)
+			}
+		}
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			*errs = append(*errs, fmt.Sprintf("%s 应为对象，实际为 %s", path, jsonType(value)))
+			return
+		}
+		validateObject(schema, object, path, errs)
+	}
+}
+
+func validateObject(schema map[string]interface{}, object map[string]interface{}, path string, errs *[]string) {
+	required, _ := schema["required"].([]string)
+	for _, name := range required {
+		if _, exists := object[name]; !exists {
+			*errs = append(*errs, fmt.Sprintf("%s 缺少必填字段 %s", path, name))
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	additional := schema["additionalProperties"]
+	for name, v := range object {
+		fieldPath := path + "." + name
+		// 可选字段允许为 null
+		if v == nil && !contains(required, name) {
+			continue
+		}
+		if propSchema, ok := properties[name].(map[string]interface{}); ok {
+			validate(propSchema, v, fieldPath, errs)
+			continue
+		}
+		switch additional := additional.(type) {
+		case bool:
+			if !additional {
+				*errs = append(*errs, fmt.Sprintf("%s 不是允许的字段", fieldPath))
+			}
+		case map[string]interface{}:
+			validate(additional, v, fieldPath, errs)
+		}
+	}
+}
+
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "字符串"
+	case bool:
+		return "布尔值"
+	case float64:
+		return "数字"
+	case []interface{}:
+		return "数组"
+	case map[string]interface{}:
+		return "对象"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func contains(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ExtractJSON 去掉回复中的 markdown 代码块和前后的说明文字，返回 JSON 部分
+func ExtractJSON(content string) string {
+	content = strings.TrimSpace(content)
+	// JSON 字符串里可能包含代码块，本身有效时直接返回
+	if json.Valid([]byte(content)) {
+		return content
+	}
+
+	start := strings.Index(content, "```json")
+	if start < 0 && strings.HasPrefix(content, "```") {
+		start = 0
+	}
+	if start >= 0 {
+		body := content[start+3:]
+		// 跳过代码块的语言标记
+		if newline := strings.IndexByte(body, '\n'); newline >= 0 {
+			body = body[newline+1:]
+		}
+		if end := strings.LastIndex(body, "```"); end >= 0 {
+			body = body[:end]
+		}
+		return strings.TrimSpace(body)
+	}
+
+	start = strings.IndexAny(content, "{[")
+	end := strings.LastIndexAny(content, "}]")
+	if start >= 0 && end > start {
+		return content[start : end+1]
+	}
+	return content
+}
+
+// DecodeJSON 从回复中提取 JSON，按 schema 和 Validator 校验后解析为 T，
+// 内容不合法时返回 *ValidationError
+func DecodeJSON[T any](content string, schema *JSONSchema) (T, error) {
+	var result, zero T
+	data := []byte(ExtractJSON(content))
+
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return zero, &ValidationError{Errors: []string{"不是有效的 JSON: " + err.Error()}}
+	}
+	if schema != nil {
+		if errs := ValidateJSON(schema.Schema, value); len(errs) > 0 {
+			return zero, &ValidationError{Errors: errs}
+		}
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return zero, &ValidationError{Errors: []string{err.Error()}}
+	}
+	if v, ok := any(&result).(Validator); ok {
+		if err := v.Validate(); err != nil {
+			return zero, &ValidationError{Errors: []string{err.Error()}}
+		}
+	}
+	return result, nil
+}
+
+// CompleteJSON 请求模型按 T 的 schema 返回 JSON，校验失败时带上错误信息要求模型修正，最多重试 maxRetries 次
+func CompleteJSON[T any](ctx context.Context, provider Provider, req CompletionRequest, maxRetries int) (T, error) {
+	req = WithJSONSchema(req, SchemaFor[T]())
+	resp, err := provider.Complete(ctx, req)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return RepairJSON[T](ctx, provider, req, resp.Content, maxRetries)
+}
+
+// WithJSONSchema 设置请求的 schema，并在开头的 system 消息之后加入返回格式的说明，
+// 使不支持 schema 的提供商也能按要求返回
+func WithJSONSchema(req CompletionRequest, schema *JSONSchema) CompletionRequest {
+	if req.JSONSchema == nil {
+		req.JSONSchema = schema
+	}
+	data, _ := json.Marshal(req.JSONSchema.Schema)
+	instruction := Message{
+		Role:    "system",
+		Content: "请只返回符合以下 JSON Schema 的 JSON，不要包含其他内容：\n" + string(data),
+	}
+
+	i := 0
+	for i < len(req.Messages) && req.Messages[i].Role == "system" {
+		i++
+	}
+	messages := make([]Message, 0, len(req.Messages)+1)
+	messages = append(messages, req.Messages[:i]...)
+	messages = append(messages, instruction)
+	req.Messages = append(messages, req.Messages[i:]...)
+	return req
+}
+
+// RepairJSON 校验已有的回复，不合法时把回复和错误发回模型要求修正，最多重试 maxRetries 次，
+// req 应为得到该回复的请求
+func RepairJSON[T any](ctx context.Context, provider Provider, req CompletionRequest, content string, maxRetries int) (T, error) {
+	if req.JSONSchema == nil {
+		req.JSONSchema = SchemaFor[T]()
+	}
+	// 复制消息，避免修改调用方的切片
+	req.Messages = append([]Message(nil), req.Messages...)
+
+	for attempt := 0; ; attempt++ {
+		result, err := DecodeJSON[T](content, req.JSONSchema)
+		if err == nil {
+			return result, nil
+		}
+		if attempt >= maxRetries {
+			if maxRetries > 0 {
+				err = fmt.Errorf("重试 %d 次后仍然失败: %w", maxRetries, err)
+			}
+			return result, err
+		}
+
+		req.Messages = append(req.Messages,
+			Message{Role: "assistant", Content: content},
+			Message{Role: "user", Content: repairPrompt(err)},
+		)
+		resp, err := provider.Complete(ctx, req)
+		if err != nil {
+			return result, err
+		}
+		content = resp.Content
+	}
+}
+
+// repairPrompt 把校验错误整理为要求模型修正的消息
+func repairPrompt(err error) string {
+	problems := []string{err.Error()}
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		problems = validationErr.Errors
+	}
+	return "上面的回复不符合要求：\n- " + strings.Join(problems, "\n- ") + "\n请修正这些问题，重新返回完整的 JSON，不要包含其他内容。"
+}
@@ -0,0 +1,158 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type jsonItem struct {
+	Name  string   `json:"name"`
+	Kind  string   `json:"kind,omitempty" enum:"a,b"`
+	Count int      `json:"count"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+type jsonResult struct {
+	Items   []jsonItem `json:"items"`
+	Summary string     `json:"summary"`
+}
+
+func (r jsonResult) Validate() error {
+	if len(r.Items) == 0 {
+		return errors.New("items 不能为空")
+	}
+	return nil
+}
+
+// replyProvider 依次返回预设的回复，并记录收到的请求
+type replyProvider struct {
+	mockProvider
+	replies  []string
+	requests []CompletionRequest
+}
+
+func (p *replyProvider) Complete(ctx context.Context, req CompletionRequest) (*CompletionResponse, error) {
+	p.requests = append(p.requests, req)
+	reply := p.replies[0]
+	p.replies = p.replies[1:]
+	return &CompletionResponse{Content: reply}, nil
+}
+
+func TestSchemaFor(t *testing.T) {
+	schema := SchemaFor[jsonResult]()
+	assert.Equal(t, "jsonresult", schema.Name)
+	assert.True(t, schema.IsObject())
+	assert.Equal(t, []string{"items", "summary"}, schema.Schema["required"])
+
+	items := schema.Schema["properties"].(map[string]interface{})["items"].(map[string]interface{})
+	assert.Equal(t, "array", items["type"])
+	item := items["items"].(map[string]interface{})
+	assert.Equal(t, []string{"name", "count"}, item["required"])
+	assert.Equal(t, false, item["additionalProperties"])
+
+	properties := item["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "string", "enum": []string{"a", "b"}}, properties["kind"])
+	assert.Equal(t, "integer", properties["count"].(map[string]interface{})["type"])
+
+	assert.False(t, SchemaFor[[]jsonItem]().IsObject())
+}
+
+func TestSchemaFor_RequiredTag(t *testing.T) {
+	type tagged struct {
+		Note  []string `json:"note" jsonschema:"optional"`
+		Score float64  `json:"score,omitempty" jsonschema:"required"`
+		Name  string   `json:"name"`
+	}
+	schema := SchemaFor[tagged]()
+	// jsonschema 标签优先于 omitempty
+	assert.Equal(t, []string{"score", "name"}, schema.Schema["required"])
+
+	_, err := DecodeJSON[tagged](`{"score":1,"name":"x"}`, schema)
+	assert.NoError(t, err)
+	_, err = DecodeJSON[tagged](`{"name":"x"}`, schema)
+	assert.ErrorContains(t, err, "$ 缺少必填字段 score")
+}
+
+func TestExtractJSON(t *testing.T) {
+	assert.Equal(t, `{"a":1}`, ExtractJSON("```json\n{\"a\":1}\n```"))
+	assert.Equal(t, `[1]`, ExtractJSON("```\n[1]\n```"))
+	assert.Equal(t, `{"a":1}`, ExtractJSON("结果如下：\n{\"a\":1}\n以上。"))
+	// 字符串中的代码块不影响有效的 JSON
+	content := "{\"content\":\"```go\\nfmt.Println()\\n```\"}"
+	assert.Equal(t, content, ExtractJSON(content))
+}
+
+func TestDecodeJSON(t *testing.T) {
+	schema := SchemaFor[jsonResult]()
+
+	result, err := DecodeJSON[jsonResult]("```json\n{\"items\":[{\"name\":\"x\",\"count\":2,\"kind\":null}],\"summary\":\"s\"}\n```", schema)
+	assert.NoError(t, err)
+	assert.Equal(t, "x", result.Items[0].Name)
+
+	_, err = DecodeJSON[jsonResult](`{"items":[{"name":1,"count":1.5,"kind":"c","extra":true}]}`, schema)
+	var validationErr *ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	assert.ElementsMatch(t, []string{
+		"$ 缺少必填字段 summary",
+		"$.items[0].name 应为字符串，实际为 数字",
+		"$.items[0].count 应为整数，实际为 数字",
+		"$.items[0].kind 必须是 a、b 之一",
+		"$.items[0].extra 不是允许的字段",
+	}, validationErr.Errors)
+
+	_, err = DecodeJSON[jsonResult](`{"items":[],"summary":"s"}`, schema)
+	assert.EqualError(t, err, "JSON 校验失败: items 不能为空")
+
+	_, err = DecodeJSON[jsonResult](`{"items":`, schema)
+	assert.ErrorContains(t, err, "不是有效的 JSON")
+}
+
+func TestCompleteJSON(t *testing.T) {
+	provider := &replyProvider{replies: []string{
+		`{"items":[{"name":"x"}],"summary":"s"}`,
+		`{"items":[{"name":"x","count":1}],"summary":"s"}`,
+	}}
+	req := CompletionRequest{Messages: []Message{
+		{Role: "system", Content: "prompt"},
+		{Role: "user", Content: "hi"},
+	}}
+
+	result, err := CompleteJSON[jsonResult](context.Background(), provider, req, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Items[0].Count)
+	assert.Len(t, req.Messages, 2)
+
+	// schema 说明放在开头的 system 消息之后
+	first := provider.requests[0]
+	assert.Equal(t, "jsonresult", first.JSONSchema.Name)
+	assert.Equal(t, []string{"system", "system", "user"}, roles(first.Messages))
+	assert.Contains(t, first.Messages[1].Content, `"required":["items","summary"]`)
+
+	// 修正请求带上原回复和校验错误
+	retry := provider.requests[1]
+	assert.Equal(t, []string{"system", "system", "user", "assistant", "user"}, roles(retry.Messages))
+	assert.Contains(t, retry.Messages[4].Content, "$.items[0] 缺少必填字段 count")
+}
+
+func TestCompleteJSONRetriesExhausted(t *testing.T) {
+	provider := &replyProvider{replies: []string{"不是 JSON", "还不是", "仍然不是"}}
+	_, err := CompleteJSON[jsonResult](context.Background(), provider, CompletionRequest{}, 2)
+	assert.ErrorContains(t, err, "重试 2 次后仍然失败")
+	assert.Len(t, provider.requests, 3)
+
+	provider = &replyProvider{replies: []string{"不是 JSON"}}
+	_, err = CompleteJSON[jsonResult](context.Background(), provider, CompletionRequest{}, 0)
+	assert.True(t, strings.HasPrefix(err.Error(), "JSON 校验失败"))
+}
+
+func roles(messages []Message) []string {
+	result := make([]string, len(messages))
+	for i, m := range messages {
+		result[i] = m.Role
+	}
+	return result
+}
@@ -0,0 +1,103 @@
+package llm
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// TextContent 返回消息的纯文本形式，供不支持结构化内容的模型使用
+// 工具调用失败时在内容前加上说明，让模型知道工具没有正常执行
+func (m Message) TextContent() string {
+	if m.IsError {
+		return "Error: the tool call failed.\n" + m.Content
+	}
+	return m.Content
+}
+
+// Images 返回消息中的图片
+func (m Message) Images() []ContentPart {
+	var images []ContentPart
+	for _, part := range m.Parts {
+		if part.Type == ContentTypeImage {
+			images = append(images, part)
+		}
+	}
+	return images
+}
+
+// HasMedia 判断消息是否包含图片或文件
+func (m Message) HasMedia() bool {
+	for _, part := range m.Parts {
+		if part.Type != ContentTypeText {
+			return true
+		}
+	}
+	return false
+}
+
+// NewUserMessage 创建包含多个内容片段的用户消息，Content 为片段的文本形式
+func NewUserMessage(parts ...ContentPart) Message {
+	return Message{
+		Role:    "user",
+		Content: PartsText(parts),
+		Parts:   parts,
+	}
+}
+
+// String 返回片段的文本形式，图片和文件只显示摘要
+func (p ContentPart) String() string {
+	switch p.Type {
+	case ContentTypeImage:
+		if p.URL != "" {
+			return fmt.Sprintf("[image %s]", p.URL)
+		}
+		return fmt.Sprintf("[image %s, %d bytes]", p.MIMEType, p.size())
+	case ContentTypeFile:
+		return fmt.Sprintf("[file %s %s, %d bytes]", p.Name, p.MIMEType, p.size())
+	default:
+		return p.Text
+	}
+}
+
+// size 返回 base64 数据解码后的字节数
+func (p ContentPart) size() int {
+	padding := strings.Count(p.Data[max(0, len(p.Data)-2):], "=")
+	return base64.StdEncoding.DecodedLen(len(p.Data)) - padding
+}
+
+// DataURL 返回图片或文件的 data URL，远程图片直接返回 URL
+func (p ContentPart) DataURL() string {
+	if p.URL != "" {
+		return p.URL
+	}
+	return "data:" + p.MIMEType + ";base64," + p.Data
+}
+
+// PartsText 拼接内容片段的文本形式
+func PartsText(parts []ContentPart) string {
+	texts := make([]string, 0, len(parts))
+	for _, part := range parts {
+		texts = append(texts, part.String())
+	}
+	return strings.Join(texts, "\n")
+}
+
+// HasMedia 判断请求中是否有包含图片或文件的消息，工具返回的图片不计算在内
+func (r CompletionRequest) HasMedia() bool {
+	for _, m := range r.Messages {
+		if m.Role != "tool" && m.HasMedia() {
+			return true
+		}
+	}
+	return false
+}
+
+// RejectMedia 只支持文本的模型收到包含图片或文件的消息时返回错误
+// 工具返回的图片不受影响，它们会以占位符的形式发送
+func (r CompletionRequest) RejectMedia(provider, model string) error {
+	if r.HasMedia() {
+		return fmt.Errorf("%s: 模型 %s 只支持文本输入，无法处理消息中的图片或文件", provider, model)
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package llm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageTextContent(t *testing.T) {
+	msg := Message{Role: "tool", Content: "not found"}
+	assert.Equal(t, "not found", msg.TextContent())
+
+	msg.IsError = true
+	assert.Equal(t, "Error: the tool call failed.\nnot found", msg.TextContent())
+}
+
+func TestMessageImages(t *testing.T) {
+	msg := Message{Parts: []ContentPart{
+		{Type: ContentTypeText, Text: "a"},
+		{Type: ContentTypeImage, MIMEType: "image/png", Data: "YWJj"},
+	}}
+	assert.Equal(t, []ContentPart{{Type: ContentTypeImage, MIMEType: "image/png", Data: "YWJj"}}, msg.Images())
+	assert.Nil(t, Message{Content: "a"}.Images())
+}
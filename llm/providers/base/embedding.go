@@ -0,0 +1,80 @@
+package base
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/sjzsdu/wn/llm"
+)
+
+// EmbedFunc 请求一批文本的向量，返回的向量顺序与输入一致
+type EmbedFunc func(ctx context.Context, batch []string) ([][]float32, llm.Usage, error)
+
+// EmbeddingBatcher 分批请求向量，检查返回的数量和维度并累计用量，零值可以直接使用
+type EmbeddingBatcher struct {
+	mu         sync.Mutex
+	dimensions int
+	usage      llm.Usage
+}
+
+// Run 每次最多发送 batchSize 条文本，任意一批失败时返回错误
+func (b *EmbeddingBatcher) Run(ctx context.Context, texts []string, batchSize int, embed EmbedFunc) ([][]float32, error) {
+	if batchSize <= 0 {
+		batchSize = len(texts)
+	}
+
+	vectors := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += batchSize {
+		batch := texts[start:min(start+batchSize, len(texts))]
+		result, usage, err := embed(ctx, batch)
+		if err != nil {
+			return nil, err
+		}
+		if len(result) != len(batch) {
+			return nil, fmt.Errorf("embeddings: 请求 %d 条文本，返回了 %d 个向量", len(batch), len(result))
+		}
+		if err := b.record(result, usage); err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, result...)
+	}
+	return vectors, nil
+}
+
+func (b *EmbeddingBatcher) record(vectors [][]float32, usage llm.Usage) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, vector := range vectors {
+		if len(vector) == 0 {
+			return fmt.Errorf("embeddings: 返回了空向量")
+		}
+		if b.dimensions == 0 {
+			b.dimensions = len(vector)
+		}
+		if len(vector) != b.dimensions {
+			return fmt.Errorf("embeddings: 向量维度不一致，期望 %d，实际 %d", b.dimensions, len(vector))
+		}
+	}
+	if usage.TotalTokens == 0 {
+		usage.TotalTokens = usage.PromptTokens
+	}
+	b.usage.PromptTokens += usage.PromptTokens
+	b.usage.TotalTokens += usage.TotalTokens
+	return nil
+}
+
+// Dimensions 返回已收到的向量维度，第一次请求前返回 0
+func (b *EmbeddingBatcher) Dimensions() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.dimensions
+}
+
+// EmbeddingUsage 返回累计用量
+func (b *EmbeddingBatcher) EmbeddingUsage() llm.Usage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.usage
+}
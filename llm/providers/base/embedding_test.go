@@ -0,0 +1,63 @@
+package base
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sjzsdu/wn/llm"
+)
+
+func TestEmbeddingBatcher(t *testing.T) {
+	var b EmbeddingBatcher
+	var batches [][]string
+	embed := func(ctx context.Context, batch []string) ([][]float32, llm.Usage, error) {
+		batches = append(batches, batch)
+		vectors := make([][]float32, len(batch))
+		for i := range batch {
+			vectors[i] = []float32{float32(len(batches)), float32(i)}
+		}
+		return vectors, llm.Usage{PromptTokens: len(batch)}, nil
+	}
+
+	vectors, err := b.Run(context.Background(), []string{"a", "b", "c", "d", "e"}, 2, embed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(batches) != 3 || len(batches[2]) != 1 {
+		t.Errorf("unexpected batches: %v", batches)
+	}
+	if len(vectors) != 5 || vectors[4][0] != 3 || vectors[1][1] != 1 {
+		t.Errorf("unexpected vectors: %v", vectors)
+	}
+	if b.Dimensions() != 2 || b.EmbeddingUsage() != (llm.Usage{PromptTokens: 5, TotalTokens: 5}) {
+		t.Errorf("dimensions %d, usage %+v", b.Dimensions(), b.EmbeddingUsage())
+	}
+
+	if vectors, err := b.Run(context.Background(), nil, 2, embed); err != nil || len(vectors) != 0 {
+		t.Errorf("empty input: %v, %v", vectors, err)
+	}
+}
+
+func TestEmbeddingBatcherErrors(t *testing.T) {
+	tests := map[string]EmbedFunc{
+		"count": func(ctx context.Context, batch []string) ([][]float32, llm.Usage, error) {
+			return [][]float32{{1}}, llm.Usage{}, nil
+		},
+		"dimensions": func(ctx context.Context, batch []string) ([][]float32, llm.Usage, error) {
+			return [][]float32{{1}, {1, 2}}, llm.Usage{}, nil
+		},
+		"empty": func(ctx context.Context, batch []string) ([][]float32, llm.Usage, error) {
+			return [][]float32{{1}, nil}, llm.Usage{}, nil
+		},
+		"request": func(ctx context.Context, batch []string) ([][]float32, llm.Usage, error) {
+			return nil, llm.Usage{}, errors.New("boom")
+		},
+	}
+	for name, embed := range tests {
+		var b EmbeddingBatcher
+		if _, err := b.Run(context.Background(), []string{"a", "b"}, 0, embed); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -6,35 +6,55 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"math"
+	"math/rand/v2"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/go-resty/resty/v2"
 )
 
+// maxRetryWait 单次重试的最长等待时间，Retry-After 超过该值时按该值等待
+const maxRetryWait = time.Minute
+
 func NewHTTPHandler(apiKey, endpoint string, config RequestConfig) *HTTPHandler {
 	client := resty.New()
+	h := &HTTPHandler{
+		APIKey:      apiKey,
+		APIEndpoint: endpoint,
+		Client:      client,
+		Config:      config,
+		Limiter:     NewRateLimiter(config.RateLimit),
+	}
 
 	// 基础配置
 	client.SetTimeout(time.Duration(config.Timeout) * time.Second)
 	client.SetHeaders(config.Headers)
 
+	// 每次发送（包括重试）前等待限流器
+	client.OnBeforeRequest(func(c *resty.Client, r *resty.Request) error {
+		return h.Limiter.Wait(r.Context(), EstimateTokens(r.Body))
+	})
+
 	// 重试配置
 	if config.RetryConfig != nil {
 		client.SetRetryCount(config.RetryConfig.MaxRetries)
-		client.SetRetryWaitTime(time.Duration(config.RetryConfig.RetryDelay) * time.Second)
-
-		// 根据重试策略设置退避时间
-		if config.RetryConfig.RetryPolicy == RetryPolicyExponential {
-			client.SetRetryAfter(func(client *resty.Client, resp *resty.Response) (time.Duration, error) {
-				return time.Duration(math.Pow(2, float64(client.RetryCount))) * time.Second, nil
-			})
-		}
+		client.SetRetryWaitTime(0)
+		client.SetRetryMaxWaitTime(maxRetryWait)
+		client.SetRetryAfter(func(c *resty.Client, resp *resty.Response) (time.Duration, error) {
+			if d, ok := retryAfter(resp); ok {
+				// 限流时暂停共享限流器的所有请求
+				if resp.StatusCode() == http.StatusTooManyRequests {
+					h.Limiter.Pause(d)
+				}
+				return d, nil
+			}
+			return config.RetryConfig.Backoff(resp.Request.Attempt), nil
+		})
 
 		// 设置重试条件
 		client.AddRetryCondition(func(r *resty.Response, err error) bool {
-			return err != nil || r.StatusCode() >= 500
+			return err != nil || r.StatusCode() == http.StatusTooManyRequests || r.StatusCode() >= 500
 		})
 	}
 
@@ -45,12 +65,40 @@ func NewHTTPHandler(apiKey, endpoint string, config RequestConfig) *HTTPHandler
 		IdleConnTimeout:     90 * time.Second,
 	})
 
-	return &HTTPHandler{
-		APIKey:      apiKey,
-		APIEndpoint: endpoint,
-		Client:      client,
-		Config:      config,
+	return h
+}
+
+// Backoff 返回第 attempt 次请求失败后的等待时间，在计算值的一半到全部之间随机取值
+func (c *RetryConfig) Backoff(attempt int) time.Duration {
+	delay := time.Duration(c.RetryDelay) * time.Second
+	if c.RetryPolicy == RetryPolicyExponential {
+		delay <<= min(max(attempt-1, 0), 10)
+	} else {
+		delay *= time.Duration(max(attempt, 1))
 	}
+	delay = min(delay, maxRetryWait)
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + time.Duration(rand.Int64N(int64(delay/2)+1))
+}
+
+// retryAfter 解析响应的 Retry-After 请求头，支持秒数和 HTTP 日期
+func retryAfter(resp *resty.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	value := resp.Header().Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
 }
 
 func (h *HTTPHandler) DoGet(ctx context.Context, path string, params map[string]string) (*resty.Response, error) {
@@ -110,6 +158,9 @@ func (h *HTTPHandler) HandleStreamResponse(resp *resty.Response, handler StreamH
 	if resp == nil {
 		return fmt.Errorf("响应对象为空")
 	}
+	if resp.StatusCode() >= 400 {
+		return NewStatusError(resp)
+	}
 
 	reader := resp.RawResponse.Body
 	if reader == nil {
@@ -136,15 +187,39 @@ func (h *HTTPHandler) HandleStreamResponse(resp *resty.Response, handler StreamH
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		if err == io.EOF {
-			return nil
-		}
-		if err.Error() == "http: read on closed response body" {
-			return nil
-		}
+	if err := scanner.Err(); err != nil && err != io.EOF && err.Error() != "http: read on closed response body" {
 		return fmt.Errorf("读取流式响应失败: %w", err)
 	}
 
+	// 流结束后发送最终响应
+	if finisher, ok := handler.(StreamFinisher); ok {
+		return finisher.FinishStream()
+	}
 	return nil
 }
+
+// StatusError 表示接口返回了非成功状态码
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+// NewStatusError 根据响应创建 StatusError，未解析的流式响应会读取并关闭响应体
+func NewStatusError(resp *resty.Response) *StatusError {
+	body := resp.String()
+	if body == "" && resp.RawResponse != nil && resp.RawResponse.Body != nil {
+		data, _ := io.ReadAll(io.LimitReader(resp.RawResponse.Body, 64*1024))
+		resp.RawResponse.Body.Close()
+		body = string(data)
+	}
+	return &StatusError{StatusCode: resp.StatusCode(), Body: body}
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("请求失败，状态码：%d，响应：%s", e.StatusCode, e.Body)
+}
+
+// HTTPStatus 返回响应状态码
+func (e *StatusError) HTTPStatus() int {
+	return e.StatusCode
+}
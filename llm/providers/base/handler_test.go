@@ -3,6 +3,9 @@ package base
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 )
@@ -119,3 +122,26 @@ func (h *testStreamHandler) HandleStream(chunk []byte) error {
 	*h.data = append(*h.data, chunk...)
 	return nil
 }
+
+func TestStatusError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("overloaded"))
+	}))
+	defer server.Close()
+
+	handler := NewHTTPHandler("", server.URL, RequestConfig{Timeout: 5})
+	resp, err := handler.DoStream(context.Background(), "/", map[string]string{})
+	if err != nil {
+		t.Fatalf("DoStream failed: %v", err)
+	}
+
+	err = handler.HandleStreamResponse(resp, nil)
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("expected StatusError, got %v", err)
+	}
+	if statusErr.HTTPStatus() != http.StatusServiceUnavailable || statusErr.Body != "overloaded" {
+		t.Errorf("unexpected status error: %+v", statusErr)
+	}
+}
@@ -1,9 +1,11 @@
 package base
 
-// NewProvider 创建新的Provider实例
+// NewProvider 创建新的Provider实例，同名提供商共享限流器
 func NewProvider(name, apiKey, endpoint, model string, config RequestConfig) *Provider {
+	handler := NewHTTPHandler(apiKey, endpoint, config)
+	handler.Limiter = SharedRateLimiter(name, config.RateLimit)
 	return &Provider{
-		HTTPHandler: NewHTTPHandler(apiKey, endpoint, config),
+		HTTPHandler: handler,
 		Model:       model,
 		Name:        name,
 		MaxTokens:   2048,
@@ -25,3 +27,26 @@ func (p *Provider) SetModel(model string) string {
 func (p *Provider) GetModel() string {
 	return p.Model
 }
+
+// RequestModel 返回请求使用的模型，请求中未指定时使用当前模型
+func (p *Provider) RequestModel(model string) string {
+	if model != "" {
+		return model
+	}
+	return p.Model
+}
+
+// ToolChoice 把 tool_choice 转换为 OpenAI 兼容接口的格式，工具名称转换为指定函数的对象
+func ToolChoice(choice string) interface{} {
+	switch choice {
+	case "":
+		return nil
+	case "auto", "none", "required":
+		return choice
+	default:
+		return map[string]interface{}{
+			"type":     "function",
+			"function": map[string]string{"name": choice},
+		}
+	}
+}
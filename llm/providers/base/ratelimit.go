@@ -0,0 +1,172 @@
+package base
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimit 每分钟的请求数和 token 数限制，0 表示不限制
+type RateLimit struct {
+	RequestsPerMinute int
+	TokensPerMinute   int
+}
+
+// RateLimitFromOptions 从配置中读取 WN_<NAME>_RPM 和 WN_<NAME>_TPM
+func RateLimitFromOptions(name string, options map[string]interface{}) RateLimit {
+	prefix := "WN_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+	read := func(key string) int {
+		value, _ := options[prefix+key].(string)
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || n < 0 {
+			return 0
+		}
+		return n
+	}
+	return RateLimit{
+		RequestsPerMinute: read("RPM"),
+		TokensPerMinute:   read("TPM"),
+	}
+}
+
+// bucket 令牌桶，每分钟补充 capacity 个令牌，capacity 为 0 表示不限制
+type bucket struct {
+	capacity  float64
+	available float64
+	last      time.Time
+}
+
+func newBucket(capacity int, now time.Time) bucket {
+	return bucket{capacity: float64(capacity), available: float64(capacity), last: now}
+}
+
+func (b *bucket) refill(now time.Time) {
+	if b.capacity == 0 {
+		return
+	}
+	b.available = min(b.capacity, b.available+now.Sub(b.last).Minutes()*b.capacity)
+	b.last = now
+}
+
+// wait 返回取得 n 个令牌还需等待的时间，超过容量的请求按容量计算
+func (b *bucket) wait(n float64) time.Duration {
+	if b.capacity == 0 {
+		return 0
+	}
+	n = min(n, b.capacity)
+	if b.available >= n {
+		return 0
+	}
+	return time.Duration((n - b.available) / b.capacity * float64(time.Minute))
+}
+
+func (b *bucket) take(n float64) {
+	if b.capacity == 0 {
+		return
+	}
+	b.available -= min(n, b.capacity)
+}
+
+// RateLimiter 令牌桶限流器，请求数和 token 数分别限制
+type RateLimiter struct {
+	mu       sync.Mutex
+	limit    RateLimit
+	requests bucket
+	tokens   bucket
+	// pausedUntil 之前暂停所有请求，用于响应 429 的 Retry-After
+	pausedUntil time.Time
+}
+
+// NewRateLimiter 创建限流器，令牌桶初始为满
+func NewRateLimiter(limit RateLimit) *RateLimiter {
+	l := &RateLimiter{}
+	l.setLimit(limit)
+	return l
+}
+
+func (l *RateLimiter) setLimit(limit RateLimit) {
+	now := time.Now()
+	l.limit = limit
+	l.requests = newBucket(limit.RequestsPerMinute, now)
+	l.tokens = newBucket(limit.TokensPerMinute, now)
+}
+
+// Wait 阻塞直到可以发送一个预计消耗 tokens 个 token 的请求
+func (l *RateLimiter) Wait(ctx context.Context, tokens int) error {
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		l.requests.refill(now)
+		l.tokens.refill(now)
+		delay := max(l.pausedUntil.Sub(now), l.requests.wait(1), l.tokens.wait(float64(tokens)))
+		if delay <= 0 {
+			l.requests.take(1)
+			l.tokens.take(float64(tokens))
+			l.mu.Unlock()
+			return nil
+		}
+		l.mu.Unlock()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Pause 在 d 时间内暂停所有共享该限流器的请求
+func (l *RateLimiter) Pause(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until := time.Now().Add(d); until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+}
+
+var (
+	limitersMu sync.Mutex
+	limiters   = make(map[string]*RateLimiter)
+)
+
+// SharedRateLimiter 返回同名提供商共享的限流器，限制变化时更新现有限流器
+func SharedRateLimiter(name string, limit RateLimit) *RateLimiter {
+	limitersMu.Lock()
+	defer limitersMu.Unlock()
+
+	l, ok := limiters[name]
+	if !ok {
+		l = NewRateLimiter(limit)
+		limiters[name] = l
+		return l
+	}
+
+	l.mu.Lock()
+	if l.limit != limit {
+		l.setLimit(limit)
+	}
+	l.mu.Unlock()
+	return l
+}
+
+// EstimateTokens 按每 4 个字节 1 个 token 估算请求体的 token 数
+func EstimateTokens(body interface{}) int {
+	var size int
+	switch b := body.(type) {
+	case nil:
+		return 0
+	case []byte:
+		size = len(b)
+	case string:
+		size = len(b)
+	default:
+		data, _ := json.Marshal(b)
+		size = len(data)
+	}
+	return size/4 + 1
+}
@@ -0,0 +1,102 @@
+package base
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimitFromOptions(t *testing.T) {
+	limit := RateLimitFromOptions("my-gateway", map[string]interface{}{
+		"WN_MY_GATEWAY_RPM": "60",
+		"WN_MY_GATEWAY_TPM": "invalid",
+	})
+	if limit != (RateLimit{RequestsPerMinute: 60}) {
+		t.Errorf("unexpected limit: %+v", limit)
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	// 每分钟 600 个请求，即每 100ms 补充一个
+	l := NewRateLimiter(RateLimit{RequestsPerMinute: 600, TokensPerMinute: 6000})
+	l.requests.available = 1
+
+	ctx := context.Background()
+	start := time.Now()
+	if err := l.Wait(ctx, 10); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Wait(ctx, 10); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Errorf("second request should wait for refill, elapsed %v", elapsed)
+	}
+
+	// token 桶不足时等待，取消上下文立即返回
+	l.tokens.available = 0
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx, 6000); err != context.DeadlineExceeded {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+
+	// 不限制时不等待
+	if err := NewRateLimiter(RateLimit{}).Wait(context.Background(), 1e9); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSharedRateLimiter(t *testing.T) {
+	a := SharedRateLimiter("shared-test", RateLimit{RequestsPerMinute: 10})
+	b := SharedRateLimiter("shared-test", RateLimit{RequestsPerMinute: 20})
+	if a != b || b.limit.RequestsPerMinute != 20 {
+		t.Errorf("limiter should be shared and updated")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	config := RetryConfig{RetryDelay: 1, RetryPolicy: RetryPolicyExponential}
+	for attempt, want := range map[int]time.Duration{1: time.Second, 3: 4 * time.Second, 20: maxRetryWait} {
+		d := config.Backoff(attempt)
+		if d < want/2 || d > want {
+			t.Errorf("attempt %d: backoff %v not in [%v, %v]", attempt, d, want/2, want)
+		}
+	}
+
+	config.RetryPolicy = RetryPolicyLinear
+	if d := config.Backoff(3); d < 1500*time.Millisecond || d > 3*time.Second {
+		t.Errorf("linear backoff %v", d)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch calls.Add(1) {
+		case 1:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			w.Write([]byte("ok"))
+		}
+	}))
+	defer server.Close()
+
+	handler := NewHTTPHandler("", server.URL, RequestConfig{
+		Timeout:     5,
+		RetryConfig: &RetryConfig{MaxRetries: 3, RetryPolicy: RetryPolicyExponential},
+	})
+	resp, err := handler.DoPost(context.Background(), "/", []byte("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode() != http.StatusOK || calls.Load() != 3 {
+		t.Errorf("expected success after 3 calls, got %d after %d", resp.StatusCode(), calls.Load())
+	}
+}
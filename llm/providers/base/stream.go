@@ -0,0 +1,190 @@
+package base
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/sjzsdu/wn/llm"
+)
+
+// ToolCallDelta 流式响应中工具调用的增量，Index 相同的增量属于同一个工具调用
+// 只有第一个增量带 ID 和 Name，之后的增量只带参数片段
+type ToolCallDelta struct {
+	Index     int
+	ID        string
+	Type      string
+	Name      string
+	Arguments string
+}
+
+type toolCallBuilder struct {
+	id        string
+	typ       string
+	name      string
+	arguments strings.Builder
+}
+
+// StreamAccumulator 汇总流式响应的文本、思考过程、工具调用和用量，流结束时发送包含完整结果的最终响应
+type StreamAccumulator struct {
+	handler      llm.StreamHandler
+	content      strings.Builder
+	reasoning    strings.Builder
+	calls        []*toolCallBuilder
+	byIndex      map[int]*toolCallBuilder
+	usage        llm.Usage
+	finishReason string
+	finished     bool
+}
+
+func NewStreamAccumulator(handler llm.StreamHandler) *StreamAccumulator {
+	return &StreamAccumulator{
+		handler: handler,
+		byIndex: make(map[int]*toolCallBuilder),
+	}
+}
+
+// AddText 追加文本增量并转发给回调
+func (a *StreamAccumulator) AddText(text string) {
+	if text == "" {
+		return
+	}
+	a.content.WriteString(text)
+	a.handler(llm.StreamResponse{
+		Content: text,
+		Done:    false,
+	})
+}
+
+// AddReasoning 追加思考过程增量并转发给回调
+func (a *StreamAccumulator) AddReasoning(text string) {
+	if text == "" {
+		return
+	}
+	a.reasoning.WriteString(text)
+	a.handler(llm.StreamResponse{
+		Reasoning: text,
+		Done:      false,
+	})
+}
+
+// AddToolCall 合并工具调用增量
+// 同一 Index 出现不同的 ID 时视为新的工具调用，兼容不返回 index 的服务
+func (a *StreamAccumulator) AddToolCall(delta ToolCallDelta) {
+	call, ok := a.byIndex[delta.Index]
+	if !ok || (delta.ID != "" && call.id != "" && call.id != delta.ID) {
+		call = &toolCallBuilder{}
+		a.byIndex[delta.Index] = call
+		a.calls = append(a.calls, call)
+	}
+	if delta.ID != "" {
+		call.id = delta.ID
+	}
+	if delta.Type != "" {
+		call.typ = delta.Type
+	}
+	if delta.Name != "" {
+		call.name = delta.Name
+	}
+	call.arguments.WriteString(delta.Arguments)
+}
+
+// AppendToolCall 添加一个完整的工具调用，用于一次返回全部参数的服务，ID 为空时自动生成
+func (a *StreamAccumulator) AppendToolCall(call llm.ToolCall) {
+	builder := &toolCallBuilder{id: call.ID, typ: call.Type, name: call.Function}
+	if len(call.Arguments) > 0 {
+		data, _ := json.Marshal(call.Arguments)
+		builder.arguments.Write(data)
+	}
+	a.calls = append(a.calls, builder)
+}
+
+// ResetToolCalls 清空已收到的工具调用，用于每次返回全部工具调用的服务
+func (a *StreamAccumulator) ResetToolCalls() {
+	a.calls = nil
+	a.byIndex = make(map[int]*toolCallBuilder)
+}
+
+// SetUsage 记录用量，未返回总数时按输入和输出相加
+func (a *StreamAccumulator) SetUsage(usage llm.Usage) {
+	if usage.TotalTokens == 0 {
+		usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+	}
+	if usage.TotalTokens > 0 {
+		a.usage = usage
+	}
+}
+
+// SetFinishReason 记录结束原因，最终响应在 Finish 时发送
+func (a *StreamAccumulator) SetFinishReason(reason string) {
+	if reason != "" {
+		a.finishReason = reason
+	}
+}
+
+// ToolCalls 返回完整的工具调用，参数不是有效的 JSON 对象时返回错误
+func (a *StreamAccumulator) ToolCalls() ([]llm.ToolCall, error) {
+	if len(a.calls) == 0 {
+		return nil, nil
+	}
+
+	calls := make([]llm.ToolCall, 0, len(a.calls))
+	for i, call := range a.calls {
+		arguments := map[string]interface{}{}
+		if raw := strings.TrimSpace(call.arguments.String()); raw != "" {
+			if err := json.Unmarshal([]byte(raw), &arguments); err != nil {
+				return nil, fmt.Errorf("工具 %s 的参数不是有效的 JSON: %w", call.name, err)
+			}
+		}
+		id := call.id
+		if id == "" {
+			id = fmt.Sprintf("call_%d", i)
+		}
+		typ := call.typ
+		if typ == "" {
+			typ = "function"
+		}
+		calls = append(calls, llm.ToolCall{
+			ID:        id,
+			Type:      typ,
+			Function:  call.name,
+			Arguments: arguments,
+		})
+	}
+	return calls, nil
+}
+
+// Finish 发送最终响应，重复调用时忽略，未收到结束原因时说明响应被中断
+func (a *StreamAccumulator) Finish() error {
+	if a.finished {
+		return nil
+	}
+	if a.finishReason == "" {
+		return fmt.Errorf("流式响应在结束前中断")
+	}
+	a.finished = true
+
+	toolCalls, err := a.ToolCalls()
+	if err != nil {
+		return err
+	}
+	content := a.content.String()
+	a.handler(llm.StreamResponse{
+		Content:      content,
+		FinishReason: a.finishReason,
+		Done:         true,
+		Response: &llm.CompletionResponse{
+			Content:      content,
+			FinishReason: a.finishReason,
+			ToolCalls:    toolCalls,
+			Reasoning:    a.reasoning.String(),
+			Usage:        a.usage,
+		},
+	})
+	return nil
+}
+
+// FinishStream 实现 StreamFinisher
+func (a *StreamAccumulator) FinishStream() error {
+	return a.Finish()
+}
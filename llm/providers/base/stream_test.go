@@ -0,0 +1,88 @@
+package base
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sjzsdu/wn/llm"
+)
+
+func TestStreamAccumulator(t *testing.T) {
+	var events []llm.StreamResponse
+	a := NewStreamAccumulator(func(resp llm.StreamResponse) {
+		events = append(events, resp)
+	})
+
+	a.AddText("")
+	a.AddText("checking")
+	// 两个并行工具调用的参数片段交替到达
+	a.AddToolCall(ToolCallDelta{Index: 0, ID: "a", Type: "function", Name: "weather"})
+	a.AddToolCall(ToolCallDelta{Index: 1, ID: "b", Name: "time"})
+	a.AddToolCall(ToolCallDelta{Index: 0, Arguments: `{"city":`})
+	a.AddToolCall(ToolCallDelta{Index: 1, Arguments: `{}`})
+	a.AddToolCall(ToolCallDelta{Index: 0, Arguments: `"Paris"}`})
+	// 不返回 index 的服务用新的 ID 表示新的工具调用
+	a.AddToolCall(ToolCallDelta{Index: 1, ID: "c", Name: "noargs"})
+	a.AppendToolCall(llm.ToolCall{Function: "complete", Arguments: map[string]interface{}{"n": 1.0}})
+	a.SetUsage(llm.Usage{PromptTokens: 3, CompletionTokens: 4})
+
+	if err := a.Finish(); err == nil {
+		t.Fatal("expected error before finish reason")
+	}
+	a.SetFinishReason("tool_calls")
+	if err := a.Finish(); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.FinishStream(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(events) != 2 || events[0].Content != "checking" || !events[1].Done {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+	resp := events[1].Response
+	want := []llm.ToolCall{
+		{ID: "a", Type: "function", Function: "weather", Arguments: map[string]interface{}{"city": "Paris"}},
+		{ID: "b", Type: "function", Function: "time", Arguments: map[string]interface{}{}},
+		{ID: "c", Type: "function", Function: "noargs", Arguments: map[string]interface{}{}},
+		{ID: "call_3", Type: "function", Function: "complete", Arguments: map[string]interface{}{"n": 1.0}},
+	}
+	if !reflect.DeepEqual(resp.ToolCalls, want) {
+		t.Errorf("tool calls = %+v", resp.ToolCalls)
+	}
+	if resp.Usage.TotalTokens != 7 || resp.Content != "checking" || resp.FinishReason != "tool_calls" {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+}
+
+func TestStreamAccumulatorInvalidArguments(t *testing.T) {
+	a := NewStreamAccumulator(func(llm.StreamResponse) {})
+	a.AddToolCall(ToolCallDelta{ID: "a", Name: "weather", Arguments: `{"city":`})
+	a.SetFinishReason("tool_calls")
+	if err := a.Finish(); err == nil {
+		t.Error("expected invalid JSON error")
+	}
+}
+
+func TestStreamAccumulatorReasoning(t *testing.T) {
+	var events []llm.StreamResponse
+	a := NewStreamAccumulator(func(resp llm.StreamResponse) {
+		events = append(events, resp)
+	})
+
+	a.AddReasoning("")
+	a.AddReasoning("think")
+	a.AddReasoning("ing")
+	a.AddText("answer")
+	a.SetFinishReason("stop")
+	if err := a.Finish(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(events) != 4 || events[0].Reasoning != "think" || events[0].Content != "" || events[2].Reasoning != "" {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+	if resp := events[3].Response; resp.Reasoning != "thinking" || resp.Content != "answer" {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+}
@@ -24,6 +24,7 @@ type RequestConfig struct {
 	Timeout     int
 	RetryConfig *RetryConfig
 	Headers     map[string]string
+	RateLimit   RateLimit
 }
 
 // ResponseHandler 定义响应处理器
@@ -36,6 +37,11 @@ type StreamHandler interface {
 	HandleStream([]byte) error
 }
 
+// StreamFinisher 流式响应读取完毕后调用，用于发送最终响应
+type StreamFinisher interface {
+	FinishStream() error
+}
+
 // MiddlewareFunc 定义中间件函数类型
 type MiddlewareFunc func(RequestConfig) RequestConfig
 
@@ -44,6 +50,7 @@ type HTTPHandler struct {
 	APIEndpoint string
 	Client      *resty.Client
 	Config      RequestConfig
+	Limiter     *RateLimiter
 }
 
 // Provider 提供基础的 LLM Provider 实现
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -24,6 +25,8 @@ const (
 type Provider struct {
 	base.Provider
 	StreamHandler StreamHandler
+	// ThinkingBudget 扩展思考的 token 预算，0 表示不开启
+	ThinkingBudget int
 }
 
 func New(options map[string]interface{}) (llm.Provider, error) {
@@ -44,6 +47,7 @@ func New(options map[string]interface{}) (llm.Provider, error) {
 			RetryDelay:  1,
 			RetryPolicy: base.RetryPolicyLinear,
 		},
+		RateLimit: base.RateLimitFromOptions(name, options),
 	}
 
 	p := &Provider{
@@ -62,16 +66,31 @@ func New(options map[string]interface{}) (llm.Provider, error) {
 	if model, ok := options["WN_CLAUDE_MODEL"].(string); ok {
 		p.Model = model
 	}
+	if budget, ok := options["WN_CLAUDE_THINKING_BUDGET"].(string); ok && budget != "" {
+		n, err := strconv.Atoi(strings.TrimSpace(budget))
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("claude: invalid WN_CLAUDE_THINKING_BUDGET %q", budget)
+		}
+		p.ThinkingBudget = n
+	}
 
 	return p, nil
 }
 
 func (p *Provider) PrepareRequest(req llm.CompletionRequest, stream bool) ([]byte, error) {
+	if err := req.Reject(name, llm.ParamSeed, llm.ParamPresencePenalty, llm.ParamFrequencyPenalty); err != nil {
+		return nil, err
+	}
+
 	// 创建请求体结构
 	request := &ClaudeRequest{
-		Model:    p.Model,
-		Stream:   stream,
-		Messages: p.handleMessages(req.Messages),
+		Model:         p.RequestModel(req.Model),
+		Stream:        stream,
+		Messages:      p.handleMessages(req.Messages),
+		ToolChoice:    toolChoice(req.ToolChoice),
+		Temperature:   req.Temperature,
+		TopP:          req.TopP,
+		StopSequences: req.Stop,
 	}
 
 	// 处理工具
@@ -84,6 +103,13 @@ func (p *Provider) PrepareRequest(req llm.CompletionRequest, stream bool) ([]byt
 		request.MaxTokens = req.MaxTokens
 	}
 
+	// 扩展思考要求 max_tokens 大于预算，且不支持修改 temperature
+	if p.ThinkingBudget > 0 {
+		request.Thinking = &Thinking{Type: "enabled", BudgetTokens: p.ThinkingBudget}
+		request.MaxTokens = max(request.MaxTokens, p.ThinkingBudget+1024)
+		request.Temperature = nil
+	}
+
 	if share.GetDebug() {
 		helper.PrintWithLabel("[DEBUG] Request Body", request)
 	}
@@ -101,13 +127,16 @@ func (p *Provider) Complete(ctx context.Context, req llm.CompletionRequest) (*ll
 	if err != nil {
 		return nil, err
 	}
+	if resp.StatusCode() != 200 {
+		return nil, base.NewStatusError(resp)
+	}
 
 	return p.ParseResponse(resp.RawBody())
 }
 
 func (p *Provider) CompleteStream(ctx context.Context, req llm.CompletionRequest, handler llm.StreamHandler) error {
 	p.StreamHandler = NewStreamHandler(handler)
-	jsonBody, err := p.PrepareRequest(req, false)
+	jsonBody, err := p.PrepareRequest(req, true)
 	if err != nil {
 		return fmt.Errorf("marshal request: %w", err)
 	}
@@ -122,7 +151,9 @@ func (p *Provider) CompleteStream(ctx context.Context, req llm.CompletionRequest
 // HandleStream 处理流式响应
 func (p *Provider) HandleStream(bytes []byte) error {
 	line := strings.TrimSpace(string(bytes))
-	helper.PrintWithLabel("[DEBUG] Stream Response", line)
+	if share.GetDebug() {
+		helper.PrintWithLabel("[DEBUG] Stream Response", line)
+	}
 	if line == "" || line == "data: [DONE]" || !strings.HasPrefix(line, "data: ") {
 		return nil
 	}
@@ -131,6 +162,11 @@ func (p *Provider) HandleStream(bytes []byte) error {
 	return p.StreamHandler.AddContent([]byte(data))
 }
 
+// FinishStream 流结束时发送最终响应
+func (p *Provider) FinishStream() error {
+	return p.StreamHandler.Finish()
+}
+
 // 将响应结构体提取到类型定义中
 func (p *Provider) ParseResponse(body io.Reader) (*llm.CompletionResponse, error) {
 	var claudeResp StreamResponse
@@ -142,8 +178,20 @@ func (p *Provider) ParseResponse(body io.Reader) (*llm.CompletionResponse, error
 		return nil, fmt.Errorf("no content in response")
 	}
 
+	// 扩展思考时 thinking 块在 text 块之前
+	var content, reasoning strings.Builder
+	for _, block := range claudeResp.Content {
+		switch block.Type {
+		case "thinking":
+			reasoning.WriteString(block.Thinking)
+		case "", "text":
+			content.WriteString(block.Text)
+		}
+	}
+
 	response := &llm.CompletionResponse{
-		Content:      claudeResp.Content[0].Text,
+		Content:      content.String(),
+		Reasoning:    reasoning.String(),
 		FinishReason: claudeResp.StopReason,
 		Usage: llm.Usage{
 			PromptTokens:     claudeResp.Usage.InputTokens,
@@ -199,12 +247,29 @@ func (p *Provider) handleTools(tools []mcp.Tool) []Tool {
 	return result
 }
 
+// toolChoice 转换 tool_choice，required 对应 any，其他值为工具名称
+func toolChoice(choice string) *ToolChoice {
+	switch choice {
+	case "":
+		return nil
+	case llm.ToolChoiceAuto, llm.ToolChoiceNone:
+		return &ToolChoice{Type: choice}
+	case llm.ToolChoiceRequired:
+		return &ToolChoice{Type: "any"}
+	default:
+		return &ToolChoice{Type: "tool", Name: choice}
+	}
+}
+
 func (p *Provider) handleMessages(messages []llm.Message) []Message {
 	result := make([]Message, len(messages))
 	for i, m := range messages {
 		msg := Message{
 			Role:    m.Role,
-			Content: m.Content,
+			Content: m.TextContent(),
+		}
+		if m.Role != "tool" && m.HasMedia() {
+			msg.Content = contentBlocks(m.Parts)
 		}
 
 		if m.ToolCallId != "" {
@@ -232,6 +297,32 @@ func (p *Provider) handleMessages(messages []llm.Message) []Message {
 	return result
 }
 
+// contentBlocks 按顺序转换内容片段
+func contentBlocks(parts []llm.ContentPart) []ContentBlock {
+	blocks := make([]ContentBlock, 0, len(parts))
+	for _, part := range parts {
+		switch part.Type {
+		case llm.ContentTypeImage, llm.ContentTypeFile:
+			block := ContentBlock{Type: "image", Source: mediaSource(part)}
+			if part.Type == llm.ContentTypeFile {
+				block.Type = "document"
+				block.Title = part.Name
+			}
+			blocks = append(blocks, block)
+		default:
+			blocks = append(blocks, ContentBlock{Type: "text", Text: part.Text})
+		}
+	}
+	return blocks
+}
+
+func mediaSource(part llm.ContentPart) *MediaSource {
+	if part.URL != "" {
+		return &MediaSource{Type: "url", URL: part.URL}
+	}
+	return &MediaSource{Type: "base64", MediaType: part.MIMEType, Data: part.Data}
+}
+
 func init() {
 	llm.Register(name, New)
 }
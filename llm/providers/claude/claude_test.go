@@ -1,8 +1,16 @@
 package claude
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/sjzsdu/wn/llm"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -40,3 +48,131 @@ func TestNew(t *testing.T) {
 		})
 	}
 }
+
+func TestPrepareRequest_SamplingParams(t *testing.T) {
+	p, err := New(map[string]interface{}{"WN_CLAUDE_APIKEY": "key"})
+	assert.NoError(t, err)
+	provider := p.(*Provider)
+	temperature := 0.3
+
+	body, err := provider.PrepareRequest(llm.CompletionRequest{
+		SamplingParams: llm.SamplingParams{
+			Temperature: &temperature,
+			Stop:        []string{"END"},
+			ToolChoice:  llm.ToolChoiceRequired,
+		},
+	}, false)
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), `"tool_choice":{"type":"any"}`)
+	assert.Contains(t, string(body), `"temperature":0.3`)
+	assert.Contains(t, string(body), `"stop_sequences":["END"]`)
+
+	seed := 1
+	_, err = provider.PrepareRequest(llm.CompletionRequest{SamplingParams: llm.SamplingParams{Seed: &seed}}, false)
+	assert.EqualError(t, err, "claude: unsupported parameters: seed")
+}
+
+// replayStream 用 testdata 中录制的 SSE 数据回放流式请求，返回收到的全部事件
+func replayStream(t *testing.T, fixture string) ([]llm.StreamResponse, error) {
+	data, err := os.ReadFile(filepath.Join("testdata", fixture))
+	assert.NoError(t, err)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	p, err := New(map[string]interface{}{"WN_CLAUDE_APIKEY": "key", "WN_CLAUDE_ENDPOINT": server.URL})
+	assert.NoError(t, err)
+
+	var events []llm.StreamResponse
+	err = p.CompleteStream(context.Background(), llm.CompletionRequest{
+		Messages: []llm.Message{{Role: "user", Content: "hi"}},
+	}, func(resp llm.StreamResponse) {
+		events = append(events, resp)
+	})
+	return events, err
+}
+
+func TestCompleteStream(t *testing.T) {
+	events, err := replayStream(t, "tool_use.sse")
+	assert.NoError(t, err)
+	assert.Len(t, events, 2)
+	assert.Equal(t, "Okay, let me check", events[0].Content)
+
+	resp := events[1].Response
+	assert.Equal(t, "tool_use", resp.FinishReason)
+	assert.Equal(t, []llm.ToolCall{
+		{ID: "toolu_01", Type: "function", Function: "get_weather", Arguments: map[string]interface{}{"location": "San Francisco, CA"}},
+		{ID: "toolu_02", Type: "function", Function: "get_time", Arguments: map[string]interface{}{}},
+	}, resp.ToolCalls)
+	assert.Equal(t, llm.Usage{PromptTokens: 472, CompletionTokens: 89, TotalTokens: 561}, resp.Usage)
+}
+
+func TestCompleteStreamThinking(t *testing.T) {
+	events, err := replayStream(t, "thinking.sse")
+	assert.NoError(t, err)
+	assert.Len(t, events, 4)
+	assert.Equal(t, "27 * 453 = 27 * 400 + 27 * 53", events[0].Reasoning)
+	assert.Equal(t, "27 * 453 = 12231", events[2].Content)
+
+	resp := events[3].Response
+	assert.Equal(t, "27 * 453 = 27 * 400 + 27 * 53 = 10800 + 1431 = 12231", resp.Reasoning)
+	assert.Equal(t, "27 * 453 = 12231", resp.Content)
+}
+
+func TestParseResponseThinking(t *testing.T) {
+	p := &Provider{}
+	resp, err := p.ParseResponse(strings.NewReader(`{
+		"content": [
+			{"type": "thinking", "thinking": "先算乘法", "signature": "sig"},
+			{"type": "text", "text": "答案是 12231"}
+		],
+		"stop_reason": "end_turn",
+		"usage": {"input_tokens": 10, "output_tokens": 20}
+	}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "先算乘法", resp.Reasoning)
+	assert.Equal(t, "答案是 12231", resp.Content)
+}
+
+func TestPrepareRequest_Thinking(t *testing.T) {
+	p, err := New(map[string]interface{}{"WN_CLAUDE_APIKEY": "key", "WN_CLAUDE_THINKING_BUDGET": "2048"})
+	assert.NoError(t, err)
+
+	temperature := 0.5
+	body, err := p.(*Provider).PrepareRequest(llm.CompletionRequest{
+		MaxTokens:      1000,
+		SamplingParams: llm.SamplingParams{Temperature: &temperature},
+	}, false)
+	assert.NoError(t, err)
+	var request ClaudeRequest
+	assert.NoError(t, json.Unmarshal(body, &request))
+	assert.Equal(t, &Thinking{Type: "enabled", BudgetTokens: 2048}, request.Thinking)
+	assert.Equal(t, 3072, request.MaxTokens)
+	assert.Nil(t, request.Temperature)
+
+	_, err = New(map[string]interface{}{"WN_CLAUDE_APIKEY": "key", "WN_CLAUDE_THINKING_BUDGET": "many"})
+	assert.Error(t, err)
+}
+
+func TestHandleMessages_Multimodal(t *testing.T) {
+	p := &Provider{}
+	messages := p.handleMessages([]llm.Message{
+		llm.NewUserMessage(
+			llm.TextPart("compare"),
+			llm.ContentPart{Type: llm.ContentTypeImage, MIMEType: "image/png", Data: "YWJj"},
+			llm.ImageURLPart("https://example.com/a.jpg"),
+			llm.ContentPart{Type: llm.ContentTypeFile, Name: "a.pdf", MIMEType: "application/pdf", Data: "JVBERg=="},
+		),
+		{Role: "assistant", Content: "ok"},
+	})
+
+	assert.Equal(t, []ContentBlock{
+		{Type: "text", Text: "compare"},
+		{Type: "image", Source: &MediaSource{Type: "base64", MediaType: "image/png", Data: "YWJj"}},
+		{Type: "image", Source: &MediaSource{Type: "url", URL: "https://example.com/a.jpg"}},
+		{Type: "document", Title: "a.pdf", Source: &MediaSource{Type: "base64", MediaType: "application/pdf", Data: "JVBERg=="}},
+	}, messages[0].Content)
+	assert.Equal(t, "ok", messages[1].Content)
+}
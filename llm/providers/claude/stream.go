@@ -2,116 +2,75 @@ package claude
 
 import (
 	"encoding/json"
-	"strings"
+	"fmt"
 
-	"github.com/sjzsdu/wn/helper"
 	"github.com/sjzsdu/wn/llm"
+	"github.com/sjzsdu/wn/llm/providers/base"
 )
 
+// StreamHandler 解析 Messages API 的流式事件，由 base.StreamAccumulator 汇总
 type StreamHandler struct {
-	StreamHandler    llm.StreamHandler
-	ContentBuilder   strings.Builder
-	ArgumentsBuilder strings.Builder
-	CurrentToolCall  *llm.ToolCall
-	ToolCalls        []llm.ToolCall
-	Usage            llm.Usage
+	*base.StreamAccumulator
+	inputTokens int
 }
 
 func NewStreamHandler(handler llm.StreamHandler) StreamHandler {
 	return StreamHandler{
-		StreamHandler: handler,
+		StreamAccumulator: base.NewStreamAccumulator(handler),
 	}
 }
 
 func (h *StreamHandler) AddContent(data []byte) error {
-	var streamResp StreamResponse
-	if err := json.Unmarshal(data, &streamResp); err != nil {
+	var event StreamEvent
+	if err := json.Unmarshal(data, &event); err != nil {
 		return err
 	}
 
-	if len(streamResp.Content) > 0 {
-		content := streamResp.Content[0].Text
-		h.StreamHandler(llm.StreamResponse{
-			Content:      content,
-			Done:         false,
-			FinishReason: streamResp.StopReason,
-		})
-
-		if content != "" {
-			h.ContentBuilder.WriteString(content)
+	switch event.Type {
+	case "message_start":
+		// 输入 token 数在 message_start 中返回，输出 token 数在 message_delta 中返回
+		if event.Message != nil {
+			h.inputTokens = event.Message.Usage.InputTokens
 		}
-	}
-
-	// 处理工具调用
-	if len(streamResp.Content) > 0 && len(streamResp.Content[0].ToolCalls) > 0 {
-		for _, tc := range streamResp.Content[0].ToolCalls {
-			if tc.ID != "" {
-				// 新的工具调用开始
-				if h.CurrentToolCall == nil || h.CurrentToolCall.ID != tc.ID {
-					if h.CurrentToolCall != nil {
-						// 完成前一个工具调用
-						h.CurrentToolCall.Arguments = helper.StringToMap(h.ArgumentsBuilder.String())
-						h.ToolCalls = append(h.ToolCalls, *h.CurrentToolCall)
-						// 发送工具调用完成通知
-						h.StreamHandler(llm.StreamResponse{
-							Content: "",
-							Done:    false,
-						})
-					}
-					// 创建新的工具调用
-					h.CurrentToolCall = &llm.ToolCall{
-						ID:       tc.ID,
-						Type:     tc.Type,
-						Function: tc.Function.Name,
-					}
-					h.ArgumentsBuilder.Reset()
-					// 发送新工具调用开始通知
-					h.StreamHandler(llm.StreamResponse{
-						Content: "",
-						Done:    false,
-					})
-				}
-				// 累积参数字符串
-				if tc.Function.Arguments != "" {
-					h.ArgumentsBuilder.WriteString(tc.Function.Arguments)
-				}
-			}
+	case "content_block_start":
+		if event.ContentBlock != nil && event.ContentBlock.Type == "tool_use" {
+			h.AddToolCall(base.ToolCallDelta{
+				Index: event.Index,
+				ID:    event.ContentBlock.ID,
+				Type:  "function",
+				Name:  event.ContentBlock.Name,
+			})
 		}
-	}
-
-	// 处理结束
-	if streamResp.StopReason != "" {
-		// 处理最后一个工具调用
-		if h.CurrentToolCall != nil {
-			h.CurrentToolCall.Arguments = helper.StringToMap(h.ArgumentsBuilder.String())
-			h.ToolCalls = append(h.ToolCalls, *h.CurrentToolCall)
-			// 发送最后一个工具调用完成通知
-			h.StreamHandler(llm.StreamResponse{
-				Content: "",
-				Done:    false,
+	case "content_block_delta":
+		if event.Delta == nil {
+			return nil
+		}
+		switch event.Delta.Type {
+		case "text_delta":
+			h.AddText(event.Delta.Text)
+		case "thinking_delta":
+			// signature_delta 只用于多轮对话回传校验，不需要展示
+			h.AddReasoning(event.Delta.Thinking)
+		case "input_json_delta":
+			h.AddToolCall(base.ToolCallDelta{
+				Index:     event.Index,
+				Arguments: event.Delta.PartialJSON,
 			})
 		}
-
-		if streamResp.Usage.InputTokens > 0 {
-			h.Usage = llm.Usage{
-				PromptTokens:     streamResp.Usage.InputTokens,
-				CompletionTokens: streamResp.Usage.OutputTokens,
-				TotalTokens:      streamResp.Usage.InputTokens + streamResp.Usage.OutputTokens,
-			}
+	case "message_delta":
+		if event.Delta != nil {
+			h.SetFinishReason(event.Delta.StopReason)
+		}
+		if event.Usage != nil {
+			h.SetUsage(llm.Usage{
+				PromptTokens:     h.inputTokens,
+				CompletionTokens: event.Usage.OutputTokens,
+			})
+		}
+	case "error":
+		if event.Error != nil {
+			return fmt.Errorf("claude: %s: %s", event.Error.Type, event.Error.Message)
 		}
-
-		h.StreamHandler(llm.StreamResponse{
-			Content:      h.ContentBuilder.String(),
-			FinishReason: streamResp.StopReason,
-			Done:         true,
-			Response: &llm.CompletionResponse{
-				Content:      h.ContentBuilder.String(),
-				FinishReason: streamResp.StopReason,
-				ToolCalls:    h.ToolCalls,
-				Usage:        h.Usage,
-			},
-		})
 	}
-
 	return nil
 }
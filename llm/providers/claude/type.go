@@ -1,11 +1,27 @@
 package claude
 
 type ClaudeRequest struct {
-	Model     string    `json:"model"`
-	Messages  []Message `json:"messages"`
-	MaxTokens int       `json:"max_tokens"`
-	Stream    bool      `json:"stream"`
-	Tools     []Tool    `json:"tools,omitempty"` // 添加工具支持
+	Model         string      `json:"model"`
+	Messages      []Message   `json:"messages"`
+	MaxTokens     int         `json:"max_tokens"`
+	Stream        bool        `json:"stream"`
+	Tools         []Tool      `json:"tools,omitempty"` // 添加工具支持
+	ToolChoice    *ToolChoice `json:"tool_choice,omitempty"`
+	Temperature   *float64    `json:"temperature,omitempty"`
+	TopP          *float64    `json:"top_p,omitempty"`
+	StopSequences []string    `json:"stop_sequences,omitempty"`
+	Thinking      *Thinking   `json:"thinking,omitempty"`
+}
+
+// Thinking 开启扩展思考，BudgetTokens 需小于 max_tokens
+type Thinking struct {
+	Type         string `json:"type"`
+	BudgetTokens int    `json:"budget_tokens"`
+}
+
+type ToolChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
 }
 
 type Tool struct {
@@ -20,11 +36,19 @@ type Function struct {
 }
 
 type Message struct {
-	Role       string     `json:"role"`
-	Content    string     `json:"content"`
-	ToolCallID string     `json:"tool_call_id,omitempty"`
-	Name       string     `json:"name,omitempty"`
-	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
+	Role       string      `json:"role"`
+	Content    interface{} `json:"content"` // string 或 []ContentBlock
+	ToolCallID string      `json:"tool_call_id,omitempty"`
+	Name       string      `json:"name,omitempty"`
+	ToolCalls  []ToolCall  `json:"tool_calls,omitempty"`
+}
+
+// MediaSource 图片或文件的来源，base64 内联或 url 引用
+type MediaSource struct {
+	Type      string `json:"type"`
+	MediaType string `json:"media_type,omitempty"`
+	Data      string `json:"data,omitempty"`
+	URL       string `json:"url,omitempty"`
 }
 
 type ToolCall struct {
@@ -52,6 +76,7 @@ type StreamResponse struct {
 type Content struct {
 	Text      string     `json:"text"`
 	Type      string     `json:"type"`
+	Thinking  string     `json:"thinking,omitempty"`
 	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
 }
 
@@ -59,3 +84,39 @@ type Usage struct {
 	InputTokens  int `json:"input_tokens"`
 	OutputTokens int `json:"output_tokens"`
 }
+
+// StreamEvent Messages API 的流式事件，content_block_* 事件通过 Index 区分内容块
+type StreamEvent struct {
+	Type         string          `json:"type"`
+	Index        int             `json:"index"`
+	Message      *StreamResponse `json:"message,omitempty"`
+	ContentBlock *ContentBlock   `json:"content_block,omitempty"`
+	Delta        *EventDelta     `json:"delta,omitempty"`
+	Usage        *Usage          `json:"usage,omitempty"`
+	Error        *EventError     `json:"error,omitempty"`
+}
+
+// ContentBlock 内容块，用于 content_block_start 事件和多模态消息
+// tool_use 块带工具调用的 ID 和名称，image 和 document 块带图片或文件的来源
+type ContentBlock struct {
+	Type   string       `json:"type"`
+	Text   string       `json:"text,omitempty"`
+	ID     string       `json:"id,omitempty"`
+	Name   string       `json:"name,omitempty"`
+	Source *MediaSource `json:"source,omitempty"`
+	Title  string       `json:"title,omitempty"`
+}
+
+// EventDelta content_block_delta 的文本、思考或参数片段，以及 message_delta 的结束原因
+type EventDelta struct {
+	Type        string `json:"type"`
+	Text        string `json:"text,omitempty"`
+	PartialJSON string `json:"partial_json,omitempty"`
+	Thinking    string `json:"thinking,omitempty"`
+	StopReason  string `json:"stop_reason,omitempty"`
+}
+
+type EventError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
@@ -42,6 +42,7 @@ func New(options map[string]interface{}) (llm.Provider, error) {
 			RetryDelay:  1,
 			RetryPolicy: base.RetryPolicyLinear,
 		},
+		RateLimit: base.RateLimitFromOptions(name, options),
 	}
 
 	p := &Provider{
@@ -67,20 +68,38 @@ func New(options map[string]interface{}) (llm.Provider, error) {
 // PrepareRequest 将通用的 CompletionRequest 转换为 DeepseekRequest
 func (p *Provider) PrepareRequest(req llm.CompletionRequest, stream bool) ([]byte, error) {
 	// 创建 DeepseekRequest
+	if err := req.Reject(name, llm.ParamSeed); err != nil {
+		return nil, err
+	}
+	if err := req.RejectMedia(name, p.RequestModel(req.Model)); err != nil {
+		return nil, err
+	}
+
 	request := &DeepseekRequest{
 		Messages: make([]Message, len(req.Messages)),
-		Model:    p.GetModel(),
+		Model:    p.RequestModel(req.Model),
 		Stream:   stream,
 	}
+	if stream {
+		request.StreamOptions = map[string]bool{"include_usage": true}
+	}
 
 	// 复制基本字段
 	request.MaxTokens = req.MaxTokens
+	request.Temperature = req.Temperature
+	request.TopP = req.TopP
+	request.PresencePenalty = req.PresencePenalty
+	request.FrequencyPenalty = req.FrequencyPenalty
+	request.ToolChoice = base.ToolChoice(req.ToolChoice)
+	if len(req.Stop) > 0 {
+		request.Stop = req.Stop
+	}
 
 	// 转换消息
 	for i, msg := range req.Messages {
 		request.Messages[i] = Message{
 			Role:       msg.Role,
-			Content:    msg.Content,
+			Content:    msg.TextContent(),
 			Name:       msg.Name,
 			ToolCallId: msg.ToolCallId,
 		}
@@ -102,8 +121,10 @@ func (p *Provider) PrepareRequest(req llm.CompletionRequest, stream bool) ([]byt
 		}
 	}
 
-	// 设置响应格式
-	if req.ResponseFormat != "" {
+	// 设置响应格式，不支持 json_schema，根节点为对象时使用 json_object
+	if req.JSONSchema.IsObject() {
+		request.ResponseFormat = ResponseFormat{Type: "json_object"}
+	} else if req.ResponseFormat != "" {
 		request.ResponseFormat = ResponseFormat{
 			Type: req.ResponseFormat,
 		}
@@ -147,7 +168,7 @@ func (p *Provider) Complete(ctx context.Context, req llm.CompletionRequest) (*ll
 	}
 
 	if resp.StatusCode() != 200 {
-		return nil, fmt.Errorf("请求失败，状态码：%d，响应：%s", resp.StatusCode(), resp.String())
+		return nil, base.NewStatusError(resp)
 	}
 	bodyBytes := resp.Body()
 	if share.GetDebug() {
@@ -177,11 +198,13 @@ func (p *Provider) ParseResponse(bodyBytes []byte) (*llm.CompletionResponse, err
 	choice := deepseekResp.Choices[0]
 	response := llm.CompletionResponse{
 		Content:      choice.Message.Content,
+		Reasoning:    choice.Message.ReasoningContent,
 		FinishReason: choice.FinishReason,
 		Usage: llm.Usage{
 			PromptTokens:     deepseekResp.Usage.PromptTokens,
 			CompletionTokens: deepseekResp.Usage.CompletionTokens,
 			TotalTokens:      deepseekResp.Usage.TotalTokens,
+			ReasoningTokens:  deepseekResp.Usage.CompletionTokensDetails.ReasoningTokens,
 		},
 	}
 
@@ -228,6 +251,11 @@ func (p *Provider) HandleStream(bytes []byte) error {
 	return nil
 }
 
+// FinishStream 流结束时发送最终响应
+func (p *Provider) FinishStream() error {
+	return p.StreamHandler.Finish()
+}
+
 // AvailableModels 通过API获取支持的模型列表
 func (p *Provider) AvailableModels() []string {
 	resp, err := p.DoGet(context.Background(), modelsPath, nil)
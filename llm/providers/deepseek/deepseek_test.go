@@ -2,6 +2,10 @@ package deepseek
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/go-resty/resty/v2"
@@ -151,3 +155,63 @@ func TestParseResponse(t *testing.T) {
 		})
 	}
 }
+
+// replayStream 用 testdata 中录制的 SSE 数据回放流式请求，返回收到的全部事件
+func replayStream(t *testing.T, fixture string) ([]llm.StreamResponse, error) {
+	data, err := os.ReadFile(filepath.Join("testdata", fixture))
+	assert.NoError(t, err)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	p, err := New(map[string]interface{}{"WN_DEEPSEEK_APIKEY": "key", "WN_DEEPSEEK_ENDPOINT": server.URL})
+	assert.NoError(t, err)
+
+	var events []llm.StreamResponse
+	err = p.CompleteStream(context.Background(), llm.CompletionRequest{
+		Messages: []llm.Message{{Role: "user", Content: "hi"}},
+	}, func(resp llm.StreamResponse) {
+		events = append(events, resp)
+	})
+	return events, err
+}
+
+func TestCompleteStream(t *testing.T) {
+	events, err := replayStream(t, "tool_calls.sse")
+	assert.NoError(t, err)
+	assert.Equal(t, "我来查询", events[0].Content)
+
+	final := events[len(events)-1]
+	assert.True(t, final.Done)
+	assert.Equal(t, "我来查询", final.Response.Content)
+	assert.Equal(t, []llm.ToolCall{
+		{ID: "call_0_a1", Type: "function", Function: "read_file", Arguments: map[string]interface{}{"path": "main.go"}},
+		{ID: "call_1_b2", Type: "function", Function: "list_dir", Arguments: map[string]interface{}{}},
+	}, final.Response.ToolCalls)
+	assert.Equal(t, llm.Usage{PromptTokens: 120, CompletionTokens: 30, TotalTokens: 150}, final.Response.Usage)
+}
+
+func TestCompleteStreamReasoning(t *testing.T) {
+	events, err := replayStream(t, "reasoning.sse")
+	assert.NoError(t, err)
+	assert.Len(t, events, 4)
+	assert.Equal(t, "用户在打招呼，", events[0].Reasoning)
+	assert.Empty(t, events[0].Content)
+	assert.Equal(t, "你好！", events[2].Content)
+
+	resp := events[3].Response
+	assert.Equal(t, "用户在打招呼，简单回应即可。", resp.Reasoning)
+	assert.Equal(t, "你好！", resp.Content)
+	assert.Equal(t, 12, resp.Usage.ReasoningTokens)
+}
+
+func TestPrepareRequest_RejectMedia(t *testing.T) {
+	p, err := New(map[string]interface{}{"WN_DEEPSEEK_APIKEY": "key"})
+	assert.NoError(t, err)
+	_, err = p.(*Provider).PrepareRequest(llm.CompletionRequest{Messages: []llm.Message{
+		llm.NewUserMessage(llm.ImagePart("image/png", []byte("abc")), llm.TextPart("what is this?")),
+	}}, false)
+	assert.ErrorContains(t, err, "只支持文本输入")
+}
@@ -2,25 +2,21 @@ package deepseek
 
 import (
 	"encoding/json"
-	"strings"
 
 	"github.com/sjzsdu/wn/helper"
 	"github.com/sjzsdu/wn/llm"
+	"github.com/sjzsdu/wn/llm/providers/base"
 	"github.com/sjzsdu/wn/share"
 )
 
+// StreamHandler 解析 DeepSeek 的流式数据，由 base.StreamAccumulator 汇总
 type StreamHandler struct {
-	StreamHandler    llm.StreamHandler
-	ContentBuilder   strings.Builder
-	ArgumentsBuilder strings.Builder
-	CurrentToolCall  *llm.ToolCall
-	ToolCalls        []llm.ToolCall
-	Usage            llm.Usage
+	*base.StreamAccumulator
 }
 
 func NewStreamHandler(handler llm.StreamHandler) StreamHandler {
 	return StreamHandler{
-		StreamHandler: handler,
+		StreamAccumulator: base.NewStreamAccumulator(handler),
 	}
 }
 
@@ -32,88 +28,30 @@ func (h *StreamHandler) AddContent(data []byte) error {
 		}
 		return err
 	}
-	if len(streamResp.Choices) > 0 {
-		choice := streamResp.Choices[0]
 
-		h.StreamHandler(llm.StreamResponse{
-			Content:      choice.Delta.Content,
-			Done:         false,
-			FinishReason: choice.FinishReason,
-		})
-
-		if choice.Delta.Content != "" {
-			h.ContentBuilder.WriteString(choice.Delta.Content)
-		}
-
-		if len(choice.Delta.ToolCalls) > 0 {
-			for _, tc := range choice.Delta.ToolCalls {
-				if tc.ID != "" {
-					// 新的工具调用开始
-					if h.CurrentToolCall == nil || h.CurrentToolCall.ID != tc.ID {
-						if h.CurrentToolCall != nil {
-							// 完成前一个工具调用
-							h.CurrentToolCall.Arguments = helper.StringToMap(h.ArgumentsBuilder.String())
-							h.ToolCalls = append(h.ToolCalls, *h.CurrentToolCall)
-							// 发送工具调用完成通知
-							h.StreamHandler(llm.StreamResponse{
-								Content: "",
-								Done:    false,
-							})
-						}
-						// 创建新的工具调用
-						h.CurrentToolCall = &llm.ToolCall{
-							ID:       tc.ID,
-							Type:     tc.Type,
-							Function: tc.Function.Name,
-						}
-						h.ArgumentsBuilder.Reset()
-						// 发送新工具调用开始通知
-						h.StreamHandler(llm.StreamResponse{
-							Content: "",
-							Done:    false,
-						})
-					}
-				}
-				// 累积参数字符串
-				if tc.Function.Arguments != "" {
-					h.ArgumentsBuilder.WriteString(tc.Function.Arguments)
-				}
-			}
-		}
-
-		// 处理结束原因
-		if choice.FinishReason != "" {
-			// 处理最后一个工具调用
-			if choice.FinishReason == "tool_calls" && h.CurrentToolCall != nil {
-				h.CurrentToolCall.Arguments = helper.StringToMap(h.ArgumentsBuilder.String())
-				h.ToolCalls = append(h.ToolCalls, *h.CurrentToolCall)
-				// 发送最后一个工具调用完成通知
-				h.StreamHandler(llm.StreamResponse{
-					Content: "",
-					Done:    false,
-				})
-			}
-
-			if streamResp.Usage.TotalTokens > 0 {
-				h.Usage = llm.Usage{
-					PromptTokens:     streamResp.Usage.PromptTokens,
-					CompletionTokens: streamResp.Usage.CompletionTokens,
-					TotalTokens:      streamResp.Usage.TotalTokens,
-				}
-			}
+	// 开启 include_usage 后用量在最后一个事件中返回
+	h.SetUsage(llm.Usage{
+		PromptTokens:     streamResp.Usage.PromptTokens,
+		CompletionTokens: streamResp.Usage.CompletionTokens,
+		TotalTokens:      streamResp.Usage.TotalTokens,
+		ReasoningTokens:  streamResp.Usage.CompletionTokensDetails.ReasoningTokens,
+	})
+	if len(streamResp.Choices) == 0 {
+		return nil
+	}
 
-			h.StreamHandler(llm.StreamResponse{
-				Content:      h.ContentBuilder.String(),
-				FinishReason: choice.FinishReason,
-				Done:         true,
-				Response: &llm.CompletionResponse{
-					Content:      h.ContentBuilder.String(),
-					FinishReason: choice.FinishReason,
-					ToolCalls:    h.ToolCalls,
-					Usage:        h.Usage,
-				},
-			})
-		}
+	choice := streamResp.Choices[0]
+	h.AddReasoning(choice.Delta.ReasoningContent)
+	h.AddText(choice.Delta.Content)
+	for _, tc := range choice.Delta.ToolCalls {
+		h.AddToolCall(base.ToolCallDelta{
+			Index:     tc.Index,
+			ID:        tc.ID,
+			Type:      tc.Type,
+			Name:      tc.Function.Name,
+			Arguments: tc.Function.Arguments,
+		})
 	}
+	h.SetFinishReason(choice.FinishReason)
 	return nil
 }
@@ -3,15 +3,15 @@ package deepseek
 type DeepseekRequest struct {
 	Messages         []Message      `json:"messages"`
 	Model            string         `json:"model"`
-	FrequencyPenalty float64        `json:"frequency_penalty,omitempty"`
+	FrequencyPenalty *float64       `json:"frequency_penalty,omitempty"`
 	MaxTokens        int            `json:"max_tokens,omitempty"`
-	PresencePenalty  float64        `json:"presence_penalty,omitempty"`
+	PresencePenalty  *float64       `json:"presence_penalty,omitempty"`
 	ResponseFormat   ResponseFormat `json:"response_format,omitempty"`
 	Stop             interface{}    `json:"stop,omitempty"`
 	Stream           bool           `json:"stream,omitempty"`
 	StreamOptions    interface{}    `json:"stream_options,omitempty"`
-	Temperature      float64        `json:"temperature,omitempty"`
-	TopP             float64        `json:"top_p,omitempty"`
+	Temperature      *float64       `json:"temperature,omitempty"`
+	TopP             *float64       `json:"top_p,omitempty"`
 	Tools            []Tool         `json:"tools,omitempty"`
 	ToolChoice       interface{}    `json:"tool_choice,omitempty"`
 	LogitBias        map[string]int `json:"logit_bias,omitempty"`
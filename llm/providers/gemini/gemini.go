@@ -0,0 +1,448 @@
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/sjzsdu/wn/helper"
+	"github.com/sjzsdu/wn/llm"
+	"github.com/sjzsdu/wn/llm/providers/base"
+	"github.com/sjzsdu/wn/share"
+)
+
+const (
+	name            = "gemini"
+	baseAPIEndpoint = "https://generativelanguage.googleapis.com/v1beta"
+	defaultModel    = "gemini-2.0-flash"
+	modelsPath      = "/models"
+)
+
+type Provider struct {
+	base.Provider
+	StreamHandler StreamHandler
+}
+
+func New(options map[string]interface{}) (llm.Provider, error) {
+	apiKey, ok := options["WN_GEMINI_APIKEY"].(string)
+	if !ok || apiKey == "" {
+		return nil, fmt.Errorf("gemini: WN_GEMINI_APIKEY is required")
+	}
+
+	config := base.RequestConfig{
+		Headers: map[string]string{
+			"Content-Type":   "application/json",
+			"x-goog-api-key": apiKey,
+		},
+		Timeout: 60,
+		RetryConfig: &base.RetryConfig{
+			MaxRetries:  3,
+			RetryDelay:  1,
+			RetryPolicy: base.RetryPolicyLinear,
+		},
+		RateLimit: base.RateLimitFromOptions(name, options),
+	}
+
+	p := &Provider{
+		Provider: *base.NewProvider(
+			name,
+			apiKey,
+			baseAPIEndpoint,
+			defaultModel,
+			config,
+		),
+	}
+
+	if endpoint, ok := options["WN_GEMINI_ENDPOINT"].(string); ok && endpoint != "" {
+		p.APIEndpoint = strings.TrimRight(endpoint, "/")
+	}
+	if model, ok := options["WN_GEMINI_MODEL"].(string); ok && model != "" {
+		p.Model = model
+	}
+
+	return p, nil
+}
+
+func (p *Provider) PrepareRequest(req llm.CompletionRequest) ([]byte, error) {
+	request := &GenerateContentRequest{}
+	request.SystemInstruction, request.Contents = p.handleMessages(req.Messages)
+
+	if len(req.Tools) > 0 {
+		request.Tools = []Tool{{FunctionDeclarations: p.handleTools(req.Tools)}}
+		request.ToolConfig = toolConfig(req.ToolChoice)
+	}
+
+	config := GenerationConfig{
+		MaxOutputTokens:  req.MaxTokens,
+		Temperature:      req.Temperature,
+		TopP:             req.TopP,
+		StopSequences:    req.Stop,
+		Seed:             req.Seed,
+		PresencePenalty:  req.PresencePenalty,
+		FrequencyPenalty: req.FrequencyPenalty,
+	}
+	if req.JSONSchema != nil {
+		config.ResponseMimeType = "application/json"
+		config.ResponseJSONSchema = req.JSONSchema.Schema
+	} else if req.ResponseFormat == "json_object" || req.ResponseFormat == "json" {
+		config.ResponseMimeType = "application/json"
+	}
+	if !reflect.DeepEqual(config, GenerationConfig{}) {
+		request.GenerationConfig = &config
+	}
+
+	if share.GetDebug() {
+		helper.PrintWithLabel("[DEBUG] Request Body", request)
+	}
+
+	return json.Marshal(request)
+}
+
+// modelPath 返回模型接口路径，如 /models/gemini-2.0-flash:generateContent
+func (p *Provider) modelPath(req llm.CompletionRequest, method string) string {
+	model := p.RequestModel(req.Model)
+	return "/models/" + strings.TrimPrefix(model, "models/") + ":" + method
+}
+
+func (p *Provider) Complete(ctx context.Context, req llm.CompletionRequest) (*llm.CompletionResponse, error) {
+	jsonBody, err := p.PrepareRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	resp, err := p.DoPost(ctx, p.modelPath(req, "generateContent"), jsonBody)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode() != 200 {
+		return nil, base.NewStatusError(resp)
+	}
+	if share.GetDebug() {
+		helper.PrintWithLabel("[DEBUG] Raw Response", resp.String())
+	}
+
+	return p.ParseResponse(resp.Body())
+}
+
+func (p *Provider) CompleteStream(ctx context.Context, req llm.CompletionRequest, handler llm.StreamHandler) error {
+	p.StreamHandler = NewStreamHandler(handler)
+	jsonBody, err := p.PrepareRequest(req)
+	if err != nil {
+		return fmt.Errorf("marshal request: %w", err)
+	}
+
+	resp, err := p.DoStream(ctx, p.modelPath(req, "streamGenerateContent")+"?alt=sse", jsonBody)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != 200 {
+		return base.NewStatusError(resp)
+	}
+
+	return p.HandleStreamResponse(resp, p)
+}
+
+// HandleStream 处理流式响应中的一行
+func (p *Provider) HandleStream(bytes []byte) error {
+	line := strings.TrimSpace(string(bytes))
+	if !strings.HasPrefix(line, "data:") {
+		return nil
+	}
+	data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+	if data == "" {
+		return nil
+	}
+	return p.StreamHandler.AddContent([]byte(data))
+}
+
+// FinishStream 流结束时发送最终响应
+func (p *Provider) FinishStream() error {
+	return p.StreamHandler.Finish()
+}
+
+func (p *Provider) ParseResponse(body []byte) (*llm.CompletionResponse, error) {
+	var geminiResp GenerateContentResponse
+	if err := json.Unmarshal(body, &geminiResp); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w, 原始响应: %s", err, string(body))
+	}
+	if len(geminiResp.Candidates) == 0 {
+		return nil, fmt.Errorf("no candidates in response")
+	}
+
+	candidate := geminiResp.Candidates[0]
+	resp := &llm.CompletionResponse{
+		FinishReason: convertFinishReason(candidate.FinishReason),
+		Usage:        convertUsage(geminiResp.UsageMetadata),
+	}
+	for _, part := range candidate.Content.Parts {
+		if part.Thought {
+			resp.Reasoning += part.Text
+			continue
+		}
+		resp.Content += part.Text
+		if part.FunctionCall != nil {
+			resp.ToolCalls = append(resp.ToolCalls, convertFunctionCall(*part.FunctionCall, len(resp.ToolCalls)))
+		}
+	}
+	if len(resp.ToolCalls) > 0 {
+		resp.FinishReason = "tool_calls"
+	}
+	return resp, nil
+}
+
+// handleMessages 转换消息
+// system 消息合并为 systemInstruction，assistant 对应 model，tool 消息转换为 functionResponse
+// Gemini 要求同一角色的相邻消息合并为一条，多个工具结果需要放在同一条消息中
+func (p *Provider) handleMessages(messages []llm.Message) (*Content, []Content) {
+	var system *Content
+	var contents []Content
+
+	for i, m := range messages {
+		var role string
+		var parts []Part
+
+		switch m.Role {
+		case "system":
+			if system == nil {
+				system = &Content{}
+			}
+			system.Parts = append(system.Parts, Part{Text: m.Content})
+			continue
+		case "assistant":
+			role = "model"
+			if m.Content != "" {
+				parts = append(parts, Part{Text: m.Content})
+			}
+			for _, tc := range m.ToolCalls {
+				parts = append(parts, Part{FunctionCall: &FunctionCall{Name: tc.Function, Args: tc.Arguments}})
+			}
+		case "tool":
+			role = "user"
+			response := map[string]interface{}{"content": m.Content}
+			if m.IsError {
+				response = map[string]interface{}{"error": m.Content}
+			}
+			parts = append(parts, Part{FunctionResponse: &FunctionResponse{
+				Name:     toolName(messages[:i], m),
+				Response: response,
+			}})
+			parts = append(parts, imageParts(m.Images())...)
+		default:
+			role = "user"
+			if m.HasMedia() {
+				parts = append(parts, mediaParts(m.Parts)...)
+				break
+			}
+			parts = append(parts, Part{Text: m.Content})
+		}
+
+		if len(parts) == 0 {
+			continue
+		}
+		if n := len(contents); n > 0 && contents[n-1].Role == role {
+			contents[n-1].Parts = append(contents[n-1].Parts, parts...)
+			continue
+		}
+		contents = append(contents, Content{Role: role, Parts: parts})
+	}
+	return system, contents
+}
+
+// toolConfig 转换 tool_choice，required 对应 ANY，其他值为只允许调用的函数名称
+func toolConfig(choice string) *ToolConfig {
+	var config FunctionCallingConfig
+	switch choice {
+	case "":
+		return nil
+	case llm.ToolChoiceAuto:
+		config.Mode = "AUTO"
+	case llm.ToolChoiceNone:
+		config.Mode = "NONE"
+	case llm.ToolChoiceRequired:
+		config.Mode = "ANY"
+	default:
+		config.Mode = "ANY"
+		config.AllowedFunctionNames = []string{choice}
+	}
+	return &ToolConfig{FunctionCallingConfig: config}
+}
+
+// toolName 从之前最近的工具调用中查找 tool 消息对应的函数名
+func toolName(history []llm.Message, m llm.Message) string {
+	if m.Name != "" {
+		return m.Name
+	}
+	for i := len(history) - 1; i >= 0; i-- {
+		for _, tc := range history[i].ToolCalls {
+			if tc.ID == m.ToolCallId {
+				return tc.Function
+			}
+		}
+	}
+	return m.ToolCallId
+}
+
+func imageParts(images []llm.ContentPart) []Part {
+	parts := make([]Part, len(images))
+	for i, image := range images {
+		parts[i] = Part{InlineData: &InlineData{MimeType: image.MIMEType, Data: image.Data}}
+	}
+	return parts
+}
+
+// mediaParts 按顺序转换用户消息的内容片段，图片和文件内联发送，远程图片以 fileData 引用
+func mediaParts(contentParts []llm.ContentPart) []Part {
+	parts := make([]Part, 0, len(contentParts))
+	for _, part := range contentParts {
+		switch {
+		case part.Type == llm.ContentTypeText:
+			parts = append(parts, Part{Text: part.Text})
+		case part.URL != "":
+			parts = append(parts, Part{FileData: &FileData{MimeType: part.MIMEType, FileURI: part.URL}})
+		default:
+			parts = append(parts, Part{InlineData: &InlineData{MimeType: part.MIMEType, Data: part.Data}})
+		}
+	}
+	return parts
+}
+
+// handleTools 把 MCP 工具转换为函数声明
+func (p *Provider) handleTools(tools []mcp.Tool) []FunctionDeclaration {
+	declarations := make([]FunctionDeclaration, 0, len(tools))
+	for _, t := range tools {
+		declaration := FunctionDeclaration{
+			Name:        t.Name,
+			Description: t.Description,
+		}
+		// 没有参数的函数不能声明空的 properties
+		if len(t.InputSchema.Properties) > 0 {
+			parameters := map[string]interface{}{
+				"type":       "object",
+				"properties": convertProperties(t.InputSchema.Properties),
+			}
+			if len(t.InputSchema.Required) > 0 {
+				parameters["required"] = t.InputSchema.Required
+			}
+			declaration.Parameters = parameters
+		}
+		declarations = append(declarations, declaration)
+	}
+	return declarations
+}
+
+// schemaFields Gemini 函数声明支持的 OpenAPI schema 字段，其他字段（如 $schema、additionalProperties）会导致请求失败
+var schemaFields = map[string]bool{
+	"type":        true,
+	"format":      true,
+	"description": true,
+	"nullable":    true,
+	"enum":        true,
+	"items":       true,
+	"properties":  true,
+	"required":    true,
+	"minItems":    true,
+	"maxItems":    true,
+	"minimum":     true,
+	"maximum":     true,
+}
+
+// convertProperties 转换 properties 中每个参数的 schema
+func convertProperties(properties map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(properties))
+	for name, schema := range properties {
+		result[name] = filterSchema(toMap(schema))
+	}
+	return result
+}
+
+// filterSchema 递归去掉 schema 中 Gemini 不支持的字段
+func filterSchema(schema map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(schema))
+	for key, value := range schema {
+		if !schemaFields[key] {
+			continue
+		}
+		switch key {
+		case "properties":
+			value = convertProperties(toMap(value))
+		case "items":
+			value = filterSchema(toMap(value))
+		}
+		result[key] = value
+	}
+	return result
+}
+
+// toMap 把任意 JSON 结构转换为 map，无法转换时返回空 map
+func toMap(value interface{}) map[string]interface{} {
+	if m, ok := value.(map[string]interface{}); ok {
+		return m
+	}
+	result := make(map[string]interface{})
+	if data, err := json.Marshal(value); err == nil {
+		json.Unmarshal(data, &result)
+	}
+	return result
+}
+
+func convertFunctionCall(call FunctionCall, index int) llm.ToolCall {
+	return llm.ToolCall{
+		ID:        fmt.Sprintf("call_%d", index),
+		Type:      "function",
+		Function:  call.Name,
+		Arguments: call.Args,
+	}
+}
+
+func convertFinishReason(reason string) string {
+	switch reason {
+	case "STOP":
+		return "stop"
+	case "MAX_TOKENS":
+		return "length"
+	default:
+		return strings.ToLower(reason)
+	}
+}
+
+func convertUsage(usage UsageMetadata) llm.Usage {
+	return llm.Usage{
+		PromptTokens:     usage.PromptTokenCount,
+		CompletionTokens: usage.CandidatesTokenCount,
+		TotalTokens:      usage.TotalTokenCount,
+		ReasoningTokens:  usage.ThoughtsTokenCount,
+	}
+}
+
+// AvailableModels 返回支持 generateContent 的模型
+func (p *Provider) AvailableModels() []string {
+	resp, err := p.DoGet(context.Background(), modelsPath, nil)
+	if err != nil || resp.StatusCode() != 200 {
+		if share.GetDebug() {
+			helper.PrintWithLabel("[DEBUG] Get Models Error:", err)
+		}
+		return []string{}
+	}
+
+	var response ModelsResponse
+	if err := json.Unmarshal(resp.Body(), &response); err != nil {
+		return []string{}
+	}
+	models := make([]string, 0, len(response.Models))
+	for _, model := range response.Models {
+		for _, method := range model.SupportedGenerationMethods {
+			if method == "generateContent" {
+				models = append(models, strings.TrimPrefix(model.Name, "models/"))
+				break
+			}
+		}
+	}
+	return models
+}
+
+func init() {
+	llm.Register(name, New)
+}
@@ -0,0 +1,199 @@
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/sjzsdu/wn/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestProvider(t *testing.T, handler http.HandlerFunc) llm.Provider {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	p, err := New(map[string]interface{}{
+		"WN_GEMINI_APIKEY":   "test-key",
+		"WN_GEMINI_ENDPOINT": server.URL,
+	})
+	assert.NoError(t, err)
+	return p
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(map[string]interface{}{})
+	assert.Error(t, err)
+
+	p, err := New(map[string]interface{}{"WN_GEMINI_APIKEY": "key", "WN_GEMINI_MODEL": "gemini-1.5-pro"})
+	assert.NoError(t, err)
+	assert.Equal(t, "gemini", p.GetName())
+	assert.Equal(t, "gemini-1.5-pro", p.GetModel())
+}
+
+func TestComplete(t *testing.T) {
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/models/gemini-2.0-flash:generateContent", r.URL.Path)
+		assert.Equal(t, "test-key", r.Header.Get("x-goog-api-key"))
+
+		var req GenerateContentRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "be brief", req.SystemInstruction.Parts[0].Text)
+		assert.Equal(t, "read_file", req.Tools[0].FunctionDeclarations[0].Name)
+
+		fmt.Fprint(w, `{
+			"candidates": [{
+				"content": {"role": "model", "parts": [{"functionCall": {"name": "read_file", "args": {"path": "a.go"}}}]},
+				"finishReason": "STOP"
+			}],
+			"usageMetadata": {"promptTokenCount": 10, "candidatesTokenCount": 5, "totalTokenCount": 15}
+		}`)
+	})
+
+	resp, err := p.Complete(context.Background(), llm.CompletionRequest{
+		Messages: []llm.Message{
+			{Role: "system", Content: "be brief"},
+			{Role: "user", Content: "read a.go"},
+		},
+		Tools: []mcp.Tool{mcp.NewTool("read_file", mcp.WithString("path", mcp.Required()))},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "tool_calls", resp.FinishReason)
+	assert.Equal(t, []llm.ToolCall{{
+		ID:        "call_0",
+		Type:      "function",
+		Function:  "read_file",
+		Arguments: map[string]interface{}{"path": "a.go"},
+	}}, resp.ToolCalls)
+	assert.Equal(t, llm.Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15}, resp.Usage)
+}
+
+func TestCompleteStream(t *testing.T) {
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/models/gemini-2.0-flash:streamGenerateContent", r.URL.Path)
+		assert.Equal(t, "sse", r.URL.Query().Get("alt"))
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"candidates\":[{\"content\":{\"role\":\"model\",\"parts\":[{\"text\":\"hel\"}]}}]}\n\n")
+		fmt.Fprint(w, "data: {\"candidates\":[{\"content\":{\"role\":\"model\",\"parts\":[{\"text\":\"lo\"}]},\"finishReason\":\"STOP\"}],\"usageMetadata\":{\"promptTokenCount\":3,\"candidatesTokenCount\":2,\"totalTokenCount\":5}}\n\n")
+	})
+
+	var chunks []string
+	var final *llm.CompletionResponse
+	err := p.CompleteStream(context.Background(), llm.CompletionRequest{
+		Messages: []llm.Message{{Role: "user", Content: "hi"}},
+	}, func(resp llm.StreamResponse) {
+		if resp.Done {
+			final = resp.Response
+			return
+		}
+		chunks = append(chunks, resp.Content)
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"hel", "lo"}, chunks)
+	assert.Equal(t, "hello", final.Content)
+	assert.Equal(t, "stop", final.FinishReason)
+	assert.Equal(t, 5, final.Usage.TotalTokens)
+}
+
+func TestAvailableModels(t *testing.T) {
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"models": [
+			{"name": "models/gemini-2.0-flash", "supportedGenerationMethods": ["generateContent", "countTokens"]},
+			{"name": "models/text-embedding-004", "supportedGenerationMethods": ["embedContent"]}
+		]}`)
+	})
+	assert.Equal(t, []string{"gemini-2.0-flash"}, p.AvailableModels())
+}
+
+func TestHandleMessages(t *testing.T) {
+	p := &Provider{}
+	system, contents := p.handleMessages([]llm.Message{
+		{Role: "system", Content: "sys"},
+		{Role: "user", Content: "hi"},
+		{Role: "assistant", ToolCalls: []llm.ToolCall{
+			{ID: "call_0", Function: "a", Arguments: map[string]interface{}{"x": 1.0}},
+			{ID: "call_1", Function: "b"},
+		}},
+		{Role: "tool", ToolCallId: "call_0", Content: "ok"},
+		{Role: "tool", ToolCallId: "call_1", Content: "boom", IsError: true},
+	})
+
+	assert.Equal(t, &Content{Parts: []Part{{Text: "sys"}}}, system)
+	assert.Len(t, contents, 3)
+	assert.Equal(t, "model", contents[1].Role)
+	assert.Equal(t, &FunctionCall{Name: "a", Args: map[string]interface{}{"x": 1.0}}, contents[1].Parts[0].FunctionCall)
+
+	// 相邻的工具结果合并为一条 user 消息
+	assert.Equal(t, "user", contents[2].Role)
+	assert.Equal(t, []Part{
+		{FunctionResponse: &FunctionResponse{Name: "a", Response: map[string]interface{}{"content": "ok"}}},
+		{FunctionResponse: &FunctionResponse{Name: "b", Response: map[string]interface{}{"error": "boom"}}},
+	}, contents[2].Parts)
+}
+
+func TestHandleTools(t *testing.T) {
+	p := &Provider{}
+	tool := mcp.NewTool("search",
+		mcp.WithString("query", mcp.Required(), mcp.Description("keywords")),
+		mcp.WithArray("paths", mcp.Items(map[string]interface{}{"type": "string", "additionalProperties": false})),
+	)
+	tool.InputSchema.Properties["query"].(map[string]interface{})["$schema"] = "http://json-schema.org/draft-07/schema#"
+
+	declarations := p.handleTools([]mcp.Tool{tool, mcp.NewTool("now")})
+	assert.Equal(t, map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"query": map[string]interface{}{"type": "string", "description": "keywords"},
+			"paths": map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+		},
+		"required": []string{"query"},
+	}, declarations[0].Parameters)
+	assert.Nil(t, declarations[1].Parameters)
+}
+
+func TestPrepareRequest_SamplingParams(t *testing.T) {
+	p := &Provider{}
+	temperature, seed := 0.0, 3
+
+	body, err := p.PrepareRequest(llm.CompletionRequest{
+		Messages: []llm.Message{{Role: "user", Content: "hi"}},
+		Tools:    []mcp.Tool{mcp.NewTool("now")},
+		SamplingParams: llm.SamplingParams{
+			Temperature: &temperature,
+			Seed:        &seed,
+			ToolChoice:  "now",
+		},
+	})
+	assert.NoError(t, err)
+
+	var request GenerateContentRequest
+	assert.NoError(t, json.Unmarshal(body, &request))
+	assert.Equal(t, 0.0, *request.GenerationConfig.Temperature)
+	assert.Equal(t, 3, *request.GenerationConfig.Seed)
+	assert.Equal(t, FunctionCallingConfig{Mode: "ANY", AllowedFunctionNames: []string{"now"}}, request.ToolConfig.FunctionCallingConfig)
+
+	body, err = p.PrepareRequest(llm.CompletionRequest{Messages: []llm.Message{{Role: "user", Content: "hi"}}})
+	assert.NoError(t, err)
+	assert.NotContains(t, string(body), "generationConfig")
+}
+
+func TestPrepareRequest_JSONSchema(t *testing.T) {
+	p := &Provider{}
+	schema := map[string]interface{}{"type": "object"}
+
+	body, err := p.PrepareRequest(llm.CompletionRequest{
+		Messages:   []llm.Message{{Role: "user", Content: "hi"}},
+		JSONSchema: &llm.JSONSchema{Name: "result", Schema: schema},
+	})
+	assert.NoError(t, err)
+
+	var request GenerateContentRequest
+	assert.NoError(t, json.Unmarshal(body, &request))
+	assert.Equal(t, "application/json", request.GenerationConfig.ResponseMimeType)
+	assert.Equal(t, schema, request.GenerationConfig.ResponseJSONSchema)
+}
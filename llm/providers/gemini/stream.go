@@ -0,0 +1,59 @@
+package gemini
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/sjzsdu/wn/llm"
+	"github.com/sjzsdu/wn/llm/providers/base"
+)
+
+// StreamHandler 处理 streamGenerateContent 返回的 SSE 数据，每个事件是一个完整的 GenerateContentResponse，由 base.StreamAccumulator 汇总
+type StreamHandler struct {
+	*base.StreamAccumulator
+	hasToolCalls bool
+}
+
+func NewStreamHandler(handler llm.StreamHandler) StreamHandler {
+	return StreamHandler{
+		StreamAccumulator: base.NewStreamAccumulator(handler),
+	}
+}
+
+func (h *StreamHandler) AddContent(data []byte) error {
+	var chunk GenerateContentResponse
+	if err := json.Unmarshal(data, &chunk); err != nil {
+		return fmt.Errorf("unmarshal stream response: %w", err)
+	}
+	h.SetUsage(convertUsage(chunk.UsageMetadata))
+	if len(chunk.Candidates) == 0 {
+		return nil
+	}
+
+	candidate := chunk.Candidates[0]
+	for _, part := range candidate.Content.Parts {
+		if part.Thought {
+			h.AddReasoning(part.Text)
+			continue
+		}
+		h.AddText(part.Text)
+		// 函数调用一次返回完整参数
+		if part.FunctionCall != nil {
+			h.AppendToolCall(llm.ToolCall{
+				Type:      "function",
+				Function:  part.FunctionCall.Name,
+				Arguments: part.FunctionCall.Args,
+			})
+			h.hasToolCalls = true
+		}
+	}
+
+	if candidate.FinishReason != "" {
+		reason := convertFinishReason(candidate.FinishReason)
+		if h.hasToolCalls {
+			reason = "tool_calls"
+		}
+		h.SetFinishReason(reason)
+	}
+	return nil
+}
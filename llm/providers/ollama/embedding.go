@@ -0,0 +1,71 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/sjzsdu/wn/llm"
+	"github.com/sjzsdu/wn/llm/providers/base"
+)
+
+const (
+	embedPath             = "/api/embed"
+	defaultEmbeddingModel = "nomic-embed-text"
+	// embeddingBatchSize 本地推理时分批发送，避免单个请求耗时过长
+	embeddingBatchSize = 64
+)
+
+type EmbedRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type EmbedResponse struct {
+	Embeddings      [][]float32 `json:"embeddings"`
+	PromptEvalCount int         `json:"prompt_eval_count"`
+	Error           string      `json:"error,omitempty"`
+}
+
+// Embed 实现 llm.Embedder
+func (p *Provider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return p.Embeddings.Run(ctx, texts, embeddingBatchSize, p.embed)
+}
+
+func (p *Provider) embed(ctx context.Context, batch []string) ([][]float32, llm.Usage, error) {
+	jsonBody, err := json.Marshal(EmbedRequest{Model: p.EmbeddingModel, Input: batch})
+	if err != nil {
+		return nil, llm.Usage{}, fmt.Errorf("marshal request: %w", err)
+	}
+
+	resp, err := p.DoPost(ctx, embedPath, jsonBody)
+	if err != nil {
+		return nil, llm.Usage{}, err
+	}
+	if resp.StatusCode() != 200 {
+		return nil, llm.Usage{}, base.NewStatusError(resp)
+	}
+
+	var embedResp EmbedResponse
+	if err := json.Unmarshal(resp.Body(), &embedResp); err != nil {
+		return nil, llm.Usage{}, fmt.Errorf("解析响应失败: %w", err)
+	}
+	if embedResp.Error != "" {
+		return nil, llm.Usage{}, fmt.Errorf("ollama: %s", embedResp.Error)
+	}
+	usage := llm.Usage{
+		PromptTokens: embedResp.PromptEvalCount,
+		TotalTokens:  embedResp.PromptEvalCount,
+	}
+	return embedResp.Embeddings, usage, nil
+}
+
+// Dimensions 实现 llm.Embedder，本地模型的维度在第一次请求后才能确定
+func (p *Provider) Dimensions() int {
+	return p.Embeddings.Dimensions()
+}
+
+// EmbeddingUsage 实现 llm.Embedder
+func (p *Provider) EmbeddingUsage() llm.Usage {
+	return p.Embeddings.EmbeddingUsage()
+}
@@ -0,0 +1,339 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/sjzsdu/wn/helper"
+	"github.com/sjzsdu/wn/llm"
+	"github.com/sjzsdu/wn/llm/providers/base"
+	"github.com/sjzsdu/wn/llm/providers/openai"
+	"github.com/sjzsdu/wn/share"
+)
+
+const (
+	name            = "ollama"
+	defaultEndpoint = "http://localhost:11434"
+	defaultModel    = "llama3.2"
+	chatPath        = "/api/chat"
+	tagsPath        = "/api/tags"
+	// openAIPath OpenAI 兼容接口的前缀，llama.cpp、vLLM 等本地服务同样提供该接口
+	openAIPath = "/v1"
+
+	// WN_OLLAMA_API 的取值
+	APIOllama = "ollama"
+	APIOpenAI = "openai"
+)
+
+// Provider 通过 Ollama 原生的 /api/chat 接口访问本地模型
+type Provider struct {
+	base.Provider
+	StreamHandler StreamHandler
+	// EmbeddingModel 向量化使用的模型，默认 nomic-embed-text
+	EmbeddingModel string
+	Embeddings     base.EmbeddingBatcher
+}
+
+// OpenAIProvider 通过 OpenAI 兼容接口访问本地模型服务
+type OpenAIProvider struct {
+	*openai.Provider
+}
+
+// New 创建本地模型提供商，不需要 API Key
+// WN_OLLAMA_API 为 openai 时使用 OpenAI 兼容接口，否则使用 Ollama 原生接口
+func New(options map[string]interface{}) (llm.Provider, error) {
+	endpoint := defaultEndpoint
+	if value, ok := options["WN_OLLAMA_ENDPOINT"].(string); ok && value != "" {
+		endpoint = strings.TrimRight(value, "/")
+	}
+	model := defaultModel
+	if value, ok := options["WN_OLLAMA_MODEL"].(string); ok && value != "" {
+		model = value
+	}
+	embeddingModel := defaultEmbeddingModel
+	if value, ok := options["WN_OLLAMA_EMBEDDING_MODEL"].(string); ok && value != "" {
+		embeddingModel = value
+	}
+	apiKey, _ := options["WN_OLLAMA_APIKEY"].(string)
+
+	config := base.RequestConfig{
+		Headers: map[string]string{
+			"Content-Type": "application/json",
+		},
+		// 本地模型加载和推理都比较慢
+		Timeout:   300,
+		RateLimit: base.RateLimitFromOptions(name, options),
+	}
+	if apiKey != "" {
+		config.Headers["Authorization"] = "Bearer " + apiKey
+	}
+
+	api, _ := options["WN_OLLAMA_API"].(string)
+	switch api {
+	case "", APIOllama:
+		return &Provider{
+			Provider:       *base.NewProvider(name, apiKey, endpoint, model, config),
+			EmbeddingModel: embeddingModel,
+		}, nil
+	case APIOpenAI:
+		// 兼容直接配置成 http://host/v1 的地址
+		endpoint = strings.TrimSuffix(endpoint, openAIPath) + openAIPath
+		return &OpenAIProvider{
+			Provider: &openai.Provider{
+				Provider:       *base.NewProvider(name, apiKey, endpoint, model, config),
+				EmbeddingModel: embeddingModel,
+			},
+		}, nil
+	default:
+		return nil, fmt.Errorf("ollama: unsupported WN_OLLAMA_API %q, expected %s or %s", api, APIOllama, APIOpenAI)
+	}
+}
+
+func (p *Provider) PrepareRequest(req llm.CompletionRequest, stream bool) ([]byte, error) {
+	// images 字段只接受 base64 编码的图片
+	for _, m := range req.Messages {
+		for _, part := range m.Parts {
+			if part.Type == llm.ContentTypeFile || part.URL != "" {
+				return nil, fmt.Errorf("%s: 只支持发送本地图片，无法处理 %s", name, part)
+			}
+		}
+	}
+
+	request := &ChatRequest{
+		Model:    p.RequestModel(req.Model),
+		Stream:   stream,
+		Messages: p.handleMessages(req.Messages),
+	}
+	options, err := p.handleOptions(req)
+	if err != nil {
+		return nil, err
+	}
+	request.Options = options
+	if req.JSONSchema != nil {
+		request.Format = req.JSONSchema.Schema
+	} else if req.ResponseFormat == "json_object" || req.ResponseFormat == "json" {
+		request.Format = "json"
+	}
+	// none 表示不允许调用工具，此时不发送工具列表
+	if req.Tools != nil && req.ToolChoice != llm.ToolChoiceNone {
+		request.Tools = make([]Tool, 0, len(req.Tools))
+		for _, t := range req.Tools {
+			parameters := map[string]interface{}{
+				"type":       "object",
+				"properties": t.InputSchema.Properties,
+			}
+			if len(t.InputSchema.Required) > 0 {
+				parameters["required"] = t.InputSchema.Required
+			}
+			request.Tools = append(request.Tools, Tool{
+				Type: "function",
+				Function: Function{
+					Name:        t.Name,
+					Description: t.Description,
+					Parameters:  parameters,
+				},
+			})
+		}
+	}
+
+	if share.GetDebug() {
+		helper.PrintWithLabel("[DEBUG] Request Body", request)
+	}
+
+	return json.Marshal(request)
+}
+
+// handleOptions 把 max_tokens 和采样参数转换为 Ollama 的 options
+// Ollama 不支持指定必须调用的工具，tool_choice 只接受 auto 和 none
+func (p *Provider) handleOptions(req llm.CompletionRequest) (map[string]interface{}, error) {
+	if req.ToolChoice != "" && req.ToolChoice != llm.ToolChoiceAuto && req.ToolChoice != llm.ToolChoiceNone {
+		return nil, fmt.Errorf("%s: unsupported tool_choice %q", name, req.ToolChoice)
+	}
+
+	options := make(map[string]interface{})
+	if req.MaxTokens > 0 {
+		options["num_predict"] = req.MaxTokens
+	}
+	if req.Temperature != nil {
+		options["temperature"] = *req.Temperature
+	}
+	if req.TopP != nil {
+		options["top_p"] = *req.TopP
+	}
+	if len(req.Stop) > 0 {
+		options["stop"] = req.Stop
+	}
+	if req.Seed != nil {
+		options["seed"] = *req.Seed
+	}
+	if req.PresencePenalty != nil {
+		options["presence_penalty"] = *req.PresencePenalty
+	}
+	if req.FrequencyPenalty != nil {
+		options["frequency_penalty"] = *req.FrequencyPenalty
+	}
+	if len(options) == 0 {
+		return nil, nil
+	}
+	return options, nil
+}
+
+// handleMessages 转换消息，图片放在 images 中，用户消息的内容只保留文本片段
+func (p *Provider) handleMessages(messages []llm.Message) []Message {
+	result := make([]Message, len(messages))
+	for i, m := range messages {
+		msg := Message{
+			Role:    m.Role,
+			Content: m.TextContent(),
+		}
+		if m.Role != "tool" && m.HasMedia() {
+			var texts []string
+			for _, part := range m.Parts {
+				if part.Type == llm.ContentTypeText {
+					texts = append(texts, part.Text)
+				}
+			}
+			msg.Content = strings.Join(texts, "\n")
+		}
+		for _, image := range m.Images() {
+			msg.Images = append(msg.Images, image.Data)
+		}
+		for _, tc := range m.ToolCalls {
+			msg.ToolCalls = append(msg.ToolCalls, ToolCall{
+				Function: CallFunction{
+					Name:      tc.Function,
+					Arguments: tc.Arguments,
+				},
+			})
+		}
+		result[i] = msg
+	}
+	return result
+}
+
+func (p *Provider) Complete(ctx context.Context, req llm.CompletionRequest) (*llm.CompletionResponse, error) {
+	jsonBody, err := p.PrepareRequest(req, false)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	resp, err := p.DoPost(ctx, chatPath, jsonBody)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode() != 200 {
+		return nil, base.NewStatusError(resp)
+	}
+	if share.GetDebug() {
+		helper.PrintWithLabel("[DEBUG] Raw Response", resp.String())
+	}
+
+	return p.ParseResponse(resp.Body())
+}
+
+func (p *Provider) CompleteStream(ctx context.Context, req llm.CompletionRequest, handler llm.StreamHandler) error {
+	p.StreamHandler = NewStreamHandler(handler)
+	jsonBody, err := p.PrepareRequest(req, true)
+	if err != nil {
+		return fmt.Errorf("marshal request: %w", err)
+	}
+
+	resp, err := p.DoStream(ctx, chatPath, jsonBody)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != 200 {
+		return base.NewStatusError(resp)
+	}
+
+	return p.HandleStreamResponse(resp, p)
+}
+
+// HandleStream 处理流式响应中的一行
+func (p *Provider) HandleStream(bytes []byte) error {
+	if strings.TrimSpace(string(bytes)) == "" {
+		return nil
+	}
+	return p.StreamHandler.AddContent(bytes)
+}
+
+// FinishStream 流结束时发送最终响应
+func (p *Provider) FinishStream() error {
+	return p.StreamHandler.Finish()
+}
+
+func (p *Provider) ParseResponse(body []byte) (*llm.CompletionResponse, error) {
+	var chatResp ChatResponse
+	if err := json.Unmarshal(body, &chatResp); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w, 原始响应: %s", err, string(body))
+	}
+	if chatResp.Error != "" {
+		return nil, fmt.Errorf("ollama: %s", chatResp.Error)
+	}
+
+	return newCompletionResponse(chatResp, chatResp.Message.Content, convertToolCalls(chatResp.Message.ToolCalls, 0)), nil
+}
+
+// AvailableModels 返回本地已下载的模型
+func (p *Provider) AvailableModels() []string {
+	resp, err := p.DoGet(context.Background(), tagsPath, nil)
+	if err != nil || resp.StatusCode() != 200 {
+		if share.GetDebug() {
+			helper.PrintWithLabel("[DEBUG] Get Models Error:", err)
+		}
+		return []string{}
+	}
+
+	var tags TagsResponse
+	if err := json.Unmarshal(resp.Body(), &tags); err != nil {
+		return []string{}
+	}
+	models := make([]string, 0, len(tags.Models))
+	for _, model := range tags.Models {
+		models = append(models, model.Name)
+	}
+	return models
+}
+
+// AvailableModels 返回服务提供的全部模型，本地服务的模型名称不一定以 gpt 开头
+func (p *OpenAIProvider) AvailableModels() []string {
+	return p.ListModels()
+}
+
+// convertToolCalls 转换工具调用，Ollama 不返回调用 ID，按序号生成
+func convertToolCalls(toolCalls []ToolCall, offset int) []llm.ToolCall {
+	result := make([]llm.ToolCall, 0, len(toolCalls))
+	for i, tc := range toolCalls {
+		result = append(result, llm.ToolCall{
+			ID:        fmt.Sprintf("call_%d", offset+i),
+			Type:      "function",
+			Function:  tc.Function.Name,
+			Arguments: tc.Function.Arguments,
+		})
+	}
+	return result
+}
+
+func newCompletionResponse(chatResp ChatResponse, content string, toolCalls []llm.ToolCall) *llm.CompletionResponse {
+	resp := &llm.CompletionResponse{
+		Content:      content,
+		Reasoning:    chatResp.Message.Thinking,
+		FinishReason: chatResp.DoneReason,
+		Usage: llm.Usage{
+			PromptTokens:     chatResp.PromptEvalCount,
+			CompletionTokens: chatResp.EvalCount,
+			TotalTokens:      chatResp.PromptEvalCount + chatResp.EvalCount,
+		},
+	}
+	if len(toolCalls) > 0 {
+		resp.FinishReason = "tool_calls"
+		resp.ToolCalls = toolCalls
+	}
+	return resp
+}
+
+func init() {
+	llm.Register(name, New)
+}
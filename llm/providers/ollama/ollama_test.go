@@ -0,0 +1,203 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/sjzsdu/wn/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/chat", func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("Authorization"))
+
+		var req ChatRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "qwen2.5", req.Model)
+
+		if !req.Stream {
+			if len(req.Tools) > 0 {
+				fmt.Fprint(w, `{"model":"qwen2.5","message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"read_file","arguments":{"path":"a.go"}}}]},"done":true,"done_reason":"stop","prompt_eval_count":10,"eval_count":5}`)
+				return
+			}
+			fmt.Fprint(w, `{"model":"qwen2.5","message":{"role":"assistant","content":"hello"},"done":true,"done_reason":"stop","prompt_eval_count":10,"eval_count":5}`)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		fmt.Fprintln(w, `{"message":{"role":"assistant","content":"hel"},"done":false}`)
+		fmt.Fprintln(w, `{"message":{"role":"assistant","content":"lo"},"done":false}`)
+		// 不带 done_reason 的结束事件
+		fmt.Fprintln(w, `{"message":{"role":"assistant","content":""},"done":true,"prompt_eval_count":3,"eval_count":2}`)
+	})
+	mux.HandleFunc("/api/embed", func(w http.ResponseWriter, r *http.Request) {
+		var req EmbedRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "bge-m3", req.Model)
+		assert.Equal(t, []string{"a", "b"}, req.Input)
+		fmt.Fprint(w, `{"model":"bge-m3","embeddings":[[0.1,0.2],[0.3,0.4]],"prompt_eval_count":4}`)
+	})
+	mux.HandleFunc("/v1/embeddings", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data":[{"index":0,"embedding":[0.5]}],"usage":{"prompt_tokens":1,"total_tokens":1}}`)
+	})
+	mux.HandleFunc("/api/tags", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"models":[{"name":"qwen2.5:latest"},{"name":"llama3.2:latest"}]}`)
+	})
+	mux.HandleFunc("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"hi from vllm"},"finish_reason":"stop"}],"usage":{"prompt_tokens":1,"completion_tokens":2,"total_tokens":3}}`)
+	})
+	mux.HandleFunc("/v1/models", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data":[{"id":"Qwen/Qwen2.5-7B-Instruct"}]}`)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestNew(t *testing.T) {
+	p, err := New(map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Equal(t, "ollama", p.GetName())
+	assert.Equal(t, defaultModel, p.GetModel())
+	assert.Equal(t, defaultEndpoint, p.(*Provider).APIEndpoint)
+
+	p, err = New(map[string]interface{}{"WN_OLLAMA_API": "openai", "WN_OLLAMA_ENDPOINT": "http://localhost:8000/v1/"})
+	assert.NoError(t, err)
+	assert.Equal(t, "http://localhost:8000/v1", p.(*OpenAIProvider).APIEndpoint)
+
+	_, err = New(map[string]interface{}{"WN_OLLAMA_API": "grpc"})
+	assert.Error(t, err)
+}
+
+func TestComplete(t *testing.T) {
+	server := newTestServer(t)
+	p, err := New(map[string]interface{}{"WN_OLLAMA_ENDPOINT": server.URL, "WN_OLLAMA_MODEL": "qwen2.5"})
+	assert.NoError(t, err)
+
+	resp, err := p.Complete(context.Background(), llm.CompletionRequest{
+		Messages: []llm.Message{{Role: "user", Content: "hi"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", resp.Content)
+	assert.Equal(t, "stop", resp.FinishReason)
+	assert.Equal(t, llm.Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15}, resp.Usage)
+
+	resp, err = p.Complete(context.Background(), llm.CompletionRequest{
+		Messages: []llm.Message{{Role: "user", Content: "read a.go"}},
+		Tools:    []mcp.Tool{mcp.NewTool("read_file", mcp.WithString("path", mcp.Required()))},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "tool_calls", resp.FinishReason)
+	assert.Equal(t, []llm.ToolCall{{
+		ID:        "call_0",
+		Type:      "function",
+		Function:  "read_file",
+		Arguments: map[string]interface{}{"path": "a.go"},
+	}}, resp.ToolCalls)
+}
+
+func TestCompleteStream(t *testing.T) {
+	server := newTestServer(t)
+	p, err := New(map[string]interface{}{"WN_OLLAMA_ENDPOINT": server.URL, "WN_OLLAMA_MODEL": "qwen2.5"})
+	assert.NoError(t, err)
+
+	var chunks []string
+	var final *llm.CompletionResponse
+	err = p.CompleteStream(context.Background(), llm.CompletionRequest{
+		Messages: []llm.Message{{Role: "user", Content: "hi"}},
+	}, func(resp llm.StreamResponse) {
+		if resp.Done {
+			final = resp.Response
+			return
+		}
+		chunks = append(chunks, resp.Content)
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"hel", "lo"}, chunks)
+	assert.Equal(t, "hello", final.Content)
+	assert.Equal(t, "stop", final.FinishReason)
+	assert.Equal(t, 5, final.Usage.TotalTokens)
+}
+
+func TestAvailableModels(t *testing.T) {
+	server := newTestServer(t)
+	p, _ := New(map[string]interface{}{"WN_OLLAMA_ENDPOINT": server.URL})
+	assert.Equal(t, []string{"qwen2.5:latest", "llama3.2:latest"}, p.AvailableModels())
+
+	p, _ = New(map[string]interface{}{"WN_OLLAMA_ENDPOINT": server.URL, "WN_OLLAMA_API": "openai"})
+	assert.Equal(t, []string{"Qwen/Qwen2.5-7B-Instruct"}, p.AvailableModels())
+}
+
+func TestOpenAICompatibleComplete(t *testing.T) {
+	server := newTestServer(t)
+	p, _ := New(map[string]interface{}{"WN_OLLAMA_ENDPOINT": server.URL, "WN_OLLAMA_API": "openai"})
+
+	resp, err := p.Complete(context.Background(), llm.CompletionRequest{
+		Messages: []llm.Message{{Role: "user", Content: "hi"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "hi from vllm", resp.Content)
+}
+
+func TestHandleMessages(t *testing.T) {
+	p := &Provider{}
+	messages := p.handleMessages([]llm.Message{
+		{Role: "assistant", ToolCalls: []llm.ToolCall{{ID: "call_0", Function: "shot", Arguments: map[string]interface{}{"x": 1.0}}}},
+		{Role: "tool", Content: "[image image/png, 3 bytes]", Parts: []llm.ContentPart{{Type: llm.ContentTypeImage, MIMEType: "image/png", Data: "YWJj"}}},
+	})
+	assert.Equal(t, []ToolCall{{Function: CallFunction{Name: "shot", Arguments: map[string]interface{}{"x": 1.0}}}}, messages[0].ToolCalls)
+	assert.Equal(t, []string{"YWJj"}, messages[1].Images)
+
+	messages = p.handleMessages([]llm.Message{llm.NewUserMessage(llm.ImagePart("image/png", []byte("abc")), llm.TextPart("describe"))})
+	assert.Equal(t, Message{Role: "user", Content: "describe", Images: []string{"YWJj"}}, messages[0])
+
+	_, err := p.PrepareRequest(llm.CompletionRequest{Messages: []llm.Message{
+		llm.NewUserMessage(llm.ImageURLPart("https://example.com/a.png")),
+	}}, false)
+	assert.ErrorContains(t, err, "https://example.com/a.png")
+}
+
+func TestHandleOptions(t *testing.T) {
+	p := &Provider{}
+	temperature, seed := 0.0, 7
+
+	options, err := p.handleOptions(llm.CompletionRequest{
+		MaxTokens:      64,
+		SamplingParams: llm.SamplingParams{Temperature: &temperature, Seed: &seed, Stop: []string{"END"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"num_predict": 64, "temperature": 0.0, "seed": 7, "stop": []string{"END"}}, options)
+
+	options, err = p.handleOptions(llm.CompletionRequest{})
+	assert.NoError(t, err)
+	assert.Nil(t, options)
+
+	_, err = p.handleOptions(llm.CompletionRequest{SamplingParams: llm.SamplingParams{ToolChoice: "read_file"}})
+	assert.Error(t, err)
+}
+
+func TestEmbed(t *testing.T) {
+	server := newTestServer(t)
+	p, _ := New(map[string]interface{}{"WN_OLLAMA_ENDPOINT": server.URL, "WN_OLLAMA_EMBEDDING_MODEL": "bge-m3"})
+	embedder := p.(llm.Embedder)
+	assert.Zero(t, embedder.Dimensions())
+
+	vectors, err := embedder.Embed(context.Background(), []string{"a", "b"})
+	assert.NoError(t, err)
+	assert.Equal(t, [][]float32{{0.1, 0.2}, {0.3, 0.4}}, vectors)
+	assert.Equal(t, 2, embedder.Dimensions())
+	assert.Equal(t, llm.Usage{PromptTokens: 4, TotalTokens: 4}, embedder.EmbeddingUsage())
+
+	// OpenAI 兼容接口同样支持
+	p, _ = New(map[string]interface{}{"WN_OLLAMA_ENDPOINT": server.URL, "WN_OLLAMA_API": "openai"})
+	vectors, err = p.(llm.Embedder).Embed(context.Background(), []string{"a"})
+	assert.NoError(t, err)
+	assert.Equal(t, [][]float32{{0.5}}, vectors)
+}
@@ -0,0 +1,60 @@
+package ollama
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/sjzsdu/wn/llm"
+	"github.com/sjzsdu/wn/llm/providers/base"
+)
+
+// StreamHandler 处理 /api/chat 返回的 NDJSON 流，每行是一个完整的 ChatResponse，由 base.StreamAccumulator 汇总
+type StreamHandler struct {
+	*base.StreamAccumulator
+	hasToolCalls bool
+}
+
+func NewStreamHandler(handler llm.StreamHandler) StreamHandler {
+	return StreamHandler{
+		StreamAccumulator: base.NewStreamAccumulator(handler),
+	}
+}
+
+func (h *StreamHandler) AddContent(data []byte) error {
+	var chunk ChatResponse
+	if err := json.Unmarshal(data, &chunk); err != nil {
+		return fmt.Errorf("unmarshal stream response: %w", err)
+	}
+	if chunk.Error != "" {
+		return fmt.Errorf("ollama: %s", chunk.Error)
+	}
+
+	h.AddReasoning(chunk.Message.Thinking)
+	h.AddText(chunk.Message.Content)
+	// 工具调用一次返回完整参数
+	for _, tc := range chunk.Message.ToolCalls {
+		h.AppendToolCall(llm.ToolCall{
+			Type:      "function",
+			Function:  tc.Function.Name,
+			Arguments: tc.Function.Arguments,
+		})
+		h.hasToolCalls = true
+	}
+
+	if chunk.Done {
+		h.SetUsage(llm.Usage{
+			PromptTokens:     chunk.PromptEvalCount,
+			CompletionTokens: chunk.EvalCount,
+		})
+		// 旧版本的 Ollama 不返回 done_reason
+		reason := chunk.DoneReason
+		if reason == "" {
+			reason = "stop"
+		}
+		if h.hasToolCalls {
+			reason = "tool_calls"
+		}
+		h.SetFinishReason(reason)
+	}
+	return nil
+}
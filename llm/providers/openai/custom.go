@@ -0,0 +1,121 @@
+package openai
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sjzsdu/wn/llm"
+	"github.com/sjzsdu/wn/llm/providers/base"
+)
+
+// CustomConfig 配置文件中定义的 OpenAI 兼容提供商，如 Azure OpenAI、OpenRouter 或内部代理
+type CustomConfig struct {
+	BaseURL string `json:"baseUrl"`
+	// APIKey 支持 ${NAME} 形式引用环境变量
+	APIKey string `json:"apiKey,omitempty"`
+	// AuthHeader 发送 API Key 的请求头，默认 Authorization
+	AuthHeader string `json:"authHeader,omitempty"`
+	// AuthScheme API Key 的前缀，Authorization 请求头默认 Bearer，其他请求头默认直接发送
+	AuthScheme string            `json:"authScheme,omitempty"`
+	Model      string            `json:"model,omitempty"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	// Query 附加到每个请求的查询参数，如 Azure 的 api-version
+	Query map[string]string `json:"query,omitempty"`
+	// EmbeddingModel 向量化使用的模型
+	EmbeddingModel string `json:"embeddingModel,omitempty"`
+	// Models 可用模型列表，为空时从 /models 接口获取
+	Models  []string `json:"models,omitempty"`
+	Timeout int      `json:"timeout,omitempty"`
+	// RequestsPerMinute 和 TokensPerMinute 限制每分钟的请求数和 token 数，0 表示不限制
+	RequestsPerMinute int `json:"requestsPerMinute,omitempty"`
+	TokensPerMinute   int `json:"tokensPerMinute,omitempty"`
+	// StreamUsage 流式请求时发送 stream_options 获取用量，默认关闭，部分网关会拒绝未知的参数
+	StreamUsage bool `json:"streamUsage,omitempty"`
+}
+
+// CustomProvider 由配置文件定义、复用 OpenAI 请求和流式处理的提供商
+type CustomProvider struct {
+	*Provider
+	models []string
+}
+
+// NewCustom 根据配置创建提供商
+func NewCustom(name string, config CustomConfig) (*CustomProvider, error) {
+	if config.BaseURL == "" {
+		return nil, fmt.Errorf("%s: baseUrl is required", name)
+	}
+
+	apiKey := os.ExpandEnv(config.APIKey)
+	requestConfig := base.RequestConfig{
+		Headers: map[string]string{
+			"Content-Type": "application/json",
+		},
+		Timeout: 30,
+		RetryConfig: &base.RetryConfig{
+			MaxRetries:  3,
+			RetryDelay:  1,
+			RetryPolicy: base.RetryPolicyLinear,
+		},
+		RateLimit: base.RateLimit{
+			RequestsPerMinute: config.RequestsPerMinute,
+			TokensPerMinute:   config.TokensPerMinute,
+		},
+	}
+	if config.Timeout > 0 {
+		requestConfig.Timeout = config.Timeout
+	}
+	for key, value := range config.Headers {
+		requestConfig.Headers[key] = os.ExpandEnv(value)
+	}
+	if apiKey != "" {
+		header := config.AuthHeader
+		if header == "" {
+			header = "Authorization"
+		}
+		scheme := config.AuthScheme
+		if scheme == "" && strings.EqualFold(header, "Authorization") {
+			scheme = "Bearer"
+		}
+		requestConfig.Headers[header] = strings.TrimSpace(scheme + " " + apiKey)
+	}
+
+	model := config.Model
+	if model == "" && len(config.Models) > 0 {
+		model = config.Models[0]
+	}
+
+	p := &CustomProvider{
+		Provider: &Provider{
+			Provider:       *base.NewProvider(name, apiKey, strings.TrimRight(config.BaseURL, "/"), model, requestConfig),
+			EmbeddingModel: config.EmbeddingModel,
+			StreamUsage:    config.StreamUsage,
+		},
+		models: config.Models,
+	}
+	if len(config.Query) > 0 {
+		p.Client.SetQueryParams(config.Query)
+	}
+	return p, nil
+}
+
+// AvailableModels 优先返回配置中的模型列表
+func (p *CustomProvider) AvailableModels() []string {
+	if len(p.models) > 0 {
+		return p.models
+	}
+	return p.ListModels()
+}
+
+// RegisterCustomProviders 把自定义提供商注册到 llm
+// 与内置提供商重名或配置无效的提供商会被跳过，并在返回的错误中列出
+func RegisterCustomProviders(configs map[string]CustomConfig) error {
+	return llm.RegisterConfigs(configs, func(name string, config CustomConfig) (llm.NewProvider, error) {
+		if _, err := NewCustom(name, config); err != nil {
+			return nil, err
+		}
+		return func(options map[string]interface{}) (llm.Provider, error) {
+			return NewCustom(name, config)
+		}, nil
+	})
+}
@@ -0,0 +1,79 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sjzsdu/wn/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCustom(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/openai/deployments/gpt4o/chat/completions", r.URL.Path)
+		assert.Equal(t, "2024-06-01", r.URL.Query().Get("api-version"))
+		assert.Equal(t, "secret", r.Header.Get("api-key"))
+		assert.Empty(t, r.Header.Get("Authorization"))
+		assert.Equal(t, "wn", r.Header.Get("X-Title"))
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}]}`)
+	}))
+	defer server.Close()
+
+	t.Setenv("TEST_AZURE_KEY", "secret")
+	p, err := NewCustom("azure", CustomConfig{
+		BaseURL:    server.URL + "/openai/deployments/gpt4o/",
+		APIKey:     "${TEST_AZURE_KEY}",
+		AuthHeader: "api-key",
+		Headers:    map[string]string{"X-Title": "wn"},
+		Query:      map[string]string{"api-version": "2024-06-01"},
+		Models:     []string{"gpt-4o", "gpt-4o-mini"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "azure", p.GetName())
+	assert.Equal(t, "gpt-4o", p.GetModel())
+	assert.Equal(t, []string{"gpt-4o", "gpt-4o-mini"}, p.AvailableModels())
+
+	resp, err := p.Complete(context.Background(), llm.CompletionRequest{
+		Messages: []llm.Message{{Role: "user", Content: "hi"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "hi", resp.Content)
+
+	_, err = NewCustom("empty", CustomConfig{})
+	assert.Error(t, err)
+}
+
+func TestCustomProviderModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer key", r.Header.Get("Authorization"))
+		fmt.Fprint(w, `{"data":[{"id":"anthropic/claude-3.5-sonnet"},{"id":"openai/gpt-4o"}]}`)
+	}))
+	defer server.Close()
+
+	p, err := NewCustom("router", CustomConfig{BaseURL: server.URL, APIKey: "key"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"anthropic/claude-3.5-sonnet", "openai/gpt-4o"}, p.AvailableModels())
+}
+
+func TestRegisterCustomProviders(t *testing.T) {
+	assert.NoError(t, RegisterCustomProviders(nil))
+
+	err := RegisterCustomProviders(map[string]CustomConfig{
+		"test-gateway": {BaseURL: "http://localhost:8080/v1", Model: "m1"},
+		"openai":       {BaseURL: "http://localhost:8080/v1"},
+		"broken":       {},
+	})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "openai")
+	assert.Contains(t, err.Error(), "broken")
+
+	assert.True(t, llm.Registered("test-gateway"))
+	assert.False(t, llm.Registered("broken"))
+
+	p, err := llm.CreateProvider("test-gateway", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "m1", p.GetModel())
+}
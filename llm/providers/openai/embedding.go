@@ -0,0 +1,96 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/sjzsdu/wn/llm"
+	"github.com/sjzsdu/wn/llm/providers/base"
+)
+
+const (
+	EmbeddingPath         = "/embeddings"
+	defaultEmbeddingModel = "text-embedding-3-small"
+	// embeddingBatchSize 单次请求的文本数，接口上限为 2048
+	embeddingBatchSize = 512
+)
+
+// embeddingDimensions 常用模型的默认向量维度
+var embeddingDimensions = map[string]int{
+	"text-embedding-3-small": 1536,
+	"text-embedding-3-large": 3072,
+	"text-embedding-ada-002": 1536,
+}
+
+type EmbeddingRequest struct {
+	Model          string   `json:"model"`
+	Input          []string `json:"input"`
+	EncodingFormat string   `json:"encoding_format,omitempty"`
+}
+
+type EmbeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+	Usage Usage `json:"usage"`
+}
+
+// Embed 实现 llm.Embedder
+func (p *Provider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return p.Embeddings.Run(ctx, texts, embeddingBatchSize, p.embed)
+}
+
+func (p *Provider) embed(ctx context.Context, batch []string) ([][]float32, llm.Usage, error) {
+	jsonBody, err := json.Marshal(EmbeddingRequest{
+		Model:          p.embeddingModel(),
+		Input:          batch,
+		EncodingFormat: "float",
+	})
+	if err != nil {
+		return nil, llm.Usage{}, fmt.Errorf("marshal request: %w", err)
+	}
+
+	resp, err := p.DoPost(ctx, EmbeddingPath, jsonBody)
+	if err != nil {
+		return nil, llm.Usage{}, err
+	}
+	if resp.StatusCode() != 200 {
+		return nil, llm.Usage{}, base.NewStatusError(resp)
+	}
+
+	var embeddingResp EmbeddingResponse
+	if err := json.Unmarshal(resp.Body(), &embeddingResp); err != nil {
+		return nil, llm.Usage{}, fmt.Errorf("解析响应失败: %w", err)
+	}
+	// 按 index 还原输入顺序
+	vectors := make([][]float32, len(batch))
+	for _, item := range embeddingResp.Data {
+		if item.Index < 0 || item.Index >= len(vectors) {
+			return nil, llm.Usage{}, fmt.Errorf("embeddings: 无效的 index %d", item.Index)
+		}
+		vectors[item.Index] = item.Embedding
+	}
+	return vectors, embeddingResp.Usage.toUsage(), nil
+}
+
+// Dimensions 实现 llm.Embedder，第一次请求前返回常用模型的默认维度
+func (p *Provider) Dimensions() int {
+	if dimensions := p.Embeddings.Dimensions(); dimensions > 0 {
+		return dimensions
+	}
+	return embeddingDimensions[p.embeddingModel()]
+}
+
+// EmbeddingUsage 实现 llm.Embedder
+func (p *Provider) EmbeddingUsage() llm.Usage {
+	return p.Embeddings.EmbeddingUsage()
+}
+
+func (p *Provider) embeddingModel() string {
+	if p.EmbeddingModel != "" {
+		return p.EmbeddingModel
+	}
+	return defaultEmbeddingModel
+}
@@ -0,0 +1,42 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sjzsdu/wn/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmbed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, EmbeddingPath, r.URL.Path)
+		var req EmbeddingRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "text-embedding-3-large", req.Model)
+		assert.Equal(t, []string{"a", "b"}, req.Input)
+		// 返回顺序与输入不同时按 index 还原
+		fmt.Fprint(w, `{"data":[{"index":1,"embedding":[0,1,0]},{"index":0,"embedding":[1,0,0]}],"usage":{"prompt_tokens":2,"total_tokens":2}}`)
+	}))
+	defer server.Close()
+
+	p, err := New(map[string]interface{}{
+		"WN_OPENAI_APIKEY":          "key",
+		"WN_OPENAI_ENDPOINT":        server.URL,
+		"WN_OPENAI_EMBEDDING_MODEL": "text-embedding-3-large",
+	})
+	assert.NoError(t, err)
+	embedder, ok := p.(llm.Embedder)
+	assert.True(t, ok)
+	assert.Equal(t, 3072, embedder.Dimensions())
+
+	vectors, err := embedder.Embed(context.Background(), []string{"a", "b"})
+	assert.NoError(t, err)
+	assert.Equal(t, [][]float32{{1, 0, 0}, {0, 1, 0}}, vectors)
+	assert.Equal(t, 3, embedder.Dimensions())
+	assert.Equal(t, llm.Usage{PromptTokens: 2, TotalTokens: 2}, embedder.EmbeddingUsage())
+}
@@ -22,6 +22,11 @@ const (
 type Provider struct {
 	base.Provider
 	StreamHandler StreamHandler
+	// EmbeddingModel 向量化使用的模型，为空时使用 text-embedding-3-small
+	EmbeddingModel string
+	Embeddings     base.EmbeddingBatcher
+	// StreamUsage 流式请求时发送 stream_options 让最后一个事件返回用量，部分兼容接口不支持该选项
+	StreamUsage bool
 }
 
 func New(options map[string]interface{}) (llm.Provider, error) {
@@ -41,6 +46,7 @@ func New(options map[string]interface{}) (llm.Provider, error) {
 			RetryDelay:  1,
 			RetryPolicy: base.RetryPolicyLinear,
 		},
+		RateLimit: base.RateLimitFromOptions(name, options),
 	}
 
 	p := &Provider{
@@ -51,6 +57,7 @@ func New(options map[string]interface{}) (llm.Provider, error) {
 			"gpt-3.5-turbo",
 			config,
 		),
+		StreamUsage: true,
 	}
 
 	if endpoint, ok := options["WN_OPENAI_ENDPOINT"].(string); ok && endpoint != "" {
@@ -59,6 +66,9 @@ func New(options map[string]interface{}) (llm.Provider, error) {
 	if model, ok := options["WN_OPENAI_MODEL"].(string); ok {
 		p.Model = model
 	}
+	if model, ok := options["WN_OPENAI_EMBEDDING_MODEL"].(string); ok {
+		p.EmbeddingModel = model
+	}
 
 	return p, nil
 }
@@ -79,7 +89,7 @@ func (p *Provider) Complete(ctx context.Context, req llm.CompletionRequest) (*ll
 		if share.GetDebug() {
 			helper.PrintWithLabel("[DEBUG] Raw Response", resp.String())
 		}
-		return nil, fmt.Errorf("请求失败，状态码：%d，响应：%s", resp.StatusCode(), resp.String())
+		return nil, base.NewStatusError(resp)
 	}
 	bodyBytes := resp.Body()
 	if share.GetDebug() {
@@ -108,7 +118,9 @@ func (p *Provider) CompleteStream(ctx context.Context, req llm.CompletionRequest
 // HandleStream 处理流式响应
 func (p *Provider) HandleStream(bytes []byte) error {
 	line := strings.TrimSpace(string(bytes))
-	helper.PrintWithLabel("[DEBUG] Stream Response", line)
+	if share.GetDebug() {
+		helper.PrintWithLabel("[DEBUG] Stream Response", line)
+	}
 	if line == "" || line == "data: [DONE]" || !strings.HasPrefix(line, "data: ") {
 		return nil
 	}
@@ -117,47 +129,31 @@ func (p *Provider) HandleStream(bytes []byte) error {
 	return p.StreamHandler.AddContent([]byte(data))
 }
 
+// FinishStream 流结束时发送最终响应
+func (p *Provider) FinishStream() error {
+	return p.StreamHandler.Finish()
+}
+
 // PrepareRequest 准备请求
 func (p *Provider) PrepareRequest(req llm.CompletionRequest, stream bool) ([]byte, error) {
 	// 创建请求体结构
 	request := &OpenAIRequest{
-		Model:     p.Model,
-		MaxTokens: 4096,
-		Stream:    stream,
-		Messages:  make([]Message, len(req.Messages)),
+		Model:            p.RequestModel(req.Model),
+		MaxTokens:        req.MaxTokens,
+		Stream:           stream,
+		ToolChoice:       base.ToolChoice(req.ToolChoice),
+		Temperature:      req.Temperature,
+		TopP:             req.TopP,
+		Stop:             req.Stop,
+		Seed:             req.Seed,
+		PresencePenalty:  req.PresencePenalty,
+		FrequencyPenalty: req.FrequencyPenalty,
 	}
-
-	if req.MaxTokens > 0 {
-		request.MaxTokens = req.MaxTokens
+	if stream && p.StreamUsage {
+		request.StreamOptions = &StreamOptions{IncludeUsage: true}
 	}
 
-	// 处理消息
-	for i, msg := range req.Messages {
-		message := Message{
-			Role:       msg.Role,
-			Content:    msg.Content,
-			Name:       msg.Name,
-			ToolCallID: msg.ToolCallId,
-		}
-
-		// 处理工具调用
-		if msg.ToolCalls != nil {
-			toolCalls := make([]ToolCall, len(msg.ToolCalls))
-			for j, tc := range msg.ToolCalls {
-				toolCalls[j] = ToolCall{
-					ID:   tc.ID,
-					Type: tc.Type,
-					Function: CallFunction{
-						Name:      tc.Function,
-						Arguments: helper.ToJSONString(tc.Arguments),
-					},
-				}
-			}
-			message.ToolCalls = toolCalls
-		}
-
-		request.Messages[i] = message
-	}
+	request.Messages = p.handleMessages(req.Messages)
 
 	// 处理工具
 	if req.Tools != nil {
@@ -187,8 +183,17 @@ func (p *Provider) PrepareRequest(req llm.CompletionRequest, stream bool) ([]byt
 		request.Tools = tools
 	}
 
-	// 处理响应格式
-	if req.ResponseFormat != "" {
+	// 处理响应格式，json_schema 要求根节点为对象
+	if req.JSONSchema.IsObject() {
+		request.ResponseFormat = ResponseFormat{
+			Type: "json_schema",
+			JSONSchema: &JSONSchema{
+				Name:   req.JSONSchema.Name,
+				Schema: req.JSONSchema.Schema,
+				Strict: req.JSONSchema.Strict,
+			},
+		}
+	} else if req.ResponseFormat != "" {
 		request.ResponseFormat = ResponseFormat{
 			Type: req.ResponseFormat,
 		}
@@ -205,16 +210,14 @@ func (p *Provider) ParseResponse(bodyBytes []byte) (*llm.CompletionResponse, err
 	var openAIResp struct {
 		Choices []struct {
 			Message struct {
-				Content   string     `json:"content"`
-				ToolCalls []ToolCall `json:"tool_calls"`
+				Content string `json:"content"`
+				// ReasoningContent 兼容服务（如 vLLM、DeepSeek）返回的思考过程
+				ReasoningContent string     `json:"reasoning_content"`
+				ToolCalls        []ToolCall `json:"tool_calls"`
 			} `json:"message"`
 			FinishReason string `json:"finish_reason"`
 		} `json:"choices"`
-		Usage struct {
-			PromptTokens     int `json:"prompt_tokens"`
-			CompletionTokens int `json:"completion_tokens"`
-			TotalTokens      int `json:"total_tokens"`
-		} `json:"usage"`
+		Usage Usage `json:"usage"`
 	}
 
 	if err := json.Unmarshal(bodyBytes, &openAIResp); err != nil {
@@ -232,12 +235,9 @@ func (p *Provider) ParseResponse(bodyBytes []byte) (*llm.CompletionResponse, err
 	choice := openAIResp.Choices[0]
 	resp := llm.CompletionResponse{
 		Content:      choice.Message.Content,
+		Reasoning:    choice.Message.ReasoningContent,
 		FinishReason: choice.FinishReason,
-		Usage: llm.Usage{
-			PromptTokens:     openAIResp.Usage.PromptTokens,
-			CompletionTokens: openAIResp.Usage.CompletionTokens,
-			TotalTokens:      openAIResp.Usage.TotalTokens,
-		},
+		Usage:        openAIResp.Usage.toUsage(),
 	}
 
 	// 处理工具调用
@@ -288,7 +288,18 @@ func (p *Provider) ParseStreamResponse(data string) (content string, finishReaso
 }
 
 func (p *Provider) AvailableModels() []string {
-	resp, err := p.DoGet(context.Background(), "/models", nil)
+	models := make([]string, 0)
+	for _, model := range p.ListModels() {
+		if strings.HasPrefix(model, "gpt") {
+			models = append(models, model)
+		}
+	}
+	return models
+}
+
+// ListModels 返回 /models 接口列出的全部模型
+func (p *Provider) ListModels() []string {
+	resp, err := p.DoGet(context.Background(), modelsPath, nil)
 	if err != nil {
 		if share.GetDebug() {
 			helper.PrintWithLabel("[DEBUG] Get Models Error:", err)
@@ -303,7 +314,9 @@ func (p *Provider) AvailableModels() []string {
 	}
 
 	bodyBytes := resp.Body()
-	helper.PrintWithLabel("[DEBUG] Get Models Response", string(bodyBytes))
+	if share.GetDebug() {
+		helper.PrintWithLabel("[DEBUG] Get Models Response", string(bodyBytes))
+	}
 	if err := json.Unmarshal(bodyBytes, &response); err != nil {
 		if share.GetDebug() {
 			helper.PrintWithLabel("[DEBUG] Models Response Error", err)
@@ -312,11 +325,9 @@ func (p *Provider) AvailableModels() []string {
 		return []string{}
 	}
 
-	models := make([]string, 0)
+	models := make([]string, 0, len(response.Data))
 	for _, model := range response.Data {
-		if strings.HasPrefix(model.ID, "gpt") {
-			models = append(models, model.ID)
-		}
+		models = append(models, model.ID)
 	}
 	return models
 }
@@ -324,3 +335,96 @@ func (p *Provider) AvailableModels() []string {
 func init() {
 	llm.Register(name, New)
 }
+
+// handleMessages 转换消息，包含图片或文件的消息按片段顺序发送
+// tool 消息只能包含文本，工具返回的图片会在连续的 tool 消息之后以一条 user 消息补充
+func (p *Provider) handleMessages(messages []llm.Message) []Message {
+	result := make([]Message, 0, len(messages))
+	var toolImages []ContentPart
+
+	for i, msg := range messages {
+		message := Message{
+			Role:       msg.Role,
+			Content:    msg.TextContent(),
+			Name:       msg.Name,
+			ToolCallID: msg.ToolCallId,
+		}
+
+		// 处理工具调用
+		if msg.ToolCalls != nil {
+			toolCalls := make([]ToolCall, len(msg.ToolCalls))
+			for j, tc := range msg.ToolCalls {
+				toolCalls[j] = ToolCall{
+					ID:   tc.ID,
+					Type: tc.Type,
+					Function: CallFunction{
+						Name:      tc.Function,
+						Arguments: helper.ToJSONString(tc.Arguments),
+					},
+				}
+			}
+			message.ToolCalls = toolCalls
+		}
+
+		switch images := msg.Images(); {
+		case msg.Role == "tool":
+			if len(images) > 0 {
+				toolImages = append(toolImages, ContentPart{
+					Type: "text",
+					Text: fmt.Sprintf("Images returned by tool call %s:", msg.ToolCallId),
+				})
+				toolImages = append(toolImages, imageParts(images)...)
+			}
+		case msg.HasMedia():
+			message.Content = contentParts(msg.Parts)
+		}
+		result = append(result, message)
+
+		lastTool := i+1 == len(messages) || messages[i+1].Role != "tool"
+		if len(toolImages) > 0 && lastTool {
+			result = append(result, Message{Role: "user", Content: toolImages})
+			toolImages = nil
+		}
+	}
+	return result
+}
+
+func imageParts(images []llm.ContentPart) []ContentPart {
+	parts := make([]ContentPart, len(images))
+	for i, image := range images {
+		parts[i] = ContentPart{
+			Type:     "image_url",
+			ImageURL: &ImageURL{URL: image.DataURL()},
+		}
+	}
+	return parts
+}
+
+// contentParts 按顺序转换内容片段，文件以 file 片段内联发送
+func contentParts(parts []llm.ContentPart) []ContentPart {
+	result := make([]ContentPart, 0, len(parts))
+	for _, part := range parts {
+		switch part.Type {
+		case llm.ContentTypeImage:
+			result = append(result, imageParts([]llm.ContentPart{part})...)
+		case llm.ContentTypeFile:
+			result = append(result, ContentPart{
+				Type: "file",
+				File: &File{Filename: part.Name, FileData: part.DataURL()},
+			})
+		default:
+			result = append(result, ContentPart{Type: "text", Text: part.Text})
+		}
+	}
+	return result
+}
+
+// toUsage 转换用量，推理模型的思考 token 单独记录
+func (u Usage) toUsage() llm.Usage {
+	return llm.Usage{
+		PromptTokens:     u.PromptTokens,
+		CompletionTokens: u.CompletionTokens,
+		TotalTokens:      u.TotalTokens,
+		ReasoningTokens:  u.CompletionTokensDetails.ReasoningTokens,
+	}
+}
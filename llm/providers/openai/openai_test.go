@@ -0,0 +1,165 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sjzsdu/wn/llm"
+	"github.com/sjzsdu/wn/llm/providers/base"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleMessages(t *testing.T) {
+	p := &Provider{}
+	image := llm.ContentPart{Type: llm.ContentTypeImage, MIMEType: "image/png", Data: "YWJj"}
+
+	messages := p.handleMessages([]llm.Message{
+		{Role: "user", Content: "look", Parts: []llm.ContentPart{{Type: llm.ContentTypeText, Text: "look"}, image}},
+		{Role: "assistant", ToolCalls: []llm.ToolCall{{ID: "1", Type: "function", Function: "shot"}, {ID: "2", Type: "function", Function: "shot"}}},
+		{Role: "tool", ToolCallId: "1", Content: "[image image/png, 3 bytes]", Parts: []llm.ContentPart{image}},
+		{Role: "tool", ToolCallId: "2", Content: "failed", IsError: true},
+	})
+
+	assert.Len(t, messages, 5)
+	assert.Equal(t, []ContentPart{
+		{Type: "text", Text: "look"},
+		{Type: "image_url", ImageURL: &ImageURL{URL: "data:image/png;base64,YWJj"}},
+	}, messages[0].Content)
+
+	// tool 消息只包含文本
+	assert.Equal(t, "[image image/png, 3 bytes]", messages[2].Content)
+	assert.Equal(t, "Error: the tool call failed.\nfailed", messages[3].Content)
+
+	// 图片在最后一条 tool 消息之后补充
+	assert.Equal(t, "user", messages[4].Role)
+	assert.Equal(t, []ContentPart{
+		{Type: "text", Text: "Images returned by tool call 1:"},
+		{Type: "image_url", ImageURL: &ImageURL{URL: "data:image/png;base64,YWJj"}},
+	}, messages[4].Content)
+
+	// 片段按原顺序发送
+	messages = p.handleMessages([]llm.Message{llm.NewUserMessage(
+		llm.ImageURLPart("https://example.com/a.jpg"),
+		llm.ContentPart{Type: llm.ContentTypeFile, Name: "a.pdf", MIMEType: "application/pdf", Data: "JVBERg=="},
+		llm.TextPart("summarize"),
+	)})
+	assert.Equal(t, []ContentPart{
+		{Type: "image_url", ImageURL: &ImageURL{URL: "https://example.com/a.jpg"}},
+		{Type: "file", File: &File{Filename: "a.pdf", FileData: "data:application/pdf;base64,JVBERg=="}},
+		{Type: "text", Text: "summarize"},
+	}, messages[0].Content)
+}
+
+func TestPrepareRequest(t *testing.T) {
+	p := &Provider{Provider: *base.NewProvider(name, "key", baseAPIEndpoint, "gpt-4o-mini", base.RequestConfig{})}
+	temperature, seed := 0.0, 42
+
+	body, err := p.PrepareRequest(llm.CompletionRequest{
+		Messages: []llm.Message{{Role: "user", Content: "hi"}},
+		Model:    "gpt-4o",
+		SamplingParams: llm.SamplingParams{
+			Temperature: &temperature,
+			Seed:        &seed,
+			Stop:        []string{"END"},
+			ToolChoice:  "read_file",
+		},
+	}, false)
+	assert.NoError(t, err)
+
+	var request map[string]interface{}
+	assert.NoError(t, json.Unmarshal(body, &request))
+	assert.Equal(t, "gpt-4o", request["model"])
+	assert.NotContains(t, request, "max_tokens")
+	assert.Equal(t, 0.0, request["temperature"])
+	assert.Equal(t, 42.0, request["seed"])
+	assert.Equal(t, []interface{}{"END"}, request["stop"])
+	assert.Equal(t, map[string]interface{}{"type": "function", "function": map[string]interface{}{"name": "read_file"}}, request["tool_choice"])
+	assert.NotContains(t, request, "top_p")
+
+	body, err = p.PrepareRequest(llm.CompletionRequest{MaxTokens: 100}, false)
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), `"model":"gpt-4o-mini"`)
+	assert.Contains(t, string(body), `"max_tokens":100`)
+
+	// 只有开启 StreamUsage 时才发送 stream_options
+	body, err = p.PrepareRequest(llm.CompletionRequest{}, true)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(body), "stream_options")
+	p.StreamUsage = true
+	body, err = p.PrepareRequest(llm.CompletionRequest{}, true)
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), `"stream_options":{"include_usage":true}`)
+}
+
+func TestPrepareRequest_JSONSchema(t *testing.T) {
+	p := &Provider{Provider: *base.NewProvider(name, "key", baseAPIEndpoint, "gpt-4o-mini", base.RequestConfig{})}
+	schema := &llm.JSONSchema{Name: "result", Schema: map[string]interface{}{"type": "object"}}
+
+	body, err := p.PrepareRequest(llm.CompletionRequest{ResponseFormat: "json_object", JSONSchema: schema}, false)
+	assert.NoError(t, err)
+	var request map[string]interface{}
+	assert.NoError(t, json.Unmarshal(body, &request))
+	assert.Equal(t, map[string]interface{}{
+		"type":        "json_schema",
+		"json_schema": map[string]interface{}{"name": "result", "schema": map[string]interface{}{"type": "object"}},
+	}, request["response_format"])
+
+	// 根节点不是对象时使用 ResponseFormat
+	schema.Schema = map[string]interface{}{"type": "array"}
+	body, err = p.PrepareRequest(llm.CompletionRequest{ResponseFormat: "json_object", JSONSchema: schema}, false)
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), `"response_format":{"type":"json_object"}`)
+}
+
+// replayStream 用 testdata 中录制的 SSE 数据回放流式请求，返回收到的全部事件
+func replayStream(t *testing.T, fixture string) ([]llm.StreamResponse, error) {
+	data, err := os.ReadFile(filepath.Join("testdata", fixture))
+	assert.NoError(t, err)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	p, err := New(map[string]interface{}{"WN_OPENAI_APIKEY": "key", "WN_OPENAI_ENDPOINT": server.URL})
+	assert.NoError(t, err)
+
+	var events []llm.StreamResponse
+	err = p.CompleteStream(context.Background(), llm.CompletionRequest{
+		Messages: []llm.Message{{Role: "user", Content: "hi"}},
+	}, func(resp llm.StreamResponse) {
+		events = append(events, resp)
+	})
+	return events, err
+}
+
+func TestCompleteStream(t *testing.T) {
+	events, err := replayStream(t, "text.sse")
+	assert.NoError(t, err)
+	assert.Len(t, events, 3)
+	assert.Equal(t, "Hello", events[0].Content)
+	final := events[len(events)-1]
+	assert.True(t, final.Done)
+	assert.Equal(t, "Hello world", final.Response.Content)
+	assert.Equal(t, "stop", final.Response.FinishReason)
+	assert.Equal(t, llm.Usage{PromptTokens: 5, CompletionTokens: 2, TotalTokens: 7}, final.Response.Usage)
+
+	events, err = replayStream(t, "parallel_tool_calls.sse")
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+	resp := events[0].Response
+	assert.Equal(t, "tool_calls", resp.FinishReason)
+	assert.Equal(t, []llm.ToolCall{
+		{ID: "call_weather", Type: "function", Function: "get_weather", Arguments: map[string]interface{}{"city": "Beijing"}},
+		{ID: "call_time", Type: "function", Function: "get_time", Arguments: map[string]interface{}{"tz": "Asia/Shanghai"}},
+	}, resp.ToolCalls)
+	assert.Equal(t, 123, resp.Usage.TotalTokens)
+
+	_, err = replayStream(t, "invalid_arguments.sse")
+	assert.ErrorContains(t, err, "get_weather")
+}
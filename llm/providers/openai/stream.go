@@ -3,109 +3,66 @@ package openai
 import (
 	"encoding/json"
 	"fmt"
-	"strings"
 
-	"github.com/sjzsdu/wn/helper"
 	"github.com/sjzsdu/wn/llm"
+	"github.com/sjzsdu/wn/llm/providers/base"
 )
 
+// StreamHandler 解析 chat/completions 的流式数据，由 base.StreamAccumulator 汇总
 type StreamHandler struct {
-	handler      llm.StreamHandler
-	fullContent  strings.Builder
-	toolCalls    []llm.ToolCall
-	usage        llm.Usage
-	currentTool  *llm.ToolCall
-	argsBuilder  strings.Builder
+	*base.StreamAccumulator
+}
+
+// streamToolCall 流式响应中的工具调用增量，通过 index 区分并行的工具调用
+type streamToolCall struct {
+	Index int `json:"index"`
+	ToolCall
+}
+
+type streamResponse struct {
+	Choices []struct {
+		Delta struct {
+			Content          string           `json:"content"`
+			ReasoningContent string           `json:"reasoning_content"`
+			ToolCalls        []streamToolCall `json:"tool_calls"`
+		} `json:"delta"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	// Usage 开启 stream_options.include_usage 后在最后一个事件中返回
+	Usage *Usage `json:"usage"`
 }
 
 func NewStreamHandler(handler llm.StreamHandler) StreamHandler {
 	return StreamHandler{
-		handler: handler,
+		StreamAccumulator: base.NewStreamAccumulator(handler),
 	}
 }
 
 func (h *StreamHandler) AddContent(data []byte) error {
-	var streamResp struct {
-		Choices []struct {
-			Delta struct {
-				Content   string     `json:"content"`
-				ToolCalls []ToolCall `json:"tool_calls"`
-			} `json:"delta"`
-			FinishReason string `json:"finish_reason"`
-		} `json:"choices"`
-		Usage struct {
-			PromptTokens     int `json:"prompt_tokens"`
-			CompletionTokens int `json:"completion_tokens"`
-			TotalTokens      int `json:"total_tokens"`
-		} `json:"usage"`
-	}
-
+	var streamResp streamResponse
 	if err := json.Unmarshal(data, &streamResp); err != nil {
 		return fmt.Errorf("unmarshal stream response: %w", err)
 	}
 
+	if streamResp.Usage != nil {
+		h.SetUsage(streamResp.Usage.toUsage())
+	}
 	if len(streamResp.Choices) == 0 {
 		return nil
 	}
 
 	choice := streamResp.Choices[0]
-
-	if choice.Delta.Content != "" {
-		h.fullContent.WriteString(choice.Delta.Content)
-		h.handler(llm.StreamResponse{
-			Content: choice.Delta.Content,
-			Done:    false,
+	h.AddReasoning(choice.Delta.ReasoningContent)
+	h.AddText(choice.Delta.Content)
+	for _, tc := range choice.Delta.ToolCalls {
+		h.AddToolCall(base.ToolCallDelta{
+			Index:     tc.Index,
+			ID:        tc.ID,
+			Type:      tc.Type,
+			Name:      tc.Function.Name,
+			Arguments: tc.Function.Arguments,
 		})
 	}
-
-	if len(choice.Delta.ToolCalls) > 0 {
-		for _, tc := range choice.Delta.ToolCalls {
-			if tc.ID != "" {
-				if h.currentTool == nil || h.currentTool.ID != tc.ID {
-					if h.currentTool != nil {
-						h.currentTool.Arguments = helper.StringToMap(h.argsBuilder.String())
-						h.toolCalls = append(h.toolCalls, *h.currentTool)
-					}
-					h.currentTool = &llm.ToolCall{
-						ID:       tc.ID,
-						Type:     tc.Type,
-						Function: tc.Function.Name,
-					}
-					h.argsBuilder.Reset()
-				}
-			}
-			if tc.Function.Arguments != "" {
-				h.argsBuilder.WriteString(tc.Function.Arguments)
-			}
-		}
-	}
-
-	if choice.FinishReason != "" {
-		if choice.FinishReason == "tool_calls" && h.currentTool != nil {
-			h.currentTool.Arguments = helper.StringToMap(h.argsBuilder.String())
-			h.toolCalls = append(h.toolCalls, *h.currentTool)
-		}
-
-		if streamResp.Usage.TotalTokens > 0 {
-			h.usage = llm.Usage{
-				PromptTokens:     streamResp.Usage.PromptTokens,
-				CompletionTokens: streamResp.Usage.CompletionTokens,
-				TotalTokens:      streamResp.Usage.TotalTokens,
-			}
-		}
-
-		h.handler(llm.StreamResponse{
-			Content:      h.fullContent.String(),
-			FinishReason: choice.FinishReason,
-			Done:         true,
-			Response: &llm.CompletionResponse{
-				Content:      h.fullContent.String(),
-				FinishReason: choice.FinishReason,
-				ToolCalls:    h.toolCalls,
-				Usage:        h.usage,
-			},
-		})
-	}
-
+	h.SetFinishReason(choice.FinishReason)
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,103 @@
+package qwen
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/sjzsdu/wn/llm"
+	"github.com/sjzsdu/wn/llm/providers/base"
+)
+
+const (
+	EmbeddingPath         = "/services/embeddings/text-embedding/text-embedding"
+	defaultEmbeddingModel = "text-embedding-v3"
+	// embeddingBatchSize text-embedding-v3 单次请求最多 10 条文本
+	embeddingBatchSize = 10
+)
+
+// embeddingDimensions 常用模型的默认向量维度
+var embeddingDimensions = map[string]int{
+	"text-embedding-v1": 1536,
+	"text-embedding-v2": 1536,
+	"text-embedding-v3": 1024,
+}
+
+type EmbeddingRequest struct {
+	Model string `json:"model"`
+	Input struct {
+		Texts []string `json:"texts"`
+	} `json:"input"`
+}
+
+type EmbeddingResponse struct {
+	Output struct {
+		Embeddings []struct {
+			TextIndex int       `json:"text_index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"embeddings"`
+	} `json:"output"`
+	Usage struct {
+		TotalTokens int `json:"total_tokens"`
+	} `json:"usage"`
+}
+
+// Embed 实现 llm.Embedder
+func (p *Provider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return p.Embeddings.Run(ctx, texts, embeddingBatchSize, p.embed)
+}
+
+func (p *Provider) embed(ctx context.Context, batch []string) ([][]float32, llm.Usage, error) {
+	request := EmbeddingRequest{Model: p.embeddingModel()}
+	request.Input.Texts = batch
+	jsonBody, err := json.Marshal(request)
+	if err != nil {
+		return nil, llm.Usage{}, fmt.Errorf("marshal request: %w", err)
+	}
+
+	resp, err := p.DoPost(ctx, EmbeddingPath, jsonBody)
+	if err != nil {
+		return nil, llm.Usage{}, err
+	}
+	if resp.StatusCode() != 200 {
+		return nil, llm.Usage{}, base.NewStatusError(resp)
+	}
+
+	var embeddingResp EmbeddingResponse
+	if err := json.Unmarshal(resp.Body(), &embeddingResp); err != nil {
+		return nil, llm.Usage{}, fmt.Errorf("解析响应失败: %w", err)
+	}
+	// 按 text_index 还原输入顺序
+	vectors := make([][]float32, len(batch))
+	for _, item := range embeddingResp.Output.Embeddings {
+		if item.TextIndex < 0 || item.TextIndex >= len(vectors) {
+			return nil, llm.Usage{}, fmt.Errorf("embeddings: 无效的 text_index %d", item.TextIndex)
+		}
+		vectors[item.TextIndex] = item.Embedding
+	}
+	usage := llm.Usage{
+		PromptTokens: embeddingResp.Usage.TotalTokens,
+		TotalTokens:  embeddingResp.Usage.TotalTokens,
+	}
+	return vectors, usage, nil
+}
+
+// Dimensions 实现 llm.Embedder，第一次请求前返回常用模型的默认维度
+func (p *Provider) Dimensions() int {
+	if dimensions := p.Embeddings.Dimensions(); dimensions > 0 {
+		return dimensions
+	}
+	return embeddingDimensions[p.embeddingModel()]
+}
+
+// EmbeddingUsage 实现 llm.Embedder
+func (p *Provider) EmbeddingUsage() llm.Usage {
+	return p.Embeddings.EmbeddingUsage()
+}
+
+func (p *Provider) embeddingModel() string {
+	if p.EmbeddingModel != "" {
+		return p.EmbeddingModel
+	}
+	return defaultEmbeddingModel
+}
@@ -16,12 +16,16 @@ const (
 	name            = "qwen"
 	baseAPIEndpoint = "https://dashscope.aliyuncs.com/api/v1"
 	CompletionPath  = "/services/aigc/text-generation/generation"
+	MultimodalPath  = "/services/aigc/multimodal-generation/generation" // 消息中包含图片时使用
 	modelsPath      = "/models"
 )
 
 type Provider struct {
 	base.Provider
 	StreamHandler StreamHandler
+	// EmbeddingModel 向量化使用的模型，为空时使用 text-embedding-v3
+	EmbeddingModel string
+	Embeddings     base.EmbeddingBatcher
 }
 
 func New(options map[string]interface{}) (llm.Provider, error) {
@@ -42,6 +46,7 @@ func New(options map[string]interface{}) (llm.Provider, error) {
 			RetryDelay:  1,
 			RetryPolicy: base.RetryPolicyLinear,
 		},
+		RateLimit: base.RateLimitFromOptions(name, options),
 	}
 
 	p := &Provider{
@@ -60,20 +65,38 @@ func New(options map[string]interface{}) (llm.Provider, error) {
 	if model, ok := options["WN_QWEN_MODEL"].(string); ok {
 		p.Model = model
 	}
+	if model, ok := options["WN_QWEN_EMBEDDING_MODEL"].(string); ok {
+		p.EmbeddingModel = model
+	}
 
 	return p, nil
 }
 
 func (p *Provider) PrepareRequest(req llm.CompletionRequest, stream bool) ([]byte, error) {
+	if err := req.Reject(name, llm.ParamFrequencyPenalty); err != nil {
+		return nil, err
+	}
+	model := p.RequestModel(req.Model)
+	multimodal := req.HasMedia()
+	if multimodal && !visionModel(model) {
+		return nil, fmt.Errorf("%w，请使用 qwen-vl 系列模型", req.RejectMedia(name, model))
+	}
+
 	// 直接构建 QwenRequest
 	request := &QwenRequest{
-		Model: p.Model,
+		Model: model,
 		Input: Input{
 			Messages: make([]Message, len(req.Messages)),
 		},
 		Parameters: Parameters{
-			MaxTokens: req.MaxTokens,
-			Stream:    stream,
+			MaxTokens:       req.MaxTokens,
+			Stream:          stream,
+			Temperature:     req.Temperature,
+			TopP:            req.TopP,
+			Stop:            req.Stop,
+			Seed:            req.Seed,
+			PresencePenalty: req.PresencePenalty,
+			ToolChoice:      base.ToolChoice(req.ToolChoice),
 		},
 	}
 
@@ -81,10 +104,18 @@ func (p *Provider) PrepareRequest(req llm.CompletionRequest, stream bool) ([]byt
 	for i, msg := range req.Messages {
 		message := Message{
 			Role:       msg.Role,
-			Content:    msg.Content,
+			Content:    msg.TextContent(),
 			Name:       msg.Name,
 			ToolCallId: msg.ToolCallId,
 		}
+		// 多模态接口要求所有消息的内容都是列表
+		if multimodal {
+			items, err := contentItems(msg)
+			if err != nil {
+				return nil, err
+			}
+			message.Content = items
+		}
 
 		// 处理工具调用
 		if msg.ToolCalls != nil {
@@ -143,13 +174,13 @@ func (p *Provider) Complete(ctx context.Context, req llm.CompletionRequest) (*ll
 		return nil, fmt.Errorf("marshal request: %w", err)
 	}
 
-	resp, err := p.DoPost(ctx, CompletionPath, jsonBody)
+	resp, err := p.DoPost(ctx, completionPath(req), jsonBody)
 	if err != nil {
 		return nil, err
 	}
 
 	if resp.StatusCode() != 200 {
-		return nil, fmt.Errorf("请求失败，状态码：%d，响应：%s", resp.StatusCode(), resp.String())
+		return nil, base.NewStatusError(resp)
 	}
 	bodyBytes := resp.Body()
 	if share.GetDebug() {
@@ -176,8 +207,8 @@ func (p *Provider) ParseResponse(bodyBytes []byte) (*llm.CompletionResponse, err
 	}
 
 	response := llm.CompletionResponse{
-		Content:      qwenResp.Output.Text,
-		FinishReason: qwenResp.Output.FinishReason,
+		Content:      qwenResp.Output.text(),
+		FinishReason: qwenResp.Output.finishReason(),
 		Usage: llm.Usage{
 			PromptTokens:     qwenResp.Usage.InputTokens,
 			CompletionTokens: qwenResp.Usage.OutputTokens,
@@ -209,13 +240,13 @@ func (p *Provider) CompleteStream(ctx context.Context, req llm.CompletionRequest
 		return fmt.Errorf("准备请求失败: %w", err)
 	}
 
-	resp, err := p.DoStream(ctx, CompletionPath, jsonBody)
+	resp, err := p.DoStream(ctx, completionPath(req), jsonBody)
 	if err != nil {
 		return fmt.Errorf("发送请求失败: %w", err)
 	}
 
 	if resp.StatusCode() != 200 {
-		return fmt.Errorf("请求失败，状态码：%d，响应：%s", resp.StatusCode(), resp.String())
+		return base.NewStatusError(resp)
 	}
 
 	return p.HandleStreamResponse(resp, p)
@@ -226,7 +257,7 @@ func (p *Provider) HandleStream(bytes []byte) error {
 	if line == "" || line == "data: [DONE]" || !strings.HasPrefix(line, "data:") {
 		return nil
 	}
-	data := strings.TrimPrefix(line, "data: ")
+	data := strings.TrimPrefix(line, "data:")
 	if share.GetDebug() {
 		helper.PrintWithLabel("[DEBUG] Stream Response", string(data))
 	}
@@ -234,6 +265,11 @@ func (p *Provider) HandleStream(bytes []byte) error {
 	return nil
 }
 
+// FinishStream 流结束时发送最终响应
+func (p *Provider) FinishStream() error {
+	return p.StreamHandler.Finish()
+}
+
 // AvailableModels 通过API获取支持的模型列表
 func (p *Provider) AvailableModels() []string {
 	// 修正：使用正确的 API 路径
@@ -276,3 +312,34 @@ func (p *Provider) AvailableModels() []string {
 func init() {
 	llm.Register(name, New)
 }
+
+func completionPath(req llm.CompletionRequest) string {
+	if req.HasMedia() {
+		return MultimodalPath
+	}
+	return CompletionPath
+}
+
+// visionModel 判断模型是否支持图片输入
+func visionModel(model string) bool {
+	return strings.Contains(model, "-vl") || strings.HasPrefix(model, "qvq") || strings.Contains(model, "omni")
+}
+
+// contentItems 按顺序转换多模态消息的内容，DashScope 不支持内联文件
+func contentItems(msg llm.Message) ([]ContentItem, error) {
+	if msg.Role == "tool" || !msg.HasMedia() {
+		return []ContentItem{{Text: msg.TextContent()}}, nil
+	}
+	items := make([]ContentItem, 0, len(msg.Parts))
+	for _, part := range msg.Parts {
+		switch part.Type {
+		case llm.ContentTypeImage:
+			items = append(items, ContentItem{Image: part.DataURL()})
+		case llm.ContentTypeFile:
+			return nil, fmt.Errorf("qwen: 不支持发送文件 %s", part.Name)
+		default:
+			items = append(items, ContentItem{Text: part.Text})
+		}
+	}
+	return items, nil
+}
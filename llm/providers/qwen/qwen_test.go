@@ -0,0 +1,121 @@
+package qwen
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sjzsdu/wn/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+// replayStream 用 testdata 中录制的 SSE 数据回放流式请求，返回收到的全部事件
+func replayStream(t *testing.T, fixture string) ([]llm.StreamResponse, error) {
+	data, err := os.ReadFile(filepath.Join("testdata", fixture))
+	assert.NoError(t, err)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	p, err := New(map[string]interface{}{"WN_QWEN_APIKEY": "key", "WN_QWEN_ENDPOINT": server.URL})
+	assert.NoError(t, err)
+
+	var events []llm.StreamResponse
+	err = p.CompleteStream(context.Background(), llm.CompletionRequest{
+		Messages: []llm.Message{{Role: "user", Content: "hi"}},
+	}, func(resp llm.StreamResponse) {
+		events = append(events, resp)
+	})
+	return events, err
+}
+
+func TestCompleteStream(t *testing.T) {
+	events, err := replayStream(t, "tool_calls.sse")
+	assert.NoError(t, err)
+	assert.Equal(t, "好的", events[0].Content)
+	assert.Equal(t, "，", events[1].Content)
+
+	final := events[len(events)-1]
+	assert.True(t, final.Done)
+	assert.Equal(t, "好的，", final.Response.Content)
+	assert.Equal(t, []llm.ToolCall{
+		{ID: "call_q1", Type: "function", Function: "get_weather", Arguments: map[string]interface{}{"city": "杭州"}},
+	}, final.Response.ToolCalls)
+	assert.Equal(t, 75, final.Response.Usage.TotalTokens)
+}
+
+func TestPrepareRequest_Multimodal(t *testing.T) {
+	request := llm.CompletionRequest{Messages: []llm.Message{
+		{Role: "system", Content: "be brief"},
+		llm.NewUserMessage(llm.ImageURLPart("https://example.com/a.png"), llm.TextPart("what is this?")),
+	}}
+	assert.Equal(t, MultimodalPath, completionPath(request))
+
+	p, err := New(map[string]interface{}{"WN_QWEN_APIKEY": "key"})
+	assert.NoError(t, err)
+	_, err = p.(*Provider).PrepareRequest(request, false)
+	assert.ErrorContains(t, err, "qwen-vl")
+
+	request.Model = "qwen-vl-max"
+	body, err := p.(*Provider).PrepareRequest(request, false)
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), `{"role":"system","content":[{"text":"be brief"}]}`)
+	assert.Contains(t, string(body), `"content":[{"image":"https://example.com/a.png"},{"text":"what is this?"}]`)
+
+	request.Messages = append(request.Messages, llm.NewUserMessage(llm.FilePart("a.pdf", "application/pdf", []byte("%PDF"))))
+	_, err = p.(*Provider).PrepareRequest(request, false)
+	assert.ErrorContains(t, err, "a.pdf")
+}
+
+func TestParseResponse_Multimodal(t *testing.T) {
+	resp, err := (&Provider{}).ParseResponse([]byte(`{
+		"output": {"choices": [{"finish_reason": "stop", "message": {"role": "assistant", "content": [{"text": "一只猫"}]}}]},
+		"usage": {"input_tokens": 1200, "output_tokens": 4}
+	}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "一只猫", resp.Content)
+	assert.Equal(t, "stop", resp.FinishReason)
+}
+
+func TestEmbed(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, EmbeddingPath, r.URL.Path)
+		var req EmbeddingRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, defaultEmbeddingModel, req.Model)
+		requests++
+
+		var resp EmbeddingResponse
+		for i := range req.Input.Texts {
+			resp.Output.Embeddings = append(resp.Output.Embeddings, struct {
+				TextIndex int       `json:"text_index"`
+				Embedding []float32 `json:"embedding"`
+			}{TextIndex: i, Embedding: []float32{float32(requests), float32(i)}})
+		}
+		resp.Usage.TotalTokens = len(req.Input.Texts)
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	p, err := New(map[string]interface{}{"WN_QWEN_APIKEY": "key", "WN_QWEN_ENDPOINT": server.URL})
+	assert.NoError(t, err)
+	embedder := p.(llm.Embedder)
+	assert.Equal(t, 1024, embedder.Dimensions())
+
+	// 超过单次上限的文本分批发送
+	texts := make([]string, 12)
+	vectors, err := embedder.Embed(context.Background(), texts)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, requests)
+	assert.Len(t, vectors, 12)
+	assert.Equal(t, []float32{2, 1}, vectors[11])
+	assert.Equal(t, 2, embedder.Dimensions())
+	assert.Equal(t, llm.Usage{PromptTokens: 12, TotalTokens: 12}, embedder.EmbeddingUsage())
+}
@@ -1,5 +1,7 @@
 package qwen
 
+import "strings"
+
 type QwenRequest struct {
 	Model      string     `json:"model"`
 	Input      Input      `json:"input"`
@@ -12,22 +14,31 @@ type Input struct {
 }
 
 type Parameters struct {
-	Stream            bool     `json:"stream,omitempty"`
-	Temperature       float64  `json:"temperature,omitempty"`
-	TopP              float64  `json:"top_p,omitempty"`
-	TopK              int      `json:"top_k,omitempty"`
-	MaxTokens         int      `json:"max_tokens,omitempty"`
-	Stop              []string `json:"stop,omitempty"`
-	ResultFormat      string   `json:"result_format,omitempty"`
-	IncrementalOutput bool     `json:"incremental_output,omitempty"`
+	Stream            bool        `json:"stream,omitempty"`
+	Temperature       *float64    `json:"temperature,omitempty"`
+	TopP              *float64    `json:"top_p,omitempty"`
+	TopK              int         `json:"top_k,omitempty"`
+	MaxTokens         int         `json:"max_tokens,omitempty"`
+	Stop              []string    `json:"stop,omitempty"`
+	Seed              *int        `json:"seed,omitempty"`
+	PresencePenalty   *float64    `json:"presence_penalty,omitempty"`
+	ToolChoice        interface{} `json:"tool_choice,omitempty"`
+	ResultFormat      string      `json:"result_format,omitempty"`
+	IncrementalOutput bool        `json:"incremental_output,omitempty"`
 }
 
 type Message struct {
-	Role       string     `json:"role"`
-	Content    string     `json:"content"`
-	Name       string     `json:"name,omitempty"`
-	ToolCallId string     `json:"tool_call_id,omitempty"`
-	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
+	Role       string      `json:"role"`
+	Content    interface{} `json:"content"` // string 或多模态接口的 []ContentItem
+	Name       string      `json:"name,omitempty"`
+	ToolCallId string      `json:"tool_call_id,omitempty"`
+	ToolCalls  []ToolCall  `json:"tool_calls,omitempty"`
+}
+
+// ContentItem 多模态消息中的一段内容，Image 为图片地址或 data URL
+type ContentItem struct {
+	Text  string `json:"text,omitempty"`
+	Image string `json:"image,omitempty"`
 }
 
 type Tool struct {
@@ -61,6 +72,35 @@ type Output struct {
 	Text         string     `json:"text"`
 	FinishReason string     `json:"finish_reason"`
 	ToolCalls    []ToolCall `json:"tool_calls,omitempty"`
+	// Choices 多模态接口以 message 格式返回结果
+	Choices []Choice `json:"choices,omitempty"`
+}
+
+type Choice struct {
+	FinishReason string `json:"finish_reason"`
+	Message      struct {
+		Content []ContentItem `json:"content"`
+	} `json:"message"`
+}
+
+// text 返回输出的文本，兼容文本接口和多模态接口
+func (o *Output) text() string {
+	if len(o.Choices) == 0 {
+		return o.Text
+	}
+	var text strings.Builder
+	for _, item := range o.Choices[0].Message.Content {
+		text.WriteString(item.Text)
+	}
+	return text.String()
+}
+
+// finishReason 返回结束原因，兼容文本接口和多模态接口
+func (o *Output) finishReason() string {
+	if len(o.Choices) == 0 {
+		return o.FinishReason
+	}
+	return o.Choices[0].FinishReason
 }
 
 type Usage struct {
@@ -0,0 +1,300 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Route 回退链中的一个后端，Model 为空时使用提供商的默认模型
+type Route struct {
+	Provider string `json:"provider"`
+	Model    string `json:"model,omitempty"`
+}
+
+func (r Route) String() string {
+	if r.Model == "" {
+		return r.Provider
+	}
+	return r.Provider + "/" + r.Model
+}
+
+// ShortPromptRoute 提示词不超过 MaxChars 个字符时优先使用的回退链
+type ShortPromptRoute struct {
+	MaxChars int     `json:"maxChars"`
+	Chain    []Route `json:"chain"`
+}
+
+// RouterConfig 虚拟提供商的配置
+type RouterConfig struct {
+	// Chain 默认回退链，按顺序尝试
+	Chain       []Route           `json:"chain"`
+	ShortPrompt *ShortPromptRoute `json:"shortPrompt,omitempty"`
+	// Tasks 按请求的任务类型优先使用的回退链
+	Tasks map[string][]Route `json:"tasks,omitempty"`
+}
+
+// Router 由多个后端组成的虚拟提供商
+// 后端返回 5xx、429、超时或连接失败时依次尝试下一个后端，优先链失败后继续尝试默认链
+type Router struct {
+	name     string
+	config   RouterConfig
+	create   func(name string) (Provider, error)
+	mu       sync.Mutex
+	backends map[string]Provider
+}
+
+// NewRouter 创建虚拟提供商，create 用于按名称创建后端提供商
+func NewRouter(name string, config RouterConfig, create func(name string) (Provider, error)) (*Router, error) {
+	if len(config.Chain) == 0 {
+		return nil, fmt.Errorf("%s: chain is required", name)
+	}
+	if config.ShortPrompt != nil && (config.ShortPrompt.MaxChars <= 0 || len(config.ShortPrompt.Chain) == 0) {
+		return nil, fmt.Errorf("%s: shortPrompt requires maxChars and chain", name)
+	}
+	for _, route := range config.allRoutes() {
+		if route.Provider == name || !Registered(route.Provider) {
+			return nil, fmt.Errorf("%s: invalid provider %q", name, route.Provider)
+		}
+	}
+
+	return &Router{
+		name:     name,
+		config:   config,
+		create:   create,
+		backends: make(map[string]Provider),
+	}, nil
+}
+
+// allRoutes 返回所有回退链中的后端
+func (c RouterConfig) allRoutes() []Route {
+	routes := append([]Route{}, c.Chain...)
+	if c.ShortPrompt != nil {
+		routes = append(routes, c.ShortPrompt.Chain...)
+	}
+	for _, chain := range c.Tasks {
+		routes = append(routes, chain...)
+	}
+	return routes
+}
+
+// Retryable 判断错误是否应切换到下一个后端
+func Retryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var status interface{ HTTPStatus() int }
+	if errors.As(err, &status) {
+		code := status.HTTPStatus()
+		return code == 429 || code >= 500
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return false
+}
+
+// Complete 依次请求各个后端，直到成功或遇到不可重试的错误
+func (r *Router) Complete(ctx context.Context, req CompletionRequest) (*CompletionResponse, error) {
+	var errs []string
+	for _, route := range r.routes(req) {
+		provider, err := r.backend(route.Provider)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", route, err))
+			continue
+		}
+
+		resp, err := provider.Complete(ctx, route.request(req))
+		if err == nil {
+			route.label(resp, provider)
+			return resp, nil
+		}
+		if ctx.Err() != nil || !Retryable(err) {
+			return nil, err
+		}
+		errs = append(errs, fmt.Sprintf("%s: %v", route, err))
+	}
+	return nil, fmt.Errorf("%s: 所有后端均请求失败: %s", r.name, strings.Join(errs, "; "))
+}
+
+// CompleteStream 依次请求各个后端，已经开始输出后不再切换
+func (r *Router) CompleteStream(ctx context.Context, req CompletionRequest, handler StreamHandler) error {
+	var errs []string
+	for _, route := range r.routes(req) {
+		provider, err := r.backend(route.Provider)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", route, err))
+			continue
+		}
+
+		started := false
+		err = provider.CompleteStream(ctx, route.request(req), func(resp StreamResponse) {
+			started = true
+			if resp.Response != nil {
+				route.label(resp.Response, provider)
+			}
+			handler(resp)
+		})
+		if err == nil {
+			return nil
+		}
+		if started || ctx.Err() != nil || !Retryable(err) {
+			return err
+		}
+		errs = append(errs, fmt.Sprintf("%s: %v", route, err))
+	}
+	return fmt.Errorf("%s: 所有后端均请求失败: %s", r.name, strings.Join(errs, "; "))
+}
+
+// AvailableModels 返回默认回退链中的后端
+func (r *Router) AvailableModels() []string {
+	models := make([]string, 0, len(r.config.Chain))
+	for _, route := range r.config.Chain {
+		models = append(models, route.String())
+	}
+	return models
+}
+
+func (r *Router) GetName() string {
+	return r.name
+}
+
+// SetModel 虚拟提供商的模型由回退链决定，不支持修改
+func (r *Router) SetModel(model string) string {
+	return r.GetModel()
+}
+
+// GetModel 返回默认回退链中的第一个后端
+func (r *Router) GetModel() string {
+	return r.config.Chain[0].String()
+}
+
+// routes 返回本次请求依次尝试的后端，按任务或提示词长度选中的链排在默认链之前
+func (r *Router) routes(req CompletionRequest) []Route {
+	var routes []Route
+	if chain, ok := r.config.Tasks[req.Task]; ok && req.Task != "" {
+		routes = append(routes, chain...)
+	} else if short := r.config.ShortPrompt; short != nil && promptChars(req.Messages) <= short.MaxChars {
+		routes = append(routes, short.Chain...)
+	}
+
+	for _, route := range r.config.Chain {
+		tried := false
+		for _, existing := range routes {
+			if existing == route {
+				tried = true
+				break
+			}
+		}
+		if !tried {
+			routes = append(routes, route)
+		}
+	}
+	return routes
+}
+
+// backend 返回后端提供商，首次使用时创建
+func (r *Router) backend(name string) (Provider, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if provider, ok := r.backends[name]; ok {
+		return provider, nil
+	}
+	provider, err := r.create(name)
+	if err != nil {
+		return nil, err
+	}
+	r.backends[name] = provider
+	return provider, nil
+}
+
+// request 使用后端的模型替换请求中的模型
+func (r Route) request(req CompletionRequest) CompletionRequest {
+	req.Model = r.Model
+	return req
+}
+
+// label 在响应中记录实际的后端，嵌套的虚拟提供商已经填写时保留内层的结果
+func (r Route) label(resp *CompletionResponse, provider Provider) {
+	if resp.Provider == "" {
+		resp.Provider, resp.Model = provider.GetName(), r.model(provider)
+	}
+}
+
+func (r Route) model(provider Provider) string {
+	if r.Model != "" {
+		return r.Model
+	}
+	return provider.GetModel()
+}
+
+func promptChars(messages []Message) int {
+	count := 0
+	for _, msg := range messages {
+		count += utf8.RuneCountInString(msg.Content)
+	}
+	return count
+}
+
+// RegisterRouters 把配置文件中的虚拟提供商注册到 llm，需要在注册其他提供商之后调用
+// 虚拟提供商可以引用其他虚拟提供商，按依赖顺序注册，与注册顺序无关；
+// 与已有提供商重名、配置无效或存在循环引用的虚拟提供商会被跳过，并在返回的错误中列出
+func RegisterRouters(configs map[string]RouterConfig) error {
+	var errs []string
+	pending := make(map[string]RouterConfig, len(configs))
+	for _, name := range sortedNames(configs) {
+		if Registered(name) {
+			errs = append(errs, fmt.Sprintf("提供商 %s 已存在", name))
+			continue
+		}
+		pending[name] = configs[name]
+	}
+
+	for progress := true; progress; {
+		progress = false
+		for _, name := range sortedNames(pending) {
+			config := pending[name]
+			if dependsOnPending(name, config, pending) {
+				continue
+			}
+			delete(pending, name)
+			progress = true
+			if _, err := NewRouter(name, config, nil); err != nil {
+				errs = append(errs, err.Error())
+				continue
+			}
+			Register(name, func(options map[string]interface{}) (Provider, error) {
+				return NewRouter(name, config, func(backend string) (Provider, error) {
+					return CreateProvider(backend, options)
+				})
+			})
+		}
+	}
+	for _, name := range sortedNames(pending) {
+		errs = append(errs, fmt.Sprintf("%s: 回退链存在循环引用", name))
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// dependsOnPending 判断虚拟提供商是否引用了尚未注册的其他虚拟提供商
+func dependsOnPending(name string, config RouterConfig, pending map[string]RouterConfig) bool {
+	for _, route := range config.allRoutes() {
+		if _, ok := pending[route.Provider]; ok && route.Provider != name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,175 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type statusError int
+
+func (e statusError) Error() string   { return fmt.Sprintf("status %d", int(e)) }
+func (e statusError) HTTPStatus() int { return int(e) }
+
+// routeProvider 按名称返回预设错误的测试提供商，记录收到的请求
+type routeProvider struct {
+	mockProvider
+	name     string
+	err      error
+	requests []CompletionRequest
+}
+
+func (p *routeProvider) GetName() string { return p.name }
+
+func (p *routeProvider) Complete(ctx context.Context, req CompletionRequest) (*CompletionResponse, error) {
+	p.requests = append(p.requests, req)
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &CompletionResponse{Content: p.name}, nil
+}
+
+func (p *routeProvider) CompleteStream(ctx context.Context, req CompletionRequest, handler StreamHandler) error {
+	p.requests = append(p.requests, req)
+	if p.err != nil {
+		return p.err
+	}
+	handler(StreamResponse{Content: p.name})
+	handler(StreamResponse{Done: true, Response: &CompletionResponse{Content: p.name}})
+	return nil
+}
+
+func newTestRouter(t *testing.T, config RouterConfig, backends ...*routeProvider) *Router {
+	for _, backend := range backends {
+		if !Registered(backend.name) {
+			Register(backend.name, func(map[string]interface{}) (Provider, error) { return nil, nil })
+		}
+	}
+	router, err := NewRouter("router", config, func(name string) (Provider, error) {
+		for _, backend := range backends {
+			if backend.name == name {
+				return backend, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown %s", name)
+	})
+	assert.NoError(t, err)
+	return router
+}
+
+func TestRetryable(t *testing.T) {
+	assert.True(t, Retryable(statusError(500)))
+	assert.True(t, Retryable(fmt.Errorf("wrap: %w", statusError(429))))
+	assert.True(t, Retryable(context.DeadlineExceeded))
+	assert.False(t, Retryable(statusError(400)))
+	assert.False(t, Retryable(errors.New("bad request")))
+	assert.False(t, Retryable(nil))
+}
+
+func TestRouterFallback(t *testing.T) {
+	primary := &routeProvider{name: "route-primary", err: statusError(503)}
+	backup := &routeProvider{name: "route-backup"}
+	router := newTestRouter(t, RouterConfig{
+		Chain: []Route{{Provider: "route-primary", Model: "big"}, {Provider: "route-backup", Model: "small"}},
+	}, primary, backup)
+
+	resp, err := router.Complete(context.Background(), CompletionRequest{Model: "ignored"})
+	assert.NoError(t, err)
+	assert.Equal(t, "route-backup", resp.Provider)
+	assert.Equal(t, "small", resp.Model)
+	assert.Equal(t, "big", primary.requests[0].Model)
+
+	var final *CompletionResponse
+	err = router.CompleteStream(context.Background(), CompletionRequest{}, func(resp StreamResponse) {
+		if resp.Done {
+			final = resp.Response
+		}
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "route-backup", final.Provider)
+
+	// 不可重试的错误直接返回
+	primary.err = statusError(401)
+	_, err = router.Complete(context.Background(), CompletionRequest{})
+	assert.Equal(t, statusError(401), err)
+
+	backup.err = statusError(429)
+	primary.err = statusError(500)
+	_, err = router.Complete(context.Background(), CompletionRequest{})
+	assert.ErrorContains(t, err, "route-primary/big")
+	assert.ErrorContains(t, err, "route-backup/small")
+}
+
+func TestRouterRoutes(t *testing.T) {
+	router := &Router{config: RouterConfig{
+		Chain:       []Route{{Provider: "a"}, {Provider: "b"}},
+		ShortPrompt: &ShortPromptRoute{MaxChars: 5, Chain: []Route{{Provider: "b", Model: "mini"}}},
+		Tasks:       map[string][]Route{TaskAnalysis: {{Provider: "b"}}},
+	}}
+
+	short := CompletionRequest{Messages: []Message{{Role: "user", Content: "你好"}}}
+	assert.Equal(t, []Route{{Provider: "b", Model: "mini"}, {Provider: "a"}, {Provider: "b"}}, router.routes(short))
+
+	long := CompletionRequest{Messages: []Message{{Role: "user", Content: "hello world"}}}
+	assert.Equal(t, []Route{{Provider: "a"}, {Provider: "b"}}, router.routes(long))
+
+	short.Task = TaskAnalysis
+	assert.Equal(t, []Route{{Provider: "b"}, {Provider: "a"}}, router.routes(short))
+}
+
+func TestRegisterRouters(t *testing.T) {
+	Register("route-base", func(map[string]interface{}) (Provider, error) {
+		return &routeProvider{name: "route-base"}, nil
+	})
+
+	err := RegisterRouters(map[string]RouterConfig{
+		"route-virtual": {Chain: []Route{{Provider: "route-base", Model: "m1"}}},
+		"route-missing": {Chain: []Route{{Provider: "route-none"}}},
+		"route-empty":   {},
+	})
+	assert.ErrorContains(t, err, "route-missing")
+	assert.ErrorContains(t, err, "route-empty")
+	assert.False(t, Registered("route-missing"))
+
+	provider, err := CreateProvider("route-virtual", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "route-virtual", provider.GetName())
+	assert.Equal(t, []string{"route-base/m1"}, provider.AvailableModels())
+
+	resp, err := provider.Complete(context.Background(), CompletionRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, "route-base", resp.Provider)
+	assert.Equal(t, "m1", resp.Model)
+
+	assert.NoError(t, RegisterRouters(nil))
+}
+
+func TestRegisterRouters_Nested(t *testing.T) {
+	Register("nested-base", func(map[string]interface{}) (Provider, error) {
+		return &routeProvider{name: "nested-base"}, nil
+	})
+
+	// a-outer 排在 z-inner 之前，仍然可以引用它
+	err := RegisterRouters(map[string]RouterConfig{
+		"a-outer":   {Chain: []Route{{Provider: "z-inner"}}},
+		"z-inner":   {Chain: []Route{{Provider: "nested-base"}}},
+		"cycle-a":   {Chain: []Route{{Provider: "cycle-b"}}},
+		"cycle-b":   {Chain: []Route{{Provider: "cycle-a"}}},
+		"b-broken":  {Chain: []Route{{Provider: "y-invalid"}}},
+		"y-invalid": {Chain: []Route{{Provider: "nested-none"}}},
+	})
+	assert.ErrorContains(t, err, "cycle-a: 回退链存在循环引用")
+	assert.ErrorContains(t, err, "cycle-b: 回退链存在循环引用")
+	assert.ErrorContains(t, err, `b-broken: invalid provider "y-invalid"`)
+	assert.False(t, Registered("cycle-a"))
+	assert.False(t, Registered("b-broken"))
+
+	provider, err := CreateProvider("a-outer", nil)
+	assert.NoError(t, err)
+	resp, err := provider.Complete(context.Background(), CompletionRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, "nested-base", resp.Provider)
+}
@@ -0,0 +1,142 @@
+package llm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 采样参数名称，与 CompletionRequest 的 JSON 字段一致
+const (
+	ParamTemperature      = "temperature"
+	ParamTopP             = "top_p"
+	ParamStop             = "stop"
+	ParamSeed             = "seed"
+	ParamPresencePenalty  = "presence_penalty"
+	ParamFrequencyPenalty = "frequency_penalty"
+	ParamToolChoice       = "tool_choice"
+)
+
+// SamplingParamNames 所有采样参数的名称
+var SamplingParamNames = []string{
+	ParamTemperature,
+	ParamTopP,
+	ParamStop,
+	ParamSeed,
+	ParamPresencePenalty,
+	ParamFrequencyPenalty,
+	ParamToolChoice,
+}
+
+// 常用的 ToolChoice 取值，其他值表示必须调用的工具名称
+const (
+	ToolChoiceAuto     = "auto"
+	ToolChoiceNone     = "none"
+	ToolChoiceRequired = "required"
+)
+
+// IsSet 判断参数是否已设置
+func (p SamplingParams) IsSet(name string) bool {
+	switch name {
+	case ParamTemperature:
+		return p.Temperature != nil
+	case ParamTopP:
+		return p.TopP != nil
+	case ParamStop:
+		return len(p.Stop) > 0
+	case ParamSeed:
+		return p.Seed != nil
+	case ParamPresencePenalty:
+		return p.PresencePenalty != nil
+	case ParamFrequencyPenalty:
+		return p.FrequencyPenalty != nil
+	case ParamToolChoice:
+		return p.ToolChoice != ""
+	}
+	return false
+}
+
+// Reject 设置了提供商不支持的参数时返回错误，避免参数被静默忽略
+func (p SamplingParams) Reject(provider string, names ...string) error {
+	var set []string
+	for _, name := range names {
+		if p.IsSet(name) {
+			set = append(set, name)
+		}
+	}
+	if len(set) > 0 {
+		return fmt.Errorf("%s: unsupported parameters: %s", provider, strings.Join(set, ", "))
+	}
+	return nil
+}
+
+// Merge 返回用 override 中已设置的参数覆盖后的结果
+func (p SamplingParams) Merge(override SamplingParams) SamplingParams {
+	if override.Temperature != nil {
+		p.Temperature = override.Temperature
+	}
+	if override.TopP != nil {
+		p.TopP = override.TopP
+	}
+	if len(override.Stop) > 0 {
+		p.Stop = override.Stop
+	}
+	if override.Seed != nil {
+		p.Seed = override.Seed
+	}
+	if override.PresencePenalty != nil {
+		p.PresencePenalty = override.PresencePenalty
+	}
+	if override.FrequencyPenalty != nil {
+		p.FrequencyPenalty = override.FrequencyPenalty
+	}
+	if override.ToolChoice != "" {
+		p.ToolChoice = override.ToolChoice
+	}
+	return p
+}
+
+// ParseSamplingParams 从名称到字符串值的映射中解析采样参数，stop 以逗号分隔，空值会被忽略
+func ParseSamplingParams(values map[string]string) (SamplingParams, error) {
+	var p SamplingParams
+	for name, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		var err error
+		switch name {
+		case ParamTemperature:
+			p.Temperature, err = parseFloat(value)
+		case ParamTopP:
+			p.TopP, err = parseFloat(value)
+		case ParamPresencePenalty:
+			p.PresencePenalty, err = parseFloat(value)
+		case ParamFrequencyPenalty:
+			p.FrequencyPenalty, err = parseFloat(value)
+		case ParamSeed:
+			var seed int
+			seed, err = strconv.Atoi(value)
+			p.Seed = &seed
+		case ParamStop:
+			p.Stop = strings.Split(value, ",")
+		case ParamToolChoice:
+			p.ToolChoice = value
+		default:
+			return p, fmt.Errorf("unknown sampling parameter: %s", name)
+		}
+		if err != nil {
+			return p, fmt.Errorf("invalid %s %q: %w", name, value, err)
+		}
+	}
+	return p, nil
+}
+
+func parseFloat(value string) (*float64, error) {
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
@@ -0,0 +1,51 @@
+package llm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSamplingParams(t *testing.T) {
+	params, err := ParseSamplingParams(map[string]string{
+		ParamTemperature:      "0",
+		ParamTopP:             "0.9",
+		ParamStop:             "END,STOP",
+		ParamSeed:             "42",
+		ParamToolChoice:       "auto",
+		ParamFrequencyPenalty: "",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, *params.Temperature)
+	assert.Equal(t, 0.9, *params.TopP)
+	assert.Equal(t, []string{"END", "STOP"}, params.Stop)
+	assert.Equal(t, 42, *params.Seed)
+	assert.Equal(t, "auto", params.ToolChoice)
+	assert.Nil(t, params.FrequencyPenalty)
+
+	_, err = ParseSamplingParams(map[string]string{ParamSeed: "abc"})
+	assert.Error(t, err)
+	_, err = ParseSamplingParams(map[string]string{"top_k": "1"})
+	assert.Error(t, err)
+}
+
+func TestSamplingParamsMerge(t *testing.T) {
+	zero, half, one := 0.0, 0.5, 1.0
+	base := SamplingParams{Temperature: &one, TopP: &half, ToolChoice: "auto"}
+	merged := base.Merge(SamplingParams{Temperature: &zero, Stop: []string{"x"}})
+
+	assert.Equal(t, &zero, merged.Temperature)
+	assert.Equal(t, &half, merged.TopP)
+	assert.Equal(t, []string{"x"}, merged.Stop)
+	assert.Equal(t, "auto", merged.ToolChoice)
+	assert.Equal(t, &one, base.Temperature)
+}
+
+func TestSamplingParamsReject(t *testing.T) {
+	seed := 1
+	params := SamplingParams{Seed: &seed, Stop: []string{"x"}}
+	assert.NoError(t, params.Reject("test", ParamTemperature, ParamPresencePenalty))
+
+	err := params.Reject("test", ParamSeed, ParamStop, ParamTopP)
+	assert.EqualError(t, err, "test: unsupported parameters: seed, stop")
+}
@@ -13,6 +13,28 @@ type Message struct {
 	Name       string     `json:"name,omitempty"`
 	ToolCallId string     `json:"tool_call_id,omitempty"`
 	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
+	// Parts 按顺序排列的结构化内容，Content 是它的文本形式，图片等二进制内容在 Content 中以占位符表示
+	Parts []ContentPart `json:"parts,omitempty"`
+	// IsError 标记工具调用结果是否为错误
+	IsError bool `json:"is_error,omitempty"`
+}
+
+// 消息内容片段的类型
+const (
+	ContentTypeText  = "text"
+	ContentTypeImage = "image"
+	ContentTypeFile  = "file"
+)
+
+// ContentPart 表示消息中的一段内容，图片和文件以 base64 编码保存在 Data 中，远程图片通过 URL 引用
+type ContentPart struct {
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	MIMEType string `json:"mime_type,omitempty"`
+	Data     string `json:"data,omitempty"`
+	URL      string `json:"url,omitempty"`
+	// Name 文件名，只用于文件片段
+	Name string `json:"name,omitempty"`
 }
 
 // CompletionRequest 表示请求大模型的参数
@@ -22,6 +44,29 @@ type CompletionRequest struct {
 	Model          string     `json:"model,omitempty"`
 	ResponseFormat string     `json:"response_format,omitempty"`
 	Tools          []mcp.Tool `json:"tools,omitempty"`
+	// JSONSchema 要求按 schema 返回 JSON，支持的提供商优先于 ResponseFormat 使用
+	JSONSchema *JSONSchema `json:"json_schema,omitempty"`
+	// Task 请求的任务类型，虚拟提供商据此选择回退链
+	Task string `json:"task,omitempty"`
+	SamplingParams
+}
+
+// 请求的任务类型
+const (
+	TaskChat     = "chat"
+	TaskAnalysis = "analysis"
+)
+
+// SamplingParams 采样参数，未设置的参数使用模型的默认值
+type SamplingParams struct {
+	Temperature      *float64 `json:"temperature,omitempty" yaml:"temperature,omitempty"`
+	TopP             *float64 `json:"top_p,omitempty" yaml:"top_p,omitempty"`
+	Stop             []string `json:"stop,omitempty" yaml:"stop,omitempty"`
+	Seed             *int     `json:"seed,omitempty" yaml:"seed,omitempty"`
+	PresencePenalty  *float64 `json:"presence_penalty,omitempty" yaml:"presence_penalty,omitempty"`
+	FrequencyPenalty *float64 `json:"frequency_penalty,omitempty" yaml:"frequency_penalty,omitempty"`
+	// ToolChoice 取值为 auto、none、required 或工具名称
+	ToolChoice string `json:"tool_choice,omitempty" yaml:"tool_choice,omitempty"`
 }
 
 // CompletionResponse 表示大模型的响应
@@ -30,6 +75,11 @@ type CompletionResponse struct {
 	FinishReason string     `json:"finish_reason"`
 	Usage        Usage      `json:"usage"`
 	ToolCalls    []ToolCall `json:"tool_calls,omitempty"`
+	// Reasoning 推理模型的思考过程，与 Content 分开返回
+	Reasoning string `json:"reasoning,omitempty"`
+	// Provider 和 Model 记录实际响应的后端，由虚拟提供商填写
+	Provider string `json:"provider,omitempty"`
+	Model    string `json:"model,omitempty"`
 }
 
 // ToolCall 表示工具调用的信息
@@ -45,11 +95,15 @@ type Usage struct {
 	PromptTokens     int `json:"prompt_tokens"`
 	CompletionTokens int `json:"completion_tokens"`
 	TotalTokens      int `json:"total_tokens"`
+	// ReasoningTokens 思考过程消耗的 token 数，已包含在 CompletionTokens 中
+	ReasoningTokens int `json:"reasoning_tokens,omitempty"`
 }
 
 // StreamResponse 定义流式响应的结构
 type StreamResponse struct {
-	Content      string
+	Content string
+	// Reasoning 思考过程的增量，与 Content 不会同时出现
+	Reasoning    string
 	FinishReason string
 	Done         bool
 	Response     *CompletionResponse
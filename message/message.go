@@ -35,6 +35,15 @@ func (m *Manager) GetAll() []llm.Message {
 	return messages
 }
 
+// RemoveLast 删除最后一条消息
+func (m *Manager) RemoveLast() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if len(m.messages) > 0 {
+		m.messages = m.messages[:len(m.messages)-1]
+	}
+}
+
 // Clear 清空所有消息
 func (m *Manager) Clear() {
 	m.mutex.Lock()
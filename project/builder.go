@@ -28,10 +28,36 @@ var excludedDirs = map[string]bool{
 // BuildProjectTree 构建项目树
 func BuildProjectTree(targetPath string, options helper.WalkDirOptions) (*Project, error) {
 	doc := NewProject(targetPath)
+
+	err := walkProject(targetPath, options, func(path, relPath string, info os.FileInfo) error {
+		if info.IsDir() {
+			// 创建目录节点
+			return doc.CreateDir(relPath, info)
+		}
+
+		// 读取文件内容
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil // 跳过无法读取的文件
+		}
+
+		// 创建文件节点
+		return doc.CreateFile(relPath, content, info)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// walkProject 遍历目录，跳过被排除的目录和文件，对其余目录和文件调用 fn
+func walkProject(targetPath string, options helper.WalkDirOptions, fn func(path, relPath string, info os.FileInfo) error) error {
 	gitignoreRules := make(map[string][]string)
 	targetPath = filepath.Clean(targetPath)
 
-	err := filepath.Walk(targetPath, func(path string, info os.FileInfo, err error) error {
+	return filepath.Walk(targetPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
@@ -86,8 +112,7 @@ func BuildProjectTree(targetPath string, options helper.WalkDirOptions) (*Projec
 			if info.Name() == "." {
 				return nil
 			}
-			// 创建目录节点
-			return doc.CreateDir(relPath, info)
+			return fn(path, relPath, info)
 		}
 
 		// 检查文件扩展名
@@ -106,19 +131,6 @@ func BuildProjectTree(targetPath string, options helper.WalkDirOptions) (*Projec
 			return nil
 		}
 
-		// 读取文件内容
-		content, err := os.ReadFile(path)
-		if err != nil {
-			return nil // 跳过无法读取的文件
-		}
-
-		// 创建文件节点
-		return doc.CreateFile(relPath, content, info)
+		return fn(path, relPath, info)
 	})
-
-	if err != nil {
-		return nil, err
-	}
-
-	return doc, nil
 }
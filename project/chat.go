@@ -3,8 +3,8 @@ package project
 import (
 	"context"
 	"fmt"
-	"strings"
 
+	"github.com/sjzsdu/wn/agent"
 	"github.com/sjzsdu/wn/data"
 	"github.com/sjzsdu/wn/helper"
 	"github.com/sjzsdu/wn/llm"
@@ -16,11 +16,15 @@ type Chatter interface {
 	ChatWithLLM() error
 }
 
+// jsonRepairRetries 响应校验失败时要求模型修正的最多次数
+const jsonRepairRetries = 2
+
 // BaseChatter 提供了基本的聊天功能
 type BaseChatter struct {
-	project *Project
-	cache   *data.CacheManager
-	llm     llm.Provider
+	project  *Project
+	cache    *data.CacheManager
+	llm      llm.Provider
+	sampling llm.SamplingParams
 }
 
 // NewBaseChatter 创建一个基本聊天器
@@ -32,6 +36,11 @@ func NewBaseChatter(p *Project) *BaseChatter {
 	}
 }
 
+// SetSamplingParams 设置覆盖 agent 元数据和全局配置的采样参数，如批量分析时固定 temperature 和 seed
+func (b *BaseChatter) SetSamplingParams(params llm.SamplingParams) {
+	b.sampling = params
+}
+
 // VisitDirectory 实现通用的目录访问逻辑
 func (b *BaseChatter) VisitDirectory(node *Node, path string, level int) error {
 	path = b.project.GetAbsolutePath(path)
@@ -64,7 +73,7 @@ func (b *BaseChatter) VisitDirectory(node *Node, path string, level int) error {
 		Role:    "user",
 		Content: childrenResponses,
 	})
-	resContent, err := b.ensureValidJSONResponse(context.Background(), messages)
+	resContent, err := b.ensureValidJSONResponse(context.Background(), "directory", messages)
 	if err != nil {
 		fmt.Printf("Directory:%s, %s", path, resContent)
 		return err
@@ -102,7 +111,7 @@ func (b *BaseChatter) VisitFile(node *Node, path string, level int) error {
 		Role:    "user",
 		Content: string(node.Content),
 	})
-	resContent, err := b.ensureValidJSONResponse(context.Background(), messages)
+	resContent, err := b.ensureValidJSONResponse(context.Background(), "file", messages)
 	if err != nil {
 		fmt.Printf("File:%s, %s", path, resContent)
 		return err
@@ -113,32 +122,24 @@ func (b *BaseChatter) VisitFile(node *Node, path string, level int) error {
 	return nil
 }
 
-// ensureValidJSONResponse 确保获取到有效的JSON响应
-func (b *BaseChatter) ensureValidJSONResponse(ctx context.Context, messages []llm.Message) (string, error) {
-	req := llm.CompletionRequest{
-		Messages: messages,
-	}
-
-	resp, err := b.llm.Complete(ctx, req)
+// ensureValidJSONResponse 确保获取到有效的JSON响应，使用 agentName 对应的采样参数，
+// 响应不符合 LLMResponse 的结构时要求模型修正
+func (b *BaseChatter) ensureValidJSONResponse(ctx context.Context, agentName string, messages []llm.Message) (string, error) {
+	sampling, err := agent.GetSamplingParams(agentName, b.sampling)
 	if err != nil {
 		return "", err
 	}
-
-	// 处理返回内容中的 JSON 代码块
-	content := resp.Content
-	if strings.Contains(content, "```json") && strings.Contains(content, "```") {
-		// 提取 ```json 和 ``` 之间的内容
-		start := strings.Index(content, "```json") + 7
-		end := strings.LastIndex(content, "```")
-		if start < end {
-			content = strings.TrimSpace(content[start:end])
-		}
-	}
-	if _, err := NewLLMResponse(content); err != nil {
-		return "", fmt.Errorf("无效的 JSON 响应: %v, %s", err, content)
+	req := llm.CompletionRequest{
+		Messages:       messages,
+		Task:           llm.TaskAnalysis,
+		SamplingParams: sampling,
 	}
 
-	return content, nil
+	resp, err := llm.CompleteJSON[LLMResponse](ctx, b.llm, req, jsonRepairRetries)
+	if err != nil {
+		return "", fmt.Errorf("无效的 JSON 响应: %w", err)
+	}
+	return resp.ToJSON()
 }
 
 func (b *BaseChatter) ChatWithLLM() error {
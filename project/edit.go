@@ -0,0 +1,36 @@
+package project
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Edit 一次查找替换编辑
+type Edit struct {
+	OldText    string `json:"oldText"`
+	NewText    string `json:"newText"`
+	ReplaceAll bool   `json:"replaceAll,omitempty"`
+}
+
+// ApplyEdits 依次将编辑应用到内容上
+// OldText 必须存在，且在未设置 ReplaceAll 时只能出现一次，避免替换到错误的位置
+func ApplyEdits(content string, edits []Edit) (string, error) {
+	for i, edit := range edits {
+		if edit.OldText == "" {
+			return "", fmt.Errorf("edit %d: oldText is empty", i+1)
+		}
+		count := strings.Count(content, edit.OldText)
+		switch {
+		case count == 0:
+			return "", fmt.Errorf("edit %d: oldText not found", i+1)
+		case count > 1 && !edit.ReplaceAll:
+			return "", fmt.Errorf("edit %d: oldText matches %d times, add more context or set replaceAll", i+1, count)
+		}
+		if edit.ReplaceAll {
+			content = strings.ReplaceAll(content, edit.OldText, edit.NewText)
+		} else {
+			content = strings.Replace(content, edit.OldText, edit.NewText, 1)
+		}
+	}
+	return content, nil
+}
@@ -33,15 +33,15 @@ type Method struct {
 type Class struct {
 	Name      string     `json:"name"`
 	Feature   string     `json:"feature"`
-	Variables []Variable `json:"variables"`
-	Methods   []Method   `json:"methods"`
+	Variables []Variable `json:"variables" jsonschema:"optional"`
+	Methods   []Method   `json:"methods" jsonschema:"optional"`
 }
 
 // Interface 表示接口信息
 type Interface struct {
 	Name    string   `json:"name"`
 	Feature string   `json:"feature"`
-	Methods []Method `json:"methods"`
+	Methods []Method `json:"methods" jsonschema:"optional"`
 }
 
 // Symbol 表示其他符号信息
@@ -51,13 +51,13 @@ type Symbol struct {
 	Feature string `json:"feature"`
 }
 
-// LLMResponse 表示 LLM 响应的完整结构
+// LLMResponse 表示 LLM 响应的完整结构，没有对应符号的列表在 schema 中可以省略
 type LLMResponse struct {
-	Functions    []Function  `json:"functions"`
-	Classes      []Class     `json:"classes"`
-	Interfaces   []Interface `json:"interfaces"`
-	Variables    []Variable  `json:"variables"`
-	OtherSymbols []Symbol    `json:"other_symbols"`
+	Functions    []Function  `json:"functions" jsonschema:"optional"`
+	Classes      []Class     `json:"classes" jsonschema:"optional"`
+	Interfaces   []Interface `json:"interfaces" jsonschema:"optional"`
+	Variables    []Variable  `json:"variables" jsonschema:"optional"`
+	OtherSymbols []Symbol    `json:"other_symbols" jsonschema:"optional"`
 	Feature      string      `json:"feature"`
 }
 
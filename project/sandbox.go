@@ -0,0 +1,145 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ResolvePath 将路径解析为项目根目录下的绝对路径和相对路径
+// 通过 .. 或符号链接逃逸出项目根目录的路径会被拒绝
+func (p *Project) ResolvePath(path string) (string, string, error) {
+	root, err := filepath.Abs(p.rootPath)
+	if err != nil {
+		return "", "", err
+	}
+
+	var abs string
+	if filepath.IsAbs(path) {
+		abs = filepath.Clean(path)
+	} else {
+		abs = filepath.Join(root, path)
+	}
+
+	rel, err := filepath.Rel(root, abs)
+	if err != nil || isOutside(rel) {
+		return "", "", fmt.Errorf("路径超出项目目录: %s", path)
+	}
+
+	// 找到最深的已存在路径，解析其中的符号链接后再次检查
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", "", err
+	}
+	existing := abs
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		existing = parent
+	}
+	realPath, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		return "", "", err
+	}
+	realRel, err := filepath.Rel(realRoot, realPath)
+	if err != nil || isOutside(realRel) {
+		return "", "", fmt.Errorf("路径通过符号链接超出项目目录: %s", path)
+	}
+
+	return abs, rel, nil
+}
+
+// SaveFile 将内容写入磁盘并同步更新项目树中的节点，节点及其上级目录的分析结果会被清除
+// 不存在的文件及其上级目录会被创建
+func (p *Project) SaveFile(path string, content []byte) error {
+	abs, rel, err := p.ResolvePath(path)
+	if err != nil {
+		return err
+	}
+	if rel == "." {
+		return fmt.Errorf("cannot write to directory")
+	}
+
+	perm := os.FileMode(0644)
+	if info, err := os.Stat(abs); err == nil {
+		if info.IsDir() {
+			return fmt.Errorf("cannot write to directory")
+		}
+		perm = info.Mode().Perm()
+	}
+
+	if err := os.MkdirAll(filepath.Dir(abs), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(abs, content, perm); err != nil {
+		return err
+	}
+
+	info, err := os.Stat(abs)
+	if err != nil {
+		return err
+	}
+	if err := p.upsertFile(rel, content, info); err != nil {
+		return err
+	}
+	p.invalidate(rel)
+	return nil
+}
+
+// upsertFile 更新已存在的文件节点，或者连同上级目录一起创建新节点
+func (p *Project) upsertFile(rel string, content []byte, info os.FileInfo) error {
+	if node := p.nodeAt(rel); node != nil {
+		if err := p.WriteFile(rel, content); err != nil {
+			return err
+		}
+		node.mu.Lock()
+		node.Info = info
+		node.mu.Unlock()
+		return nil
+	}
+
+	if err := p.ensureDir(filepath.Dir(rel)); err != nil {
+		return err
+	}
+	return p.CreateFile(rel, content, info)
+}
+
+// ensureDir 创建目录节点及其缺失的上级目录节点
+func (p *Project) ensureDir(dir string) error {
+	if dir == "." || dir == "" {
+		return nil
+	}
+	current := ""
+	for _, comp := range strings.Split(dir, string(filepath.Separator)) {
+		current = filepath.Join(current, comp)
+		if p.nodeAt(current) != nil {
+			continue
+		}
+		dirInfo, _ := os.Stat(p.GetAbsolutePath(current))
+		if err := p.CreateDir(current, dirInfo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nodeAt 返回路径对应的节点，不存在时返回 nil
+func (p *Project) nodeAt(path string) *Node {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	node, err := p.findNode(path)
+	if err != nil {
+		return nil
+	}
+	return node
+}
+
+func isOutside(rel string) bool {
+	return rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,66 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProject_ResolvePath(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	require.NoError(t, os.Symlink(outside, filepath.Join(root, "link")))
+
+	p := NewProject(root)
+
+	abs, rel, err := p.ResolvePath("src/main.go")
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "src", "main.go"), abs)
+	assert.Equal(t, filepath.Join("src", "main.go"), rel)
+
+	_, _, err = p.ResolvePath("../secret.txt")
+	assert.Error(t, err)
+
+	_, _, err = p.ResolvePath(filepath.Join(outside, "a.txt"))
+	assert.Error(t, err)
+
+	_, _, err = p.ResolvePath("link/a.txt")
+	assert.Error(t, err)
+}
+
+func TestProject_SaveFile(t *testing.T) {
+	root := t.TempDir()
+	p := NewProject(root)
+
+	require.NoError(t, p.SaveFile("a/b/c.txt", []byte("hello")))
+
+	data, err := os.ReadFile(filepath.Join(root, "a", "b", "c.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+
+	content, err := p.ReadFile("a/b/c.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", string(content))
+
+	// 写入后清除文件及其上级目录的分析结果
+	dir, err := p.FindNode("a")
+	require.NoError(t, err)
+	file, err := p.FindNode("a/b/c.txt")
+	require.NoError(t, err)
+	p.root.LLMResponse = &LLMResponse{Feature: "root"}
+	dir.LLMResponse = &LLMResponse{Feature: "dir"}
+	file.LLMResponse = &LLMResponse{Feature: "file"}
+
+	require.NoError(t, p.SaveFile("a/b/c.txt", []byte("world")))
+	content, err = p.ReadFile("a/b/c.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "world", string(content))
+	assert.Nil(t, p.root.LLMResponse)
+	assert.Nil(t, dir.LLMResponse)
+	assert.Nil(t, file.LLMResponse)
+
+	assert.Error(t, p.SaveFile("../escape.txt", []byte("x")))
+}
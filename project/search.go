@@ -0,0 +1,222 @@
+package project
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/sjzsdu/wn/data"
+	"github.com/sjzsdu/wn/helper"
+)
+
+// Entry 目录列表中的一项
+type Entry struct {
+	Path  string `json:"path"`
+	IsDir bool   `json:"isDir"`
+	Size  int    `json:"size,omitempty"`
+}
+
+// GrepOptions 内容搜索选项
+type GrepOptions struct {
+	Pattern    string   // 正则表达式
+	Include    []string // 只搜索匹配这些 glob 的文件
+	Exclude    []string // 跳过匹配这些 glob 的文件
+	Context    int      // 匹配行前后的上下文行数
+	IgnoreCase bool     // 忽略大小写
+}
+
+// GrepMatch 一处匹配结果，Line 从 1 开始
+type GrepMatch struct {
+	Path   string   `json:"path"`
+	Line   int      `json:"line"`
+	Text   string   `json:"text"`
+	Before []string `json:"before,omitempty"`
+	After  []string `json:"after,omitempty"`
+}
+
+// FindNode 返回路径对应的节点
+func (p *Project) FindNode(path string) (*Node, error) {
+	if filepath.Clean(path) == "." {
+		path = "/"
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.findNode(path)
+}
+
+// ListDir 列出目录下 depth 层以内的文件和子目录，depth 小于 1 时按 1 处理
+func (p *Project) ListDir(path string, depth int) ([]Entry, error) {
+	node, err := p.FindNode(path)
+	if err != nil {
+		return nil, err
+	}
+	if !node.IsDir {
+		return nil, fmt.Errorf("not a directory: %s", path)
+	}
+	if depth < 1 {
+		depth = 1
+	}
+
+	var entries []Entry
+	var walk func(n *Node, dir string, level int)
+	walk = func(n *Node, dir string, level int) {
+		for _, child := range sortedChildren(n) {
+			childPath := filepath.Join(dir, child.Name)
+			entry := Entry{Path: slashPath(childPath), IsDir: child.IsDir}
+			if !child.IsDir {
+				entry.Size = len(child.Content)
+			}
+			entries = append(entries, entry)
+			if child.IsDir && level < depth {
+				walk(child, childPath, level+1)
+			}
+		}
+	}
+	walk(node, path, 1)
+	return entries, nil
+}
+
+// Glob 返回匹配模式的所有文件路径
+func (p *Project) Glob(pattern string) ([]string, error) {
+	files, err := p.GetAllFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []string
+	for _, file := range files {
+		if helper.MatchGlob(pattern, file) {
+			matched = append(matched, slashPath(file))
+		}
+	}
+	return matched, nil
+}
+
+// Grep 按正则表达式搜索文件内容，跳过二进制文件
+func (p *Project) Grep(opts GrepOptions) ([]GrepMatch, error) {
+	pattern := opts.Pattern
+	if opts.IgnoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %v", err)
+	}
+
+	var matches []GrepMatch
+	traverser := NewTreeTraverser(p)
+	visitor := VisitorFunc(func(path string, node *Node, depth int) error {
+		if node.IsDir || !includePath(path, opts.Include, opts.Exclude) {
+			return nil
+		}
+
+		node.mu.RLock()
+		content := node.Content
+		node.mu.RUnlock()
+		if isBinary(content) {
+			return nil
+		}
+
+		lines := splitLines(content)
+		for i, line := range lines {
+			if !re.MatchString(line) {
+				continue
+			}
+			match := GrepMatch{Path: slashPath(path), Line: i + 1, Text: line}
+			if opts.Context > 0 {
+				match.Before = lines[max(0, i-opts.Context):i]
+				match.After = lines[i+1 : min(len(lines), i+1+opts.Context)]
+			}
+			matches = append(matches, match)
+		}
+		return nil
+	})
+	if err := traverser.TraverseTree(visitor); err != nil {
+		return nil, err
+	}
+	return matches, nil
+}
+
+// CachedLLMResponse 返回节点的 LLM 分析结果
+// 节点上没有结果时从缓存中按内容哈希查找，不会调用大模型
+func (p *Project) CachedLLMResponse(path string) (*LLMResponse, error) {
+	node, err := p.FindNode(path)
+	if err != nil {
+		return nil, err
+	}
+	if node.LLMResponse != nil {
+		return node.LLMResponse, nil
+	}
+
+	cache := data.GetDefaultCacheManager()
+	if cache == nil {
+		return nil, nil
+	}
+	hash, err := node.CalculateHash()
+	if err != nil {
+		return nil, err
+	}
+	content, found, err := cache.FindContent(p.GetAbsolutePath("/"+strings.TrimPrefix(path, "/")), hash)
+	if err != nil || !found {
+		return nil, err
+	}
+	node.SetLLMResponse(content)
+	return node.LLMResponse, nil
+}
+
+func includePath(path string, include, exclude []string) bool {
+	for _, pattern := range exclude {
+		if helper.MatchGlob(pattern, path) {
+			return false
+		}
+	}
+	if len(include) == 0 {
+		return true
+	}
+	for _, pattern := range include {
+		if helper.MatchGlob(pattern, path) {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedChildren(node *Node) []*Node {
+	node.mu.RLock()
+	defer node.mu.RUnlock()
+	children := make([]*Node, 0, len(node.Children))
+	for _, child := range node.Children {
+		children = append(children, child)
+	}
+	sort.Slice(children, func(i, j int) bool {
+		return children[i].Name < children[j].Name
+	})
+	return children
+}
+
+func splitLines(content []byte) []string {
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(content)+1)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines
+}
+
+// isBinary 通过检查前 8000 字节中是否有 NUL 判断是否为二进制文件
+func isBinary(content []byte) bool {
+	if len(content) > 8000 {
+		content = content[:8000]
+	}
+	return bytes.IndexByte(content, 0) >= 0
+}
+
+// slashPath 返回不带前导斜杠的 / 分隔路径
+func slashPath(path string) string {
+	return strings.TrimPrefix(filepath.ToSlash(filepath.Clean(path)), "/")
+}
@@ -0,0 +1,88 @@
+package project
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSearchProject(t *testing.T) *Project {
+	p := NewProject(t.TempDir())
+	require.NoError(t, p.CreateDir("cmd", nil))
+	require.NoError(t, p.CreateDir("cmd/sub", nil))
+	require.NoError(t, p.CreateFile("main.go", []byte("package main\n\nfunc main() {\n\trun()\n}\n"), nil))
+	require.NoError(t, p.CreateFile("cmd/root.go", []byte("package cmd\n\nfunc Run() {}\n"), nil))
+	require.NoError(t, p.CreateFile("cmd/sub/sub.go", []byte("package sub\n"), nil))
+	require.NoError(t, p.CreateFile("README.md", []byte("# run\n"), nil))
+	return p
+}
+
+func TestProject_ListDir(t *testing.T) {
+	p := newSearchProject(t)
+
+	entries, err := p.ListDir(".", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []Entry{
+		{Path: "README.md", Size: 6},
+		{Path: "cmd", IsDir: true},
+		{Path: "main.go", Size: 37},
+	}, entries)
+
+	entries, err = p.ListDir("cmd", 2)
+	assert.NoError(t, err)
+	var paths []string
+	for _, entry := range entries {
+		paths = append(paths, entry.Path)
+	}
+	assert.Equal(t, []string{"cmd/root.go", "cmd/sub", "cmd/sub/sub.go"}, paths)
+
+	_, err = p.ListDir("main.go", 1)
+	assert.Error(t, err)
+}
+
+func TestProject_Glob(t *testing.T) {
+	p := newSearchProject(t)
+
+	files, err := p.Glob("cmd/**/*.go")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"cmd/root.go", "cmd/sub/sub.go"}, files)
+
+	files, err = p.Glob("*.md")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"README.md"}, files)
+}
+
+func TestProject_Grep(t *testing.T) {
+	p := newSearchProject(t)
+
+	matches, err := p.Grep(GrepOptions{Pattern: "run", IgnoreCase: true, Include: []string{"*.go"}, Context: 1})
+	assert.NoError(t, err)
+	require.Len(t, matches, 2)
+	assert.Equal(t, "cmd/root.go", matches[0].Path)
+	assert.Equal(t, 3, matches[0].Line)
+	assert.Equal(t, []string{""}, matches[0].Before)
+	assert.Equal(t, "main.go", matches[1].Path)
+	assert.Equal(t, 4, matches[1].Line)
+	assert.Equal(t, []string{"func main() {"}, matches[1].Before)
+	assert.Equal(t, []string{"}"}, matches[1].After)
+
+	_, err = p.Grep(GrepOptions{Pattern: "("})
+	assert.Error(t, err)
+}
+
+func TestApplyEdits(t *testing.T) {
+	content, err := ApplyEdits("a b a", []Edit{{OldText: "b", NewText: "c"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "a c a", content)
+
+	_, err = ApplyEdits("a b a", []Edit{{OldText: "a", NewText: "x"}})
+	assert.Error(t, err)
+
+	content, err = ApplyEdits("a b a", []Edit{{OldText: "a", NewText: "x", ReplaceAll: true}})
+	assert.NoError(t, err)
+	assert.Equal(t, "x b x", content)
+
+	_, err = ApplyEdits("a b a", []Edit{{OldText: "z", NewText: "x"}})
+	assert.Error(t, err)
+}
@@ -238,11 +238,13 @@ func (t *TreeTraverser) Traverse(node *Node, path string, level int, visitor Nod
 		return nil
 	}
 
-	// 对子节点进行排序
+	// 对子节点进行排序，复制时持有读锁，避免与监控器的更新并发访问
+	node.mu.RLock()
 	children := make([]*Node, 0, len(node.Children))
 	for _, child := range node.Children {
 		children = append(children, child)
 	}
+	node.mu.RUnlock()
 	sort.Slice(children, func(i, j int) bool {
 		return children[i].Name < children[j].Name
 	})
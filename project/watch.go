@@ -0,0 +1,192 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sjzsdu/wn/helper"
+)
+
+// ChangeType 文件变化类型
+type ChangeType int
+
+const (
+	FileCreated  ChangeType = iota // 新建文件
+	FileModified                   // 修改文件
+	FileRemoved                    // 删除文件
+)
+
+// Change 一次文件变化，Path 为 / 分隔的相对路径
+type Change struct {
+	Path string
+	Type ChangeType
+}
+
+// fileState 记录文件的修改时间和大小，用于判断文件是否变化
+type fileState struct {
+	isDir   bool
+	modTime time.Time
+	size    int64
+}
+
+// Watcher 通过轮询监控项目目录，并增量更新项目树
+type Watcher struct {
+	project  *Project
+	options  helper.WalkDirOptions
+	interval time.Duration
+	files    map[string]fileState
+}
+
+// NewWatcher 创建项目监控器，以当前磁盘状态为基准
+func NewWatcher(p *Project, options helper.WalkDirOptions, interval time.Duration) *Watcher {
+	w := &Watcher{
+		project:  p,
+		options:  options,
+		interval: interval,
+	}
+	w.files, _ = w.scan()
+	return w
+}
+
+// Run 定期检查文件变化，有变化时调用 onChange，直到 ctx 结束
+func (w *Watcher) Run(ctx context.Context, onChange func([]Change)) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			changes, err := w.Poll()
+			if err == nil && len(changes) > 0 {
+				onChange(changes)
+			}
+		}
+	}
+}
+
+// Poll 检查一次文件变化，并将变化同步到项目树
+func (w *Watcher) Poll() ([]Change, error) {
+	current, err := w.scan()
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []Change
+	for rel, state := range current {
+		old, ok := w.files[rel]
+		if state.isDir {
+			if !ok {
+				w.project.ensureDir(filepath.FromSlash(rel))
+			}
+			continue
+		}
+		switch {
+		case !ok:
+			if w.reload(rel) {
+				changes = append(changes, Change{Path: rel, Type: FileCreated})
+			}
+		case !old.modTime.Equal(state.modTime) || old.size != state.size:
+			if w.reload(rel) {
+				changes = append(changes, Change{Path: rel, Type: FileModified})
+			}
+		}
+	}
+	for rel, state := range w.files {
+		if _, ok := current[rel]; ok {
+			continue
+		}
+		w.project.RemoveNode(filepath.FromSlash(rel))
+		if !state.isDir {
+			changes = append(changes, Change{Path: rel, Type: FileRemoved})
+		}
+	}
+
+	w.files = current
+	return changes, nil
+}
+
+// reload 重新读取文件内容并更新节点，旧的分析结果会被清除
+func (w *Watcher) reload(rel string) bool {
+	path := filepath.FromSlash(rel)
+	content, err := os.ReadFile(w.project.GetAbsolutePath(path))
+	if err != nil {
+		return false
+	}
+	info, err := os.Stat(w.project.GetAbsolutePath(path))
+	if err != nil {
+		return false
+	}
+	if err := w.project.upsertFile(path, content, info); err != nil {
+		return false
+	}
+	w.project.invalidate(path)
+	return true
+}
+
+func (w *Watcher) scan() (map[string]fileState, error) {
+	files := make(map[string]fileState)
+	err := walkProject(w.project.rootPath, w.options, func(path, relPath string, info os.FileInfo) error {
+		if relPath == "." {
+			return nil
+		}
+		state := fileState{isDir: info.IsDir()}
+		if !info.IsDir() {
+			state.modTime, state.size = info.ModTime(), info.Size()
+		}
+		files[filepath.ToSlash(relPath)] = state
+		return nil
+	})
+	return files, err
+}
+
+// RemoveNode 从项目树中删除节点及其子节点
+func (p *Project) RemoveNode(path string) error {
+	p.invalidate(path)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	node, err := p.findNode(path)
+	if err != nil {
+		return err
+	}
+	if node == p.root {
+		return errors.New("cannot remove root")
+	}
+
+	parent := node.Parent
+	if parent == nil {
+		return errors.New("node has no parent")
+	}
+	parent.mu.Lock()
+	delete(parent.Children, node.Name)
+	parent.mu.Unlock()
+	return nil
+}
+
+// invalidate 清除节点及其上级目录的分析结果，下次分析时重新生成
+func (p *Project) invalidate(path string) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	current := p.root
+	for _, comp := range strings.Split(filepath.Clean(strings.TrimPrefix(path, "/")), string(filepath.Separator)) {
+		current.mu.Lock()
+		current.LLMResponse = nil
+		child, ok := current.Children[comp]
+		current.mu.Unlock()
+		if !ok {
+			return
+		}
+		current = child
+	}
+	current.mu.Lock()
+	current.LLMResponse = nil
+	current.mu.Unlock()
+}
@@ -0,0 +1,101 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sjzsdu/wn/helper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatcher_Poll(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "a.go"), []byte("package a"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "b.go"), []byte("package b"), 0644))
+
+	options := helper.WalkDirOptions{DisableGitIgnore: true}
+	p, err := BuildProjectTree(root, options)
+	require.NoError(t, err)
+	p.root.Children["a.go"].LLMResponse = &LLMResponse{Feature: "a"}
+	p.root.LLMResponse = &LLMResponse{Feature: "root"}
+
+	w := NewWatcher(p, options, time.Second)
+	changes, err := w.Poll()
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+
+	require.NoError(t, os.WriteFile(filepath.Join(root, "a.go"), []byte("package a\n\nfunc A() {}"), 0644))
+	require.NoError(t, os.Remove(filepath.Join(root, "b.go")))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "sub"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "sub", "c.go"), []byte("package sub"), 0644))
+
+	changes, err = w.Poll()
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []Change{
+		{Path: "a.go", Type: FileModified},
+		{Path: "b.go", Type: FileRemoved},
+		{Path: "sub/c.go", Type: FileCreated},
+	}, changes)
+
+	content, err := p.ReadFile("a.go")
+	assert.NoError(t, err)
+	assert.Equal(t, "package a\n\nfunc A() {}", string(content))
+	assert.Nil(t, p.root.Children["a.go"].LLMResponse)
+	assert.Nil(t, p.root.LLMResponse)
+
+	_, err = p.ReadFile("b.go")
+	assert.Error(t, err)
+
+	content, err = p.ReadFile("sub/c.go")
+	assert.NoError(t, err)
+	assert.Equal(t, "package sub", string(content))
+}
+
+// 使用 go test -race 运行时检查监控器与搜索并发访问项目树
+func TestWatcher_PollConcurrentSearch(t *testing.T) {
+	root := t.TempDir()
+	for i := 0; i < 10; i++ {
+		require.NoError(t, os.WriteFile(filepath.Join(root, fmt.Sprintf("f%d.go", i)), []byte("package a"), 0644))
+	}
+	options := helper.WalkDirOptions{DisableGitIgnore: true}
+	p, err := BuildProjectTree(root, options)
+	require.NoError(t, err)
+	w := NewWatcher(p, options, time.Second)
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			_, err := p.Glob("**/*.go")
+			assert.NoError(t, err)
+			_, err = p.Grep(GrepOptions{Pattern: "package"})
+			assert.NoError(t, err)
+		}
+	}()
+
+	for i := 0; i < 20; i++ {
+		name := filepath.Join(root, "sub", fmt.Sprintf("n%d.go", i))
+		require.NoError(t, os.MkdirAll(filepath.Dir(name), 0755))
+		require.NoError(t, os.WriteFile(name, []byte(fmt.Sprintf("package sub // %d", i)), 0644))
+		require.NoError(t, os.Remove(filepath.Join(root, fmt.Sprintf("f%d.go", i%10))))
+		_, err := w.Poll()
+		assert.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(root, fmt.Sprintf("f%d.go", i%10)), []byte("package a"), 0644))
+		_, err = w.Poll()
+		assert.NoError(t, err)
+	}
+	close(done)
+	wg.Wait()
+}
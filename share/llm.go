@@ -7,3 +7,5 @@ const DEFAULT_LLM_MODEL = "deepseek-chat"
 const DEFAULT_LLM_AGENT = "chat"
 
 const DEFAULT_LLM_MESSAGES_LIMIT = 5
+
+const PROVIDERS_CONFIG_FILE = "providers.json"
@@ -4,3 +4,9 @@ const MCP_CLIENT_NAME = "WN MCP client"
 const MCP_SERVER_NAME = "WN MCP server"
 
 const MCP_CONFIG_FILE = "wn.mcp.json"
+
+const MCP_GLOBAL_CONFIG_FILE = "mcp.json"
+
+const DEFAULT_SAMPLING_MAX_TOKENS = 1024
+
+const DEFAULT_MCP_PING_INTERVAL = 30
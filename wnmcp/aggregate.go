@@ -0,0 +1,121 @@
+package wnmcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ServerErrors 按服务器汇总的错误报告
+type ServerErrors map[string]error
+
+func (e ServerErrors) Error() string {
+	names := make([]string, 0, len(e))
+	for name := range e {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s: %v", name, e[name]))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// OrNil 没有错误时返回 nil，避免返回非空的空 map
+func (e ServerErrors) OrNil() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// ServerResource 带有来源服务器名称的资源
+type ServerResource struct {
+	Server string `json:"server"`
+	mcp.Resource
+}
+
+// ServerResourceTemplate 带有来源服务器名称的资源模板
+type ServerResourceTemplate struct {
+	Server string `json:"server"`
+	mcp.ResourceTemplate
+}
+
+// ServerPrompt 带有来源服务器名称的提示
+type ServerPrompt struct {
+	Server string `json:"server"`
+	mcp.Prompt
+}
+
+// ResolveResource 查找提供指定 URI 的服务器
+// 依次按资源 URI、资源模板、URI scheme 匹配，只有一个服务器时直接使用该服务器
+func (c *Host) ResolveResource(ctx context.Context, uri string) (string, error) {
+	if c == nil || len(c.Clients) == 0 {
+		return "", fmt.Errorf("未配置 MCP 服务器")
+	}
+
+	names := c.clientNames()
+	if len(names) == 1 {
+		return names[0], nil
+	}
+
+	resources, _ := c.ListResources(ctx, mcp.ListResourcesRequest{})
+	for _, resource := range resources {
+		if resource.URI == uri {
+			return resource.Server, nil
+		}
+	}
+
+	templates, _ := c.ListResourceTemplates(ctx, mcp.ListResourceTemplatesRequest{})
+	for _, template := range templates {
+		if template.URITemplate != nil && template.URITemplate.Regexp().MatchString(uri) {
+			return template.Server, nil
+		}
+	}
+
+	scheme := uriScheme(uri)
+	if scheme != "" {
+		owners := make(map[string]bool)
+		for _, resource := range resources {
+			if uriScheme(resource.URI) == scheme {
+				owners[resource.Server] = true
+			}
+		}
+		for _, template := range templates {
+			if template.URITemplate != nil && uriScheme(template.URITemplate.Raw()) == scheme {
+				owners[template.Server] = true
+			}
+		}
+		if len(owners) == 1 {
+			for server := range owners {
+				return server, nil
+			}
+		}
+		if len(owners) > 1 {
+			return "", fmt.Errorf("多个服务器提供 %s:// 资源，无法确定 %s 的来源", scheme, uri)
+		}
+	}
+
+	return "", fmt.Errorf("未找到提供资源的服务器: %s", uri)
+}
+
+func (c *Host) lookupPrompt(name string) (route, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	r, ok := c.promptRoutes[name]
+	return r, ok
+}
+
+// uriScheme 返回 URI 的 scheme 部分，如 files://a 返回 files
+func uriScheme(uri string) string {
+	idx := strings.Index(uri, "://")
+	if idx <= 0 {
+		return ""
+	}
+	return uri[:idx]
+}
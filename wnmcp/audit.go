@@ -0,0 +1,266 @@
+package wnmcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/sjzsdu/wn/helper"
+)
+
+// 审计记录的类型
+const (
+	AuditRequest      = "request"
+	AuditResponse     = "response"
+	AuditNotification = "notification"
+)
+
+// 审计日志的默认大小上限和保留天数
+const (
+	DefaultAuditMaxSize       = 20 << 20
+	DefaultAuditRetentionDays = 14
+)
+
+// AuditRecord 审计日志中的一行，响应只记录大小，不记录内容，避免文件内容或密钥写入日志
+type AuditRecord struct {
+	Time         time.Time     `json:"time"`
+	Server       string        `json:"server"`
+	Type         string        `json:"type"`
+	Method       string        `json:"method"`
+	Args         interface{}   `json:"args,omitempty"`
+	ResponseSize int           `json:"responseSize,omitempty"`
+	Duration     time.Duration `json:"durationNs,omitempty"`
+	Error        string        `json:"error,omitempty"`
+}
+
+// AuditLog 把 MCP 请求、响应和通知按天追加到 dir 下的 mcp-YYYY-MM-DD.jsonl，
+// 文件超过大小上限时归档为 mcp-YYYY-MM-DD.N.jsonl，超过保留天数的文件会被删除
+type AuditLog struct {
+	dir           string
+	maxSize       int64
+	retentionDays int
+	mu            sync.Mutex
+	prunedDay     string
+}
+
+// AuditOption 审计日志的配置选项
+type AuditOption func(*AuditLog)
+
+// WithAuditMaxSize 设置单个日志文件的大小上限，0 表示不限制
+func WithAuditMaxSize(size int64) AuditOption {
+	return func(a *AuditLog) {
+		a.maxSize = size
+	}
+}
+
+// WithAuditRetention 设置日志保留的天数，0 表示不删除
+func WithAuditRetention(days int) AuditOption {
+	return func(a *AuditLog) {
+		a.retentionDays = days
+	}
+}
+
+// DefaultAuditDir 返回默认的审计日志目录 ~/.wn/logs
+func DefaultAuditDir() string {
+	return helper.GetPath("logs")
+}
+
+// NewAuditLog 创建写入 dir 的审计日志
+func NewAuditLog(dir string, opts ...AuditOption) *AuditLog {
+	a := &AuditLog{dir: dir, maxSize: DefaultAuditMaxSize, retentionDays: DefaultAuditRetentionDays}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// Hook 返回指定服务器的 FileAuditHook，可用于 WithServerHook
+func (a *AuditLog) Hook(server string) Hook {
+	return &FileAuditHook{log: a, server: server}
+}
+
+// Write 追加一条记录，每次写入都重新打开文件，以便多个 wn 进程共享同一个日志
+func (a *AuditLog) Write(record AuditRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := os.MkdirAll(a.dir, 0755); err != nil {
+		return err
+	}
+	day := record.Time.Format(auditDayLayout)
+	if day != a.prunedDay {
+		a.prune(record.Time)
+		a.prunedDay = day
+	}
+
+	name := filepath.Join(a.dir, "mcp-"+day+".jsonl")
+	if info, err := os.Stat(name); err == nil && a.maxSize > 0 && info.Size()+int64(len(data)) > a.maxSize {
+		if err := os.Rename(name, a.archiveName(day)); err != nil {
+			return err
+		}
+	}
+	file, err := os.OpenFile(name, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
+
+const auditDayLayout = "2006-01-02"
+
+// archiveName 返回当天下一个未使用的归档文件名
+func (a *AuditLog) archiveName(day string) string {
+	for i := 1; ; i++ {
+		name := filepath.Join(a.dir, fmt.Sprintf("mcp-%s.%d.jsonl", day, i))
+		if _, err := os.Stat(name); os.IsNotExist(err) {
+			return name
+		}
+	}
+}
+
+// prune 删除超过保留天数的日志文件
+func (a *AuditLog) prune(now time.Time) {
+	if a.retentionDays <= 0 {
+		return
+	}
+	files, err := filepath.Glob(filepath.Join(a.dir, "mcp-*.jsonl"))
+	if err != nil {
+		return
+	}
+	cutoff := now.AddDate(0, 0, -a.retentionDays)
+	for _, name := range files {
+		if day, ok := auditFileDay(name); ok && day.AddDate(0, 0, 1).Before(cutoff) {
+			os.Remove(name)
+		}
+	}
+}
+
+// auditFileDay 从文件名 mcp-YYYY-MM-DD[.N].jsonl 中解析日期
+func auditFileDay(name string) (time.Time, bool) {
+	base := strings.TrimPrefix(filepath.Base(name), "mcp-")
+	if len(base) < len(auditDayLayout) {
+		return time.Time{}, false
+	}
+	day, err := time.ParseInLocation(auditDayLayout, base[:len(auditDayLayout)], time.Local)
+	return day, err == nil
+}
+
+// ReadAuditLog 读取 dir 下 since 之后的审计记录，无法解析的行会被跳过
+func ReadAuditLog(dir string, since time.Time) ([]AuditRecord, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "mcp-*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	var records []AuditRecord
+	for _, name := range files {
+		if day, ok := auditFileDay(name); ok && day.AddDate(0, 0, 1).Before(since) {
+			continue
+		}
+
+		file, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+		for scanner.Scan() {
+			var record AuditRecord
+			if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+				continue
+			}
+			if record.Time.Before(since) {
+				continue
+			}
+			records = append(records, record)
+		}
+		err = scanner.Err()
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return records, nil
+}
+
+// MetricsFromAudit 根据审计记录中的响应汇总统计
+func MetricsFromAudit(records []AuditRecord) *Metrics {
+	metrics := NewMetrics()
+	for _, record := range records {
+		switch record.Type {
+		case AuditResponse:
+			var err error
+			if record.Error != "" {
+				err = errors.New(record.Error)
+			}
+			metrics.Record(record.Server, record.Method, record.Duration, err)
+		case AuditNotification:
+			metrics.RecordNotification(record.Server)
+		}
+	}
+	return metrics
+}
+
+// FileAuditHook 把单个服务器的请求、响应和通知写入 AuditLog
+type FileAuditHook struct {
+	log    *AuditLog
+	server string
+}
+
+func (h *FileAuditHook) BeforeRequest(ctx context.Context, method string, args interface{}) {
+	h.log.Write(AuditRecord{
+		Time:   time.Now(),
+		Server: h.server,
+		Type:   AuditRequest,
+		Method: method,
+		Args:   args,
+	})
+}
+
+func (h *FileAuditHook) AfterRequest(ctx context.Context, method string, response interface{}, err error) {
+	duration, _ := RequestDuration(ctx)
+	record := AuditRecord{
+		Time:     time.Now(),
+		Server:   h.server,
+		Type:     AuditResponse,
+		Method:   method,
+		Duration: duration,
+	}
+	if data, err := json.Marshal(response); err == nil && string(data) != "null" {
+		record.ResponseSize = len(data)
+	}
+	if err != nil {
+		record.Error = err.Error()
+	} else if result, ok := response.(*mcp.CallToolResult); ok && result != nil && result.IsError {
+		record.Error = "tool returned an error"
+	}
+	h.log.Write(record)
+}
+
+func (h *FileAuditHook) OnNotification(notification mcp.JSONRPCNotification) {
+	h.log.Write(AuditRecord{
+		Time:   time.Now(),
+		Server: h.server,
+		Type:   AuditNotification,
+		Method: notification.Method,
+		Args:   notification.Params,
+	})
+}
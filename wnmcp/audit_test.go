@@ -0,0 +1,115 @@
+package wnmcp
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileAuditHook(t *testing.T) {
+	dir := t.TempDir()
+	hook := NewAuditLog(dir).Hook("fs")
+
+	ctx := withRequestStart(context.Background())
+	hook.BeforeRequest(ctx, "CallTool", map[string]string{"name": "read_file"})
+	hook.AfterRequest(ctx, "CallTool", nil, errors.New("boom"))
+	hook.OnNotification(mcp.JSONRPCNotification{Notification: mcp.Notification{Method: "notifications/tools/list_changed"}})
+
+	files, _ := filepath.Glob(filepath.Join(dir, "mcp-*.jsonl"))
+	assert.Len(t, files, 1)
+
+	records, err := ReadAuditLog(dir, time.Now().Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Len(t, records, 3)
+
+	assert.Equal(t, AuditRequest, records[0].Type)
+	assert.Equal(t, map[string]interface{}{"name": "read_file"}, records[0].Args)
+	assert.Equal(t, AuditResponse, records[1].Type)
+	assert.Equal(t, "boom", records[1].Error)
+	assert.Greater(t, records[1].Duration, time.Duration(0))
+	assert.Equal(t, AuditNotification, records[2].Type)
+
+	metrics := MetricsFromAudit(records)
+	stats := metrics.Snapshot()
+	assert.Len(t, stats, 1)
+	assert.Equal(t, 1, stats[0].Errors)
+	assert.Equal(t, map[string]int{"fs": 1}, metrics.Notifications())
+}
+
+func TestReadAuditLog_Since(t *testing.T) {
+	dir := t.TempDir()
+	log := NewAuditLog(dir)
+	old := time.Now().AddDate(0, 0, -10)
+	assert.NoError(t, log.Write(AuditRecord{Time: old, Server: "fs", Type: AuditResponse, Method: "Ping"}))
+	assert.NoError(t, log.Write(AuditRecord{Time: time.Now(), Server: "fs", Type: AuditResponse, Method: "Ping"}))
+	// 无法解析的行被跳过
+	f, _ := os.OpenFile(filepath.Join(dir, "mcp-"+time.Now().Format("2006-01-02")+".jsonl"), os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString("not json\n")
+	f.Close()
+
+	records, err := ReadAuditLog(dir, time.Now().AddDate(0, 0, -7))
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+
+	records, err = ReadAuditLog(dir, time.Time{})
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+}
+
+func TestFileAuditHook_ResponseSize(t *testing.T) {
+	dir := t.TempDir()
+	hook := NewAuditLog(dir).Hook("fs")
+
+	ctx := withRequestStart(context.Background())
+	hook.AfterRequest(ctx, "CallTool", mcp.NewToolResultText("secret content"), nil)
+	hook.AfterRequest(ctx, "CallTool", &mcp.CallToolResult{Content: []mcp.Content{mcp.NewTextContent("not found")}, IsError: true}, nil)
+
+	data, err := os.ReadFile(filepath.Join(dir, "mcp-"+time.Now().Format("2006-01-02")+".jsonl"))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "secret content")
+
+	records, err := ReadAuditLog(dir, time.Time{})
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+	assert.Greater(t, records[0].ResponseSize, 0)
+	assert.Empty(t, records[0].Error)
+	assert.Equal(t, "tool returned an error", records[1].Error)
+}
+
+func TestAuditLog_Rotation(t *testing.T) {
+	dir := t.TempDir()
+	log := NewAuditLog(dir, WithAuditMaxSize(200))
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, log.Write(AuditRecord{Time: time.Now(), Server: "fs", Type: AuditResponse, Method: "Ping"}))
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "mcp-*.jsonl"))
+	assert.Greater(t, len(files), 1)
+	for _, name := range files {
+		info, err := os.Stat(name)
+		assert.NoError(t, err)
+		assert.LessOrEqual(t, info.Size(), int64(200))
+	}
+
+	records, err := ReadAuditLog(dir, time.Time{})
+	assert.NoError(t, err)
+	assert.Len(t, records, 5)
+}
+
+func TestAuditLog_Retention(t *testing.T) {
+	dir := t.TempDir()
+	log := NewAuditLog(dir, WithAuditRetention(7))
+	old := time.Now().AddDate(0, 0, -10)
+	assert.NoError(t, log.Write(AuditRecord{Time: old, Server: "fs", Type: AuditResponse, Method: "Ping"}))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "mcp-"+old.Format("2006-01-02")+".1.jsonl"), nil, 0644))
+	assert.NoError(t, log.Write(AuditRecord{Time: time.Now(), Server: "fs", Type: AuditResponse, Method: "Ping"}))
+
+	files, _ := filepath.Glob(filepath.Join(dir, "mcp-*.jsonl"))
+	assert.Equal(t, []string{filepath.Join(dir, "mcp-"+time.Now().Format("2006-01-02")+".jsonl")}, files)
+}
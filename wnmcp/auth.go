@@ -0,0 +1,47 @@
+package wnmcp
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// BearerAuth 要求请求携带 Authorization: Bearer <token> 头，tokens 为空时不做校验
+func BearerAuth(tokens []string, next http.Handler) http.Handler {
+	if len(tokens) == 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || !validToken(tokens, strings.TrimSpace(token)) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="wn"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ParseTokens 解析逗号分隔的 token 列表
+func ParseTokens(value string) []string {
+	var tokens []string
+	for _, token := range strings.Split(value, ",") {
+		if token = strings.TrimSpace(token); token != "" {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
+
+func validToken(tokens []string, token string) bool {
+	if token == "" {
+		return false
+	}
+	valid := false
+	for _, t := range tokens {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			valid = true
+		}
+	}
+	return valid
+}
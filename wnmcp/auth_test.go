@@ -0,0 +1,43 @@
+package wnmcp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBearerAuth(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := BearerAuth(ParseTokens("alpha, beta,"), ok)
+
+	tests := []struct {
+		name   string
+		header string
+		status int
+	}{
+		{"无 token", "", http.StatusUnauthorized},
+		{"错误 token", "Bearer gamma", http.StatusUnauthorized},
+		{"非 Bearer", "Basic beta", http.StatusUnauthorized},
+		{"正确 token", "Bearer beta", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/sse", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			assert.Equal(t, tt.status, rec.Code)
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	BearerAuth(nil, ok).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/sse", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
@@ -46,17 +46,29 @@ func (m *MockMCPClient) Ping(ctx context.Context) error {
 
 // ListResources 模拟ListResources方法
 func (m *MockMCPClient) ListResources(ctx context.Context, request mcp.ListResourcesRequest) (*mcp.ListResourcesResult, error) {
-	return nil, nil
+	args := m.Called(ctx, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*mcp.ListResourcesResult), args.Error(1)
 }
 
 // ListResourceTemplates 模拟ListResourceTemplates方法
 func (m *MockMCPClient) ListResourceTemplates(ctx context.Context, request mcp.ListResourceTemplatesRequest) (*mcp.ListResourceTemplatesResult, error) {
-	return nil, nil
+	args := m.Called(ctx, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*mcp.ListResourceTemplatesResult), args.Error(1)
 }
 
 // ReadResource 模拟ReadResource方法
 func (m *MockMCPClient) ReadResource(ctx context.Context, request mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
-	return nil, nil
+	args := m.Called(ctx, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*mcp.ReadResourceResult), args.Error(1)
 }
 
 // Subscribe 模拟Subscribe方法
@@ -71,12 +83,20 @@ func (m *MockMCPClient) Unsubscribe(ctx context.Context, request mcp.Unsubscribe
 
 // ListPrompts 模拟ListPrompts方法
 func (m *MockMCPClient) ListPrompts(ctx context.Context, request mcp.ListPromptsRequest) (*mcp.ListPromptsResult, error) {
-	return nil, nil
+	args := m.Called(ctx, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*mcp.ListPromptsResult), args.Error(1)
 }
 
 // GetPrompt 模拟GetPrompt方法
 func (m *MockMCPClient) GetPrompt(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
-	return nil, nil
+	args := m.Called(ctx, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*mcp.GetPromptResult), args.Error(1)
 }
 
 // SetLevel 模拟SetLevel方法
@@ -2,6 +2,11 @@ package wnmcp
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
 
 	"github.com/mark3labs/mcp-go/client"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -18,11 +23,31 @@ type Hook interface {
 	OnNotification(notification mcp.JSONRPCNotification)
 }
 
+// Dialer 创建到 MCP 服务器的新连接，用于延迟启动和断线重连
+type Dialer func() (client.MCPClient, error)
+
+// 重连的退避时间，第一次失败后立即重试，之后按指数增长
+const (
+	reconnectBaseDelay = time.Second
+	reconnectMaxDelay  = time.Minute
+)
+
 // Client 实现 MCPClient 接口
 type Client struct {
 	conn    client.MCPClient
 	project *project.Project
 	hook    Hook
+	dial    Dialer
+	timeout time.Duration
+
+	connectMu     sync.Mutex // 保证同一时间只有一个请求在建立连接
+	mu            sync.Mutex
+	status        Status
+	failures      int
+	retryAt       time.Time
+	notifications []func(notification mcp.JSONRPCNotification)
+	// initResult 建立连接时初始化的结果，只用于延迟连接的客户端
+	initResult *mcp.InitializeResult
 }
 
 // WithHook 设置客户端钩子
@@ -32,10 +57,19 @@ func WithHook(hook Hook) ClientOption {
 	}
 }
 
+// WithTimeout 设置单个请求的超时时间，0 表示不限制
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// NewClient 使用已建立的连接创建客户端并立即初始化，初始化失败会记录在 Status 中
 func NewClient(conn client.MCPClient, project *project.Project, opts ...ClientOption) *Client {
 	client := &Client{
 		conn:    conn,
 		project: project,
+		status:  Status{State: StateStarting, Since: time.Now()},
 	}
 
 	// 应用选项
@@ -43,132 +77,284 @@ func NewClient(conn client.MCPClient, project *project.Project, opts ...ClientOp
 		opt(client)
 	}
 
-	client.Initialize(context.Background(), NewInitializeRequest())
+	ctx, cancel := client.withTimeout(context.Background())
+	defer cancel()
+	if _, err := client.Initialize(ctx, NewInitializeRequest()); err != nil {
+		client.setFailed(err)
+	} else {
+		client.setReady()
+	}
+	return client
+}
+
+// NewLazyClient 创建在第一次请求时才连接的客户端，连接断开后会按退避时间自动重连
+func NewLazyClient(dial Dialer, project *project.Project, opts ...ClientOption) *Client {
+	client := &Client{
+		project: project,
+		dial:    dial,
+		status:  Status{State: StateIdle, Since: time.Now()},
+	}
+
+	for _, opt := range opts {
+		opt(client)
+	}
 	return client
 }
 
 func (c *Client) callHookBefore(ctx context.Context, method string, args interface{}) {
-    if c.hook != nil {
-        c.hook.BeforeRequest(ctx, method, args)
-    }
+	if c.hook != nil {
+		c.hook.BeforeRequest(ctx, method, args)
+	}
 }
 
 func (c *Client) callHookAfter(ctx context.Context, method string, response interface{}, err error) {
-    if c.hook != nil {
-        c.hook.AfterRequest(ctx, method, response, err)
-    }
+	if c.hook != nil {
+		c.hook.AfterRequest(ctx, method, response, err)
+	}
+}
+
+type requestStartKey struct{}
+
+func withRequestStart(ctx context.Context) context.Context {
+	return context.WithValue(ctx, requestStartKey{}, time.Now())
+}
+
+// RequestDuration 返回钩子中当前请求已经耗费的时间，ctx 不是来自 Client 时返回 false
+func RequestDuration(ctx context.Context) (time.Duration, bool) {
+	start, ok := ctx.Value(requestStartKey{}).(time.Time)
+	if !ok {
+		return 0, false
+	}
+	return time.Since(start), true
+}
+
+func (c *Client) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, c.timeout)
+}
+
+// connection 返回可用的连接，未启动或已断开时通过 dial 重新建立连接
+func (c *Client) connection(ctx context.Context) (client.MCPClient, error) {
+	c.connectMu.Lock()
+	defer c.connectMu.Unlock()
+
+	c.mu.Lock()
+	conn := c.conn
+	c.mu.Unlock()
+	if conn != nil && c.dial != nil && isClosed(conn) {
+		c.setFailed(ErrConnectionClosed)
+	}
+
+	c.mu.Lock()
+	conn, status, retryAt := c.conn, c.status, c.retryAt
+	c.mu.Unlock()
+
+	if c.dial == nil || (conn != nil && status.State == StateReady) {
+		if conn == nil {
+			return nil, fmt.Errorf("MCP 服务器未连接")
+		}
+		return conn, nil
+	}
+	if status.State == StateFailed && time.Now().Before(retryAt) {
+		return nil, fmt.Errorf("MCP 服务器不可用: %s", status.LastError)
+	}
+
+	return c.connect(ctx)
+}
+
+// connect 建立新连接并完成初始化
+func (c *Client) connect(ctx context.Context) (client.MCPClient, error) {
+	c.setState(StateStarting, "")
+
+	conn, err := c.dial()
+	if err != nil {
+		c.setFailed(err)
+		return nil, err
+	}
+
+	c.mu.Lock()
+	handlers := append([]func(mcp.JSONRPCNotification){}, c.notifications...)
+	c.mu.Unlock()
+	for _, handler := range handlers {
+		conn.OnNotification(c.wrapNotification(handler))
+	}
+
+	initCtx, cancel := c.withTimeout(withRequestStart(ctx))
+	defer cancel()
+	request := NewInitializeRequest()
+	c.callHookBefore(initCtx, "Initialize", request)
+	result, err := conn.Initialize(initCtx, request)
+	c.callHookAfter(initCtx, "Initialize", result, err)
+	if err != nil {
+		conn.Close()
+		c.setFailed(err)
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.conn = conn
+	c.initResult = result
+	c.mu.Unlock()
+	c.setReady()
+	return conn, nil
+}
+
+// call 获取连接后执行请求，处理超时、钩子和连接失败
+func call[T any](c *Client, ctx context.Context, method string, args interface{}, fn func(ctx context.Context, conn client.MCPClient) (T, error)) (T, error) {
+	var result T
+	ctx = withRequestStart(ctx)
+	c.callHookBefore(ctx, method, args)
+
+	conn, err := c.connection(ctx)
+	if err == nil {
+		reqCtx, cancel := c.withTimeout(ctx)
+		result, err = fn(reqCtx, conn)
+		cancel()
+		if err != nil && (isConnectionError(err) || isClosed(conn)) {
+			c.setFailed(err)
+		}
+	}
+
+	c.callHookAfter(ctx, method, result, err)
+	return result, err
 }
 
 // 实现 MCPClient 接口的所有方法
 func (c *Client) Initialize(ctx context.Context, request mcp.InitializeRequest) (*mcp.InitializeResult, error) {
-	c.callHookBefore(ctx, "Initialize", request)
-	result, err := c.conn.Initialize(ctx, request)
-	c.callHookAfter(ctx, "Initialize", result, err)
-	return result, err
+	// 延迟连接的客户端在建立连接时已经初始化，返回那次的结果，避免重复发送 initialize
+	if c.dial != nil {
+		if _, err := c.connection(ctx); err != nil {
+			return nil, err
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.initResult, nil
+	}
+	return call(c, ctx, "Initialize", request, func(ctx context.Context, conn client.MCPClient) (*mcp.InitializeResult, error) {
+		return conn.Initialize(ctx, request)
+	})
 }
 
 func (c *Client) Ping(ctx context.Context) error {
-	c.callHookBefore(ctx, "Ping", nil)
-	err := c.conn.Ping(ctx)
-	c.callHookAfter(ctx, "Ping", nil, err)
+	_, err := call(c, ctx, "Ping", nil, func(ctx context.Context, conn client.MCPClient) (interface{}, error) {
+		return nil, conn.Ping(ctx)
+	})
 	return err
 }
 
 func (c *Client) ListResources(ctx context.Context, request mcp.ListResourcesRequest) (*mcp.ListResourcesResult, error) {
-	c.callHookBefore(ctx, "ListResources", request)
-	result, err := c.conn.ListResources(ctx, request)
-	c.callHookAfter(ctx, "ListResources", result, err)
-	return result, err
+	return call(c, ctx, "ListResources", request, func(ctx context.Context, conn client.MCPClient) (*mcp.ListResourcesResult, error) {
+		return conn.ListResources(ctx, request)
+	})
 }
 
 func (c *Client) ListResourceTemplates(ctx context.Context, request mcp.ListResourceTemplatesRequest) (*mcp.ListResourceTemplatesResult, error) {
-	c.callHookBefore(ctx, "ListResourceTemplates", request)
-	result, err := c.conn.ListResourceTemplates(ctx, request)
-	c.callHookAfter(ctx, "ListResourceTemplates", result, err)
-	return result, err
+	return call(c, ctx, "ListResourceTemplates", request, func(ctx context.Context, conn client.MCPClient) (*mcp.ListResourceTemplatesResult, error) {
+		return conn.ListResourceTemplates(ctx, request)
+	})
 }
 
 func (c *Client) ReadResource(ctx context.Context, request mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
-	c.callHookBefore(ctx, "ReadResource", request)
-	result, err := c.conn.ReadResource(ctx, request)
-	c.callHookAfter(ctx, "ReadResource", result, err)
-	return result, err
+	return call(c, ctx, "ReadResource", request, func(ctx context.Context, conn client.MCPClient) (*mcp.ReadResourceResult, error) {
+		return conn.ReadResource(ctx, request)
+	})
 }
 
 func (c *Client) Subscribe(ctx context.Context, request mcp.SubscribeRequest) error {
-	c.callHookBefore(ctx, "Subscribe", request)
-	err := c.conn.Subscribe(ctx, request)
-	c.callHookAfter(ctx, "Subscribe", nil, err)
+	_, err := call(c, ctx, "Subscribe", request, func(ctx context.Context, conn client.MCPClient) (interface{}, error) {
+		return nil, conn.Subscribe(ctx, request)
+	})
 	return err
 }
 
 func (c *Client) Unsubscribe(ctx context.Context, request mcp.UnsubscribeRequest) error {
-	c.callHookBefore(ctx, "Unsubscribe", request)
-	err := c.conn.Unsubscribe(ctx, request)
-	c.callHookAfter(ctx, "Unsubscribe", nil, err)
+	_, err := call(c, ctx, "Unsubscribe", request, func(ctx context.Context, conn client.MCPClient) (interface{}, error) {
+		return nil, conn.Unsubscribe(ctx, request)
+	})
 	return err
 }
 
 func (c *Client) ListPrompts(ctx context.Context, request mcp.ListPromptsRequest) (*mcp.ListPromptsResult, error) {
-	c.callHookBefore(ctx, "ListPrompts", request)
-	result, err := c.conn.ListPrompts(ctx, request)
-	c.callHookAfter(ctx, "ListPrompts", result, err)
-	return result, err
+	return call(c, ctx, "ListPrompts", request, func(ctx context.Context, conn client.MCPClient) (*mcp.ListPromptsResult, error) {
+		return conn.ListPrompts(ctx, request)
+	})
 }
 
 func (c *Client) GetPrompt(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
-	c.callHookBefore(ctx, "GetPrompt", request)
-	result, err := c.conn.GetPrompt(ctx, request)
-	c.callHookAfter(ctx, "GetPrompt", result, err)
-	return result, err
+	return call(c, ctx, "GetPrompt", request, func(ctx context.Context, conn client.MCPClient) (*mcp.GetPromptResult, error) {
+		return conn.GetPrompt(ctx, request)
+	})
 }
 
 func (c *Client) ListTools(ctx context.Context, request mcp.ListToolsRequest) (*mcp.ListToolsResult, error) {
-	c.callHookBefore(ctx, "ListTools", request)
-	result, err := c.conn.ListTools(ctx, request)
-	c.callHookAfter(ctx, "ListTools", result, err)
-	return result, err
+	return call(c, ctx, "ListTools", request, func(ctx context.Context, conn client.MCPClient) (*mcp.ListToolsResult, error) {
+		return conn.ListTools(ctx, request)
+	})
 }
 
 func (c *Client) CallTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	c.callHookBefore(ctx, "CallTool", request)
-	result, err := c.conn.CallTool(ctx, request)
-	c.callHookAfter(ctx, "CallTool", result, err)
-	return result, err
+	return call(c, ctx, "CallTool", request, func(ctx context.Context, conn client.MCPClient) (*mcp.CallToolResult, error) {
+		return conn.CallTool(ctx, request)
+	})
 }
 
 func (c *Client) SetLevel(ctx context.Context, request mcp.SetLevelRequest) error {
-	c.callHookBefore(ctx, "SetLevel", request)
-	err := c.conn.SetLevel(ctx, request)
-	c.callHookAfter(ctx, "SetLevel", nil, err)
+	_, err := call(c, ctx, "SetLevel", request, func(ctx context.Context, conn client.MCPClient) (interface{}, error) {
+		return nil, conn.SetLevel(ctx, request)
+	})
 	return err
 }
 
 func (c *Client) Complete(ctx context.Context, request mcp.CompleteRequest) (*mcp.CompleteResult, error) {
-	c.callHookBefore(ctx, "Complete", request)
-	result, err := c.conn.Complete(ctx, request)
-	c.callHookAfter(ctx, "Complete", result, err)
-	return result, err
+	return call(c, ctx, "Complete", request, func(ctx context.Context, conn client.MCPClient) (*mcp.CompleteResult, error) {
+		return conn.Complete(ctx, request)
+	})
 }
 
+// Close 关闭连接，未启动的客户端直接返回
 func (c *Client) Close() error {
-	c.callHookBefore(context.Background(), "Close", nil)
-	err := c.conn.Close()
-	c.callHookAfter(context.Background(), "Close", nil, err)
+	c.mu.Lock()
+	conn := c.conn
+	if c.dial != nil {
+		c.conn = nil
+	}
+	c.status = Status{State: StateStopped, Since: time.Now()}
+	c.mu.Unlock()
+
+	if conn == nil {
+		return nil
+	}
+	ctx := withRequestStart(context.Background())
+	c.callHookBefore(ctx, "Close", nil)
+	err := conn.Close()
+	c.callHookAfter(ctx, "Close", nil, err)
 	return err
 }
 
+// OnNotification 注册通知处理函数，重连后会自动重新注册
 func (c *Client) OnNotification(handler func(notification mcp.JSONRPCNotification)) {
-	if c.hook != nil {
-		originalHandler := handler
-		handler = func(notification mcp.JSONRPCNotification) {
-			c.hook.OnNotification(notification)
-			if originalHandler != nil {
-				originalHandler(notification)
-			}
+	c.mu.Lock()
+	c.notifications = append(c.notifications, handler)
+	conn := c.conn
+	c.mu.Unlock()
+
+	if conn != nil {
+		conn.OnNotification(c.wrapNotification(handler))
+	}
+}
+
+func (c *Client) wrapNotification(handler func(notification mcp.JSONRPCNotification)) func(notification mcp.JSONRPCNotification) {
+	if c.hook == nil {
+		return handler
+	}
+	return func(notification mcp.JSONRPCNotification) {
+		c.hook.OnNotification(notification)
+		if handler != nil {
+			handler(notification)
 		}
 	}
-	c.conn.OnNotification(handler)
 }
 
 func (c *Client) GetProjectName() string {
@@ -177,3 +363,22 @@ func (c *Client) GetProjectName() string {
 	}
 	return c.project.GetName()
 }
+
+// isClosed 判断连接是否已经断开，只有实现了 Done 的连接（如 StdioClient）才能检测
+func isClosed(conn client.MCPClient) bool {
+	closer, ok := conn.(interface{ Done() <-chan struct{} })
+	if !ok {
+		return false
+	}
+	select {
+	case <-closer.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+// isConnectionError 判断错误是否表示连接已经不可用
+func isConnectionError(err error) bool {
+	return errors.Is(err, ErrConnectionClosed) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrClosedPipe)
+}
@@ -2,8 +2,12 @@ package wnmcp
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/sjzsdu/wn/helper"
 	"github.com/sjzsdu/wn/share"
@@ -11,14 +15,15 @@ import (
 
 // MCPServerConfig 单个 MCP 服务器的配置
 type MCPServerConfig struct {
-	Disabled      bool     `json:"disabled"`
-	Timeout       int      `json:"timeout"`
-	Command       string   `json:"command"`
-	Args          []string `json:"args"`
-	Env           []string `json:"env"`
-	TransportType string   `json:"transportType"`
-	Url           string   `json:"url,omitempty"`
-	AutoApprove   []string `json:"autoApprove,omitempty"`
+	Disabled      bool              `json:"disabled"`
+	Timeout       int               `json:"timeout"`
+	Command       string            `json:"command"`
+	Args          []string          `json:"args"`
+	Env           []string          `json:"env"`
+	TransportType string            `json:"transportType"`
+	Url           string            `json:"url,omitempty"`
+	Headers       map[string]string `json:"headers,omitempty"`
+	AutoApprove   []string          `json:"autoApprove,omitempty"`
 }
 
 // MCPConfig MCP 配置文件结构
@@ -26,31 +31,146 @@ type MCPConfig struct {
 	MCPServers map[string]MCPServerConfig `json:"mcpServers"`
 }
 
-// LoadMCPConfig 从指定目录加载 MCP 配置
-func LoadMCPConfig(dir string, file string) (*MCPConfig, error) {
-	var configPath string
+// GlobalMCPConfigPath 返回全局 MCP 配置文件路径 ~/.wn/mcp.json
+func GlobalMCPConfigPath() string {
+	return helper.GetPath(share.MCP_GLOBAL_CONFIG_FILE)
+}
+
+// ProjectMCPConfigPath 返回项目 MCP 配置文件路径，file 非空时使用指定的文件
+func ProjectMCPConfigPath(dir string, file string) string {
 	if file != "" {
-		configPath, _ = helper.GetAbsPath(file)
-	} else {
-		configPath = filepath.Join(dir, share.MCP_CONFIG_FILE)
+		path, _ := helper.GetAbsPath(file)
+		return path
+	}
+	return filepath.Join(dir, share.MCP_CONFIG_FILE)
+}
+
+// LoadMCPConfig 加载全局配置和项目配置并合并，项目配置中的字段覆盖全局配置
+// file 非空时用它代替项目目录下的配置文件，且文件必须存在
+// 合并后展开 ${workspaceFolder}、${env:VAR} 和 ~，workspaceFolder 为 dir
+func LoadMCPConfig(dir string, file string) (*MCPConfig, error) {
+	config := &MCPConfig{MCPServers: make(map[string]MCPServerConfig)}
+	projectPath := ProjectMCPConfigPath(dir, file)
+
+	found := false
+	for _, path := range []string{GlobalMCPConfigPath(), projectPath} {
+		err := mergeMCPConfigFile(config, path)
+		if os.IsNotExist(err) {
+			if file != "" && path == projectPath {
+				return nil, err
+			}
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		found = true
+	}
+	if !found {
+		return nil, fmt.Errorf("%w: %s", os.ErrNotExist, projectPath)
 	}
 
-	// 检查文件是否存在
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		return nil, err
+	workspace, err := filepath.Abs(dir)
+	if err != nil {
+		workspace = dir
 	}
+	config.Expand(workspace)
+	return config, nil
+}
 
-	data, err := os.ReadFile(configPath)
+// mergeMCPConfigFile 把配置文件中的服务器按字段合并到 config 中
+func mergeMCPConfigFile(config *MCPConfig, path string) error {
+	data, err := os.ReadFile(path)
 	if err != nil {
-		return nil, err
+		return err
+	}
+
+	var layer struct {
+		MCPServers map[string]json.RawMessage `json:"mcpServers"`
+	}
+	if err := json.Unmarshal(data, &layer); err != nil {
+		return err
 	}
 
-	var config MCPConfig
-	if err := json.Unmarshal(data, &config); err != nil {
-		return nil, err
+	for name, raw := range layer.MCPServers {
+		server := config.MCPServers[name]
+		if server.Headers != nil {
+			headers := make(map[string]string, len(server.Headers))
+			for k, v := range server.Headers {
+				headers[k] = v
+			}
+			server.Headers = headers
+		}
+		if err := json.Unmarshal(raw, &server); err != nil {
+			return fmt.Errorf("server %s: %w", name, err)
+		}
+		config.MCPServers[name] = server
 	}
+	return nil
+}
+
+// Expand 展开所有服务器的 command、args、env、url 和 headers 中的变量
+func (c *MCPConfig) Expand(workspace string) {
+	for name, server := range c.MCPServers {
+		server.Command = ExpandVariables(server.Command, workspace)
+		server.Url = ExpandVariables(server.Url, workspace)
+		for i, arg := range server.Args {
+			server.Args[i] = ExpandVariables(arg, workspace)
+		}
+		for i, env := range server.Env {
+			// 只展开 KEY=VALUE 中的值，使 VALUE 以 ~ 开头时也能展开
+			if key, value, ok := strings.Cut(env, "="); ok {
+				server.Env[i] = key + "=" + ExpandVariables(value, workspace)
+			} else {
+				server.Env[i] = ExpandVariables(env, workspace)
+			}
+		}
+		for key, value := range server.Headers {
+			server.Headers[key] = ExpandVariables(value, workspace)
+		}
+		c.MCPServers[name] = server
+	}
+}
+
+var variablePattern = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// ExpandVariables 展开 ${workspaceFolder}、${env:VAR} 以及开头的 ~，无法识别的变量保持原样
+func ExpandVariables(value string, workspace string) string {
+	if value == "~" || strings.HasPrefix(value, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			value = home + value[1:]
+		}
+	}
+
+	return variablePattern.ReplaceAllStringFunc(value, func(match string) string {
+		name := match[2 : len(match)-1]
+		switch {
+		case name == "workspaceFolder":
+			return workspace
+		case strings.HasPrefix(name, "env:"):
+			return os.Getenv(strings.TrimPrefix(name, "env:"))
+		default:
+			return match
+		}
+	})
+}
 
-	return &config, nil
+// IsAutoApproved 判断工具是否在 autoApprove 列表中，"*" 表示批准所有工具
+func (c MCPServerConfig) IsAutoApproved(tool string) bool {
+	for _, name := range c.AutoApprove {
+		if name == tool || name == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// RequestTimeout 返回单个请求的超时时间，timeout 以秒为单位，未配置时返回 0 表示不限制
+func (c MCPServerConfig) RequestTimeout() time.Duration {
+	if c.Timeout <= 0 {
+		return 0
+	}
+	return time.Duration(c.Timeout) * time.Second
 }
 
 // GetServerConfig 获取指定服务器的配置
@@ -0,0 +1,132 @@
+package wnmcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// MCPConfigFile 单个 MCP 配置文件，编辑时保留文件中未识别的字段
+type MCPConfigFile struct {
+	Path string
+	data map[string]interface{}
+}
+
+// OpenMCPConfigFile 读取配置文件，文件不存在时返回空配置
+func OpenMCPConfigFile(path string) (*MCPConfigFile, error) {
+	file := &MCPConfigFile{Path: path, data: make(map[string]interface{})}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return file, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &file.data); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return file, nil
+}
+
+// servers 返回 mcpServers 对象，不存在时创建
+func (f *MCPConfigFile) servers() map[string]interface{} {
+	servers, ok := f.data["mcpServers"].(map[string]interface{})
+	if !ok {
+		servers = make(map[string]interface{})
+		f.data["mcpServers"] = servers
+	}
+	return servers
+}
+
+// ServerNames 返回文件中定义的服务器名称
+func (f *MCPConfigFile) ServerNames() []string {
+	servers, _ := f.data["mcpServers"].(map[string]interface{})
+	names := make([]string, 0, len(servers))
+	for name := range servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HasServer 判断文件中是否定义了服务器
+func (f *MCPConfigFile) HasServer(name string) bool {
+	servers, _ := f.data["mcpServers"].(map[string]interface{})
+	_, ok := servers[name]
+	return ok
+}
+
+// SetDisabled 设置服务器的 disabled 字段，服务器不在文件中时只写入该字段，用于覆盖全局配置
+func (f *MCPConfigFile) SetDisabled(name string, disabled bool) {
+	servers := f.servers()
+	server, ok := servers[name].(map[string]interface{})
+	if !ok {
+		server = make(map[string]interface{})
+		servers[name] = server
+	}
+	server["disabled"] = disabled
+}
+
+// AddServer 添加服务器，同名服务器已存在时返回错误
+func (f *MCPConfigFile) AddServer(name string, config MCPServerConfig) error {
+	servers := f.servers()
+	if _, ok := servers[name]; ok {
+		return fmt.Errorf("服务器 %s 已存在于 %s", name, f.Path)
+	}
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	var server map[string]interface{}
+	if err := json.Unmarshal(data, &server); err != nil {
+		return err
+	}
+	// 未设置的 command、args、env 等字段不写入文件
+	for key, value := range server {
+		if value == nil || value == "" {
+			delete(server, key)
+		}
+	}
+	servers[name] = server
+	return nil
+}
+
+// Save 先写入临时文件再重命名，避免写入中断时损坏配置文件
+func (f *MCPConfigFile) Save() error {
+	data, err := json.MarshalIndent(f.data, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(f.Path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(f.Path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(f.Path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.Path)
+}
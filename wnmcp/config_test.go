@@ -5,11 +5,15 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
 func TestLoadMCPConfig(t *testing.T) {
+	// 隔离 HOME，避免读取本机的全局配置 ~/.wn/mcp.json
+	t.Setenv("HOME", t.TempDir())
+
 	// 创建临时目录
 	tempDir := t.TempDir()
 
@@ -149,3 +153,116 @@ func TestGetServerConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestMCPServerConfig_IsAutoApproved(t *testing.T) {
+	config := MCPServerConfig{AutoApprove: []string{"read_file"}}
+	assert.True(t, config.IsAutoApproved("read_file"))
+	assert.False(t, config.IsAutoApproved("write_file"))
+
+	all := MCPServerConfig{AutoApprove: []string{"*"}}
+	assert.True(t, all.IsAutoApproved("write_file"))
+
+	assert.False(t, MCPServerConfig{}.IsAutoApproved("read_file"))
+}
+
+func TestMCPServerConfig_RequestTimeout(t *testing.T) {
+	// 未配置时不限制，避免长时间运行的工具被中断
+	assert.Equal(t, time.Duration(0), MCPServerConfig{}.RequestTimeout())
+	assert.Equal(t, 30*time.Second, MCPServerConfig{Timeout: 30}.RequestTimeout())
+}
+
+func writeJSON(t *testing.T, path string, content string) {
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestLoadMCPConfig_Layers(t *testing.T) {
+	home := t.TempDir()
+	workspace := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("WN_TEST_TOKEN", "secret")
+
+	writeJSON(t, filepath.Join(home, ".wn", "mcp.json"), `{"mcpServers": {
+		"fs": {"command": "npx", "args": ["server-fs", "${workspaceFolder}"], "transportType": "stdio", "timeout": 30},
+		"remote": {"url": "https://example.com/sse", "headers": {"Authorization": "Bearer ${env:WN_TEST_TOKEN}"}, "transportType": "sse"}
+	}}`)
+	writeJSON(t, filepath.Join(workspace, "wn.mcp.json"), `{"mcpServers": {
+		"fs": {"disabled": true},
+		"git": {"command": "~/bin/git-mcp", "env": ["REPO=~/repo", "DEBUG=${unknown}"], "transportType": "stdio"}
+	}}`)
+
+	config, err := LoadMCPConfig(workspace, "")
+	assert.NoError(t, err)
+	assert.Len(t, config.MCPServers, 3)
+
+	// 项目配置只覆盖了 disabled，其余字段来自全局配置
+	fs := config.MCPServers["fs"]
+	assert.True(t, fs.Disabled)
+	assert.Equal(t, "npx", fs.Command)
+	assert.Equal(t, []string{"server-fs", workspace}, fs.Args)
+	assert.Equal(t, 30, fs.Timeout)
+
+	assert.Equal(t, "Bearer secret", config.MCPServers["remote"].Headers["Authorization"])
+
+	git := config.MCPServers["git"]
+	assert.Equal(t, filepath.Join(home, "bin/git-mcp"), git.Command)
+	assert.Equal(t, []string{"REPO=" + filepath.Join(home, "repo"), "DEBUG=${unknown}"}, git.Env)
+}
+
+func TestLoadMCPConfig_GlobalOnly(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	_, err := LoadMCPConfig(t.TempDir(), "")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	writeJSON(t, filepath.Join(home, ".wn", "mcp.json"), `{"mcpServers": {"fs": {"command": "fs"}}}`)
+	config, err := LoadMCPConfig(t.TempDir(), "")
+	assert.NoError(t, err)
+	assert.Equal(t, "fs", config.MCPServers["fs"].Command)
+}
+
+func TestExpandVariables(t *testing.T) {
+	t.Setenv("HOME", "/home/wn")
+	t.Setenv("WN_TEST_VAR", "value")
+
+	assert.Equal(t, "/work/src", ExpandVariables("${workspaceFolder}/src", "/work"))
+	assert.Equal(t, "x-value-y", ExpandVariables("x-${env:WN_TEST_VAR}-y", "/work"))
+	assert.Equal(t, "/home/wn/.config", ExpandVariables("~/.config", "/work"))
+	assert.Equal(t, "/home/wn", ExpandVariables("~", "/work"))
+	assert.Equal(t, "a~b", ExpandVariables("a~b", "/work"))
+	assert.Equal(t, "${other}", ExpandVariables("${other}", "/work"))
+}
+
+func TestMCPConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "wn.mcp.json")
+	writeJSON(t, path, `{"mcpServers": {"fs": {"command": "npx", "alwaysAllow": ["read"]}}, "other": 1}`)
+
+	file, err := OpenMCPConfigFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"fs"}, file.ServerNames())
+
+	file.SetDisabled("fs", true)
+	file.SetDisabled("global-only", true)
+	assert.NoError(t, file.AddServer("git", MCPServerConfig{Command: "git-mcp", TransportType: "stdio"}))
+	assert.Error(t, file.AddServer("fs", MCPServerConfig{}))
+	assert.NoError(t, file.Save())
+
+	var saved map[string]interface{}
+	data, _ := os.ReadFile(path)
+	assert.NoError(t, json.Unmarshal(data, &saved))
+
+	// 未识别的字段保持不变
+	assert.Equal(t, float64(1), saved["other"])
+	servers := saved["mcpServers"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"command": "npx", "alwaysAllow": []interface{}{"read"}, "disabled": true}, servers["fs"])
+	assert.Equal(t, map[string]interface{}{"disabled": true}, servers["global-only"])
+	assert.NotContains(t, servers["git"], "args")
+	assert.Equal(t, "git-mcp", servers["git"].(map[string]interface{})["command"])
+
+	// 文件不存在时返回空配置，保存时创建目录
+	file, err = OpenMCPConfigFile(filepath.Join(t.TempDir(), "new", "mcp.json"))
+	assert.NoError(t, err)
+	assert.Empty(t, file.ServerNames())
+	assert.NoError(t, file.Save())
+}
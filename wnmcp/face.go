@@ -4,19 +4,15 @@ import (
 	"context"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/sjzsdu/wn/helper"
+	"github.com/sjzsdu/wn/share"
 )
 
+// GetTools 返回提供给 LLM 的工具列表，名称冲突的工具会带上服务器前缀
 func (c *Host) GetTools(ctx context.Context, request mcp.ListToolsRequest) []mcp.Tool {
-	results, lastError := c.ListTools(ctx, request)
-
-	if lastError != nil {
-		return nil
-	}
-	var tools []mcp.Tool
-	for _, toolsResult := range results {
-		for _, tool := range toolsResult.Tools {
-			tools = append(tools, tool)
-		}
+	tools, err := c.RefreshTools(ctx)
+	if err != nil && share.GetDebug() {
+		helper.PrintWithLabel("GetTools", err.Error())
 	}
 	return tools
 }
@@ -2,6 +2,11 @@ package wnmcp
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/sjzsdu/wn/helper"
@@ -81,3 +86,65 @@ func (h *CompositeHook) OnNotification(notification mcp.JSONRPCNotification) {
 		hook.OnNotification(notification)
 	}
 }
+
+// Tracer 把所有服务器的请求、响应和通知以 JSON 形式输出，可以随时开启或关闭
+type Tracer struct {
+	out     io.Writer
+	enabled atomic.Bool
+	mu      sync.Mutex
+}
+
+// NewTracer 创建一个输出到 out 的 Tracer，默认关闭
+func NewTracer(out io.Writer) *Tracer {
+	return &Tracer{out: out}
+}
+
+// SetEnabled 开启或关闭输出
+func (t *Tracer) SetEnabled(enabled bool) {
+	t.enabled.Store(enabled)
+}
+
+// Enabled 返回是否正在输出
+func (t *Tracer) Enabled() bool {
+	return t.enabled.Load()
+}
+
+// Hook 返回指定服务器的钩子，可用于 WithServerHook
+func (t *Tracer) Hook(server string) Hook {
+	return &traceHook{tracer: t, server: server}
+}
+
+func (t *Tracer) print(server, direction, method string, payload interface{}) {
+	if !t.Enabled() {
+		return
+	}
+	data, err := json.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		data = []byte(fmt.Sprintf("%v", payload))
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	fmt.Fprintf(t.out, "%s %s %s\n%s\n", direction, server, method, data)
+}
+
+type traceHook struct {
+	tracer *Tracer
+	server string
+}
+
+func (h *traceHook) BeforeRequest(ctx context.Context, method string, args interface{}) {
+	h.tracer.print(h.server, "-->", method, args)
+}
+
+func (h *traceHook) AfterRequest(ctx context.Context, method string, response interface{}, err error) {
+	if err != nil {
+		h.tracer.print(h.server, "<--", method, map[string]string{"error": err.Error()})
+		return
+	}
+	h.tracer.print(h.server, "<--", method, response)
+}
+
+func (h *traceHook) OnNotification(notification mcp.JSONRPCNotification) {
+	h.tracer.print(h.server, "<--", notification.Method, notification)
+}
@@ -0,0 +1,32 @@
+package wnmcp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTracer(t *testing.T) {
+	out := &bytes.Buffer{}
+	tracer := NewTracer(out)
+	hook := tracer.Hook("fs")
+
+	// 默认关闭
+	hook.BeforeRequest(context.Background(), "ListTools", nil)
+	assert.Empty(t, out.String())
+
+	tracer.SetEnabled(true)
+	hook.BeforeRequest(context.Background(), "CallTool", map[string]string{"name": "read_file"})
+	hook.AfterRequest(context.Background(), "CallTool", nil, errors.New("boom"))
+	hook.OnNotification(mcp.JSONRPCNotification{Notification: mcp.Notification{Method: "notifications/tools/list_changed"}})
+
+	text := out.String()
+	assert.Contains(t, text, "--> fs CallTool")
+	assert.Contains(t, text, `"name": "read_file"`)
+	assert.Contains(t, text, `"error": "boom"`)
+	assert.Contains(t, text, "<-- fs notifications/tools/list_changed")
+}
@@ -3,6 +3,8 @@ package wnmcp
 import (
 	"context"
 	"fmt"
+	"sort"
+	"sync"
 
 	"github.com/mark3labs/mcp-go/client"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -14,24 +16,65 @@ import (
 type Host struct {
 	Clients map[string]*Client
 	project *project.Project
+	configs map[string]MCPServerConfig
+
+	mu           sync.RWMutex
+	toolRoutes   map[string]route
+	promptRoutes map[string]route
+
+	sampler *Sampler
+	hooks   []func(server string) Hook
+}
+
+// HostOption 定义 Host 选项函数类型
+type HostOption func(*Host)
+
+// WithSampler 允许 stdio 服务器通过 sampling/createMessage 调用本地大模型
+func WithSampler(sampler *Sampler) HostOption {
+	return func(h *Host) {
+		h.sampler = sampler
+	}
+}
+
+// WithServerHook 为每个服务器的客户端追加一个钩子，函数参数为服务器名
+func WithServerHook(hook func(server string) Hook) HostOption {
+	return func(h *Host) {
+		h.hooks = append(h.hooks, hook)
+	}
 }
 
-func createMCPClient(config MCPServerConfig) (client.MCPClient, error) {
+func (h *Host) createMCPClient(name string, config MCPServerConfig) (client.MCPClient, error) {
 	switch config.TransportType {
 	case "sse":
-		return client.NewSSEMCPClient(config.Url)
+		// mcp-go 的 SSE 客户端不处理服务器请求，因此不支持 sampling
+		conn, err := client.NewSSEMCPClient(config.Url, client.WithHeaders(config.Headers))
+		if err != nil {
+			return nil, err
+		}
+		if err := conn.Start(context.Background()); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		return conn, nil
 	case "stdio":
-		return client.NewStdioMCPClient(
+		conn, err := NewStdioClient(
 			config.Command,
 			config.Env,
 			config.Args...,
 		)
+		if err != nil {
+			return nil, err
+		}
+		if h.sampler != nil {
+			conn.OnRequest(MethodSamplingCreateMessage, h.sampler.Handler(name))
+		}
+		return conn, nil
 	default:
 		return nil, fmt.Errorf("不支持的传输类型: %s", config.TransportType)
 	}
 }
 
-func NewHost(config *MCPConfig, project *project.Project) (*Host, error) {
+func NewHost(config *MCPConfig, project *project.Project, opts ...HostOption) (*Host, error) {
 	if config == nil {
 		return nil, nil
 	}
@@ -42,6 +85,10 @@ func NewHost(config *MCPConfig, project *project.Project) (*Host, error) {
 	Host := &Host{
 		Clients: make(map[string]*Client),
 		project: project,
+		configs: make(map[string]MCPServerConfig),
+	}
+	for _, opt := range opts {
+		opt(Host)
 	}
 
 	for name, serverConfig := range config.MCPServers {
@@ -49,188 +96,209 @@ func NewHost(config *MCPConfig, project *project.Project) (*Host, error) {
 			continue
 		}
 
-		mcpClient, err := createMCPClient(serverConfig)
-		if err != nil {
-			fmt.Printf("创建客户端 %s 失败: %v\n", name, err)
-			continue
+		name, serverConfig := name, serverConfig
+		dial := func() (client.MCPClient, error) {
+			return Host.createMCPClient(name, serverConfig)
 		}
 
-		Host.Clients[name] = NewClient(mcpClient, project, WithHook(NewLogHook(name)))
+		hooks := []Hook{NewLogHook(name)}
+		for _, hook := range Host.hooks {
+			hooks = append(hooks, hook(name))
+		}
+		Host.Clients[name] = NewLazyClient(dial, project,
+			WithHook(NewCompositeHook(hooks...)),
+			WithTimeout(serverConfig.RequestTimeout()),
+		)
+		Host.configs[name] = serverConfig
 	}
 
 	return Host, nil
 }
 
 func (c *Host) Ping(ctx context.Context) error {
-	var lastErr error
-	for name, client := range c.Clients {
-		if err := client.Ping(ctx); err != nil {
-			fmt.Printf("客户端 %s Ping 失败: %v\n", name, err)
-			lastErr = err
+	errs := make(ServerErrors)
+	for _, name := range c.clientNames() {
+		if err := c.Clients[name].Ping(ctx); err != nil {
+			errs[name] = err
 		}
 	}
-	return lastErr
+	return errs.OrNil()
 }
 
-func (c *Host) ListResources(ctx context.Context, request mcp.ListResourcesRequest) (*mcp.ListResourcesResult, error) {
-	var lastErr error
-	var lastResult *mcp.ListResourcesResult
+// ListResources 合并所有服务器的资源列表，并标记每个资源所属的服务器
+func (c *Host) ListResources(ctx context.Context, request mcp.ListResourcesRequest) ([]ServerResource, error) {
+	errs := make(ServerErrors)
+	var resources []ServerResource
 
-	for name, client := range c.Clients {
-		result, err := client.ListResources(ctx, request)
+	for _, name := range c.clientNames() {
+		result, err := c.Clients[name].ListResources(ctx, request)
 		if err != nil {
-			fmt.Printf("客户端 %s 获取资源列表失败: %v\n", name, err)
-			lastErr = err
+			errs[name] = err
+			continue
+		}
+		if result == nil {
 			continue
 		}
-		lastResult = result
+		for _, resource := range result.Resources {
+			resources = append(resources, ServerResource{Server: name, Resource: resource})
+		}
 	}
-	return lastResult, lastErr
+	return resources, errs.OrNil()
 }
 
-func (c *Host) ListResourceTemplates(ctx context.Context, request mcp.ListResourceTemplatesRequest) (*mcp.ListResourceTemplatesResult, error) {
-	var lastErr error
-	var lastResult *mcp.ListResourceTemplatesResult
+// ListResourceTemplates 合并所有服务器的资源模板，并标记每个模板所属的服务器
+func (c *Host) ListResourceTemplates(ctx context.Context, request mcp.ListResourceTemplatesRequest) ([]ServerResourceTemplate, error) {
+	errs := make(ServerErrors)
+	var templates []ServerResourceTemplate
 
-	for name, client := range c.Clients {
-		result, err := client.ListResourceTemplates(ctx, request)
+	for _, name := range c.clientNames() {
+		result, err := c.Clients[name].ListResourceTemplates(ctx, request)
 		if err != nil {
-			fmt.Printf("客户端 %s 获取资源模板列表失败: %v\n", name, err)
-			lastErr = err
+			errs[name] = err
+			continue
+		}
+		if result == nil {
 			continue
 		}
-		lastResult = result
+		for _, template := range result.ResourceTemplates {
+			templates = append(templates, ServerResourceTemplate{Server: name, ResourceTemplate: template})
+		}
 	}
-	return lastResult, lastErr
+	return templates, errs.OrNil()
 }
 
+// ReadResource 根据 URI 找到提供该资源的服务器并读取
 func (c *Host) ReadResource(ctx context.Context, request mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
-	var lastErr error
-	var lastResult *mcp.ReadResourceResult
-
-	for name, client := range c.Clients {
-		result, err := client.ReadResource(ctx, request)
-		if err != nil {
-			fmt.Printf("客户端 %s 读取资源失败: %v\n", name, err)
-			lastErr = err
-			continue
-		}
-		lastResult = result
+	server, err := c.ResolveResource(ctx, request.Params.URI)
+	if err != nil {
+		return nil, err
 	}
-	return lastResult, lastErr
+	return c.Clients[server].ReadResource(ctx, request)
 }
 
 func (c *Host) Subscribe(ctx context.Context, request mcp.SubscribeRequest) error {
-	var lastErr error
-	for name, client := range c.Clients {
-		if err := client.Subscribe(ctx, request); err != nil {
-			fmt.Printf("客户端 %s 订阅失败: %v\n", name, err)
-			lastErr = err
-		}
+	server, err := c.ResolveResource(ctx, request.Params.URI)
+	if err != nil {
+		return err
 	}
-	return lastErr
+	return c.Clients[server].Subscribe(ctx, request)
 }
 
 func (c *Host) Unsubscribe(ctx context.Context, request mcp.UnsubscribeRequest) error {
-	var lastErr error
-	for name, client := range c.Clients {
-		if err := client.Unsubscribe(ctx, request); err != nil {
-			fmt.Printf("客户端 %s 取消订阅失败: %v\n", name, err)
-			lastErr = err
-		}
+	server, err := c.ResolveResource(ctx, request.Params.URI)
+	if err != nil {
+		return err
 	}
-	return lastErr
+	return c.Clients[server].Unsubscribe(ctx, request)
 }
 
-func (c *Host) ListPrompts(ctx context.Context, request mcp.ListPromptsRequest) (*mcp.ListPromptsResult, error) {
-	var lastErr error
-	var lastResult *mcp.ListPromptsResult
+// ListPrompts 合并所有服务器的提示列表，名称冲突的提示会带上服务器前缀
+func (c *Host) ListPrompts(ctx context.Context, request mcp.ListPromptsRequest) ([]ServerPrompt, error) {
+	errs := make(ServerErrors)
+	names := c.clientNames()
+	listed := make(map[string][]mcp.Prompt, len(names))
+	owners := make(map[string]int)
 
-	for name, client := range c.Clients {
-		result, err := client.ListPrompts(ctx, request)
+	for _, name := range names {
+		result, err := c.Clients[name].ListPrompts(ctx, request)
 		if err != nil {
-			fmt.Printf("客户端 %s 获取提示列表失败: %v\n", name, err)
-			lastErr = err
+			errs[name] = err
 			continue
 		}
-		lastResult = result
+		if result == nil {
+			continue
+		}
+		listed[name] = result.Prompts
+		for _, prompt := range result.Prompts {
+			owners[prompt.Name]++
+		}
+	}
+
+	routes := make(map[string]route)
+	var prompts []ServerPrompt
+	for _, name := range names {
+		for _, prompt := range listed[name] {
+			exposed := prompt.Name
+			if owners[prompt.Name] > 1 {
+				exposed = NamespacedToolName(name, prompt.Name)
+			}
+			routes[exposed] = route{Server: name, Name: prompt.Name}
+			prompt.Name = exposed
+			prompts = append(prompts, ServerPrompt{Server: name, Prompt: prompt})
+		}
 	}
-	return lastResult, lastErr
+
+	c.mu.Lock()
+	c.promptRoutes = routes
+	c.mu.Unlock()
+
+	return prompts, errs.OrNil()
 }
 
+// GetPrompt 将请求路由到拥有该提示的服务器
 func (c *Host) GetPrompt(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
-	var lastErr error
-	var lastResult *mcp.GetPromptResult
-
-	for name, client := range c.Clients {
-		result, err := client.GetPrompt(ctx, request)
-		if err != nil {
-			fmt.Printf("客户端 %s 获取提示失败: %v\n", name, err)
-			lastErr = err
-			continue
-		}
-		lastResult = result
+	r, ok := c.lookupPrompt(request.Params.Name)
+	if !ok {
+		c.ListPrompts(ctx, mcp.ListPromptsRequest{})
+		r, ok = c.lookupPrompt(request.Params.Name)
+	}
+	if !ok {
+		return nil, fmt.Errorf("未找到提示: %s", request.Params.Name)
 	}
-	return lastResult, lastErr
+
+	request.Params.Name = r.Name
+	return c.Clients[r.Server].GetPrompt(ctx, request)
 }
 
 func (c *Host) ListTools(ctx context.Context, request mcp.ListToolsRequest) ([]*mcp.ListToolsResult, error) {
-	var lastErr error
+	errs := make(ServerErrors)
 	var results []*mcp.ListToolsResult
 
-	for name, client := range c.Clients {
-		result, err := client.ListTools(ctx, request)
+	for _, name := range c.clientNames() {
+		result, err := c.Clients[name].ListTools(ctx, request)
 		if err != nil {
-			fmt.Printf("客户端 %s 获取工具列表失败: %v\n", name, err)
-			lastErr = err
+			errs[name] = err
 			continue
 		}
 		results = append(results, result)
 	}
-	return results, lastErr
+	return results, errs.OrNil()
 }
 
+// CallTool 根据工具索引将调用路由到拥有该工具的客户端
 func (c *Host) CallTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	var lastErr error
-	var lastResult *mcp.CallToolResult
-
-	for name, client := range c.Clients {
-		result, err := client.CallTool(ctx, request)
-		if err != nil {
-			fmt.Printf("客户端 %s 调用工具失败: %v\n", name, err)
-			lastErr = err
-			continue
-		}
-		lastResult = result
+	server, toolName, err := c.ResolveTool(ctx, request.Params.Name)
+	if err != nil {
+		return nil, err
 	}
-	return lastResult, lastErr
+
+	client := c.Clients[server]
+	request.Params.Name = toolName
+	return client.CallTool(ctx, request)
 }
 
 func (c *Host) SetLevel(ctx context.Context, request mcp.SetLevelRequest) error {
-	var lastErr error
-	for name, client := range c.Clients {
-		if err := client.SetLevel(ctx, request); err != nil {
-			fmt.Printf("客户端 %s 设置日志级别失败: %v\n", name, err)
-			lastErr = err
+	errs := make(ServerErrors)
+	for _, name := range c.clientNames() {
+		if err := c.Clients[name].SetLevel(ctx, request); err != nil {
+			errs[name] = err
 		}
 	}
-	return lastErr
+	return errs.OrNil()
 }
 
+// Complete 返回第一个成功完成的服务器结果
 func (c *Host) Complete(ctx context.Context, request mcp.CompleteRequest) (*mcp.CompleteResult, error) {
-	var lastErr error
-	var lastResult *mcp.CompleteResult
-
-	for name, client := range c.Clients {
-		result, err := client.Complete(ctx, request)
+	errs := make(ServerErrors)
+	for _, name := range c.clientNames() {
+		result, err := c.Clients[name].Complete(ctx, request)
 		if err != nil {
-			fmt.Printf("客户端 %s 自动完成失败: %v\n", name, err)
-			lastErr = err
+			errs[name] = err
 			continue
 		}
-		lastResult = result
+		return result, nil
 	}
-	return lastResult, lastErr
+	return nil, errs.OrNil()
 }
 
 func (c *Host) OnNotification(handler func(notification mcp.JSONRPCNotification)) {
@@ -253,13 +321,42 @@ func (c *Host) GetAllClients() map[string]*Client {
 	return c.Clients
 }
 
+// IsAutoApproved 判断服务器是否配置了自动批准该工具（原始工具名）
+func (c *Host) IsAutoApproved(server, tool string) bool {
+	if c == nil {
+		return false
+	}
+	config, ok := c.configs[server]
+	if !ok {
+		return false
+	}
+	return config.IsAutoApproved(tool)
+}
+
+// Servers 返回按名称排序的服务器列表
+func (c *Host) Servers() []string {
+	return c.clientNames()
+}
+
+// clientNames 返回按名称排序的客户端列表，保证遍历顺序稳定
+func (c *Host) clientNames() []string {
+	if c == nil {
+		return nil
+	}
+	names := make([]string, 0, len(c.Clients))
+	for name := range c.Clients {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func (c *Host) Close() error {
-	var lastErr error
-	for name, client := range c.Clients {
-		if err := client.Close(); err != nil {
-			fmt.Printf("客户端 %s 关闭失败: %v\n", name, err)
-			lastErr = err
+	errs := make(ServerErrors)
+	for _, name := range c.clientNames() {
+		if err := c.Clients[name].Close(); err != nil {
+			errs[name] = err
 		}
 	}
-	return lastErr
+	return errs.OrNil()
 }
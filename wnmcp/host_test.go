@@ -0,0 +1,178 @@
+package wnmcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestHost(conns map[string]*MockMCPClient) *Host {
+	host := &Host{Clients: make(map[string]*Client)}
+	for name, conn := range conns {
+		host.Clients[name] = &Client{conn: conn}
+	}
+	return host
+}
+
+func TestHost_GetTools(t *testing.T) {
+	fs := new(MockMCPClient)
+	git := new(MockMCPClient)
+	fs.On("ListTools", mock.Anything, mock.Anything).Return(&mcp.ListToolsResult{
+		Tools: []mcp.Tool{mcp.NewTool("read_file"), mcp.NewTool("list_dir")},
+	}, nil)
+	git.On("ListTools", mock.Anything, mock.Anything).Return(&mcp.ListToolsResult{
+		Tools: []mcp.Tool{mcp.NewTool("read_file"), mcp.NewTool("git_log")},
+	}, nil)
+
+	host := newTestHost(map[string]*MockMCPClient{"filesystem": fs, "git": git})
+	tools := host.GetTools(context.Background(), mcp.ListToolsRequest{})
+
+	var names []string
+	for _, tool := range tools {
+		names = append(names, tool.Name)
+	}
+	assert.ElementsMatch(t, []string{"filesystem__read_file", "list_dir", "git__read_file", "git_log"}, names)
+}
+
+func TestHost_CallTool(t *testing.T) {
+	fs := new(MockMCPClient)
+	git := new(MockMCPClient)
+	fs.On("ListTools", mock.Anything, mock.Anything).Return(&mcp.ListToolsResult{
+		Tools: []mcp.Tool{mcp.NewTool("read_file")},
+	}, nil)
+	git.On("ListTools", mock.Anything, mock.Anything).Return(&mcp.ListToolsResult{
+		Tools: []mcp.Tool{mcp.NewTool("read_file"), mcp.NewTool("git_log")},
+	}, nil)
+
+	fsResult := mcp.NewToolResultText("fs")
+	gitResult := mcp.NewToolResultText("git")
+	fs.On("CallTool", mock.Anything, mock.MatchedBy(func(r mcp.CallToolRequest) bool {
+		return r.Params.Name == "read_file"
+	})).Return(fsResult, nil)
+	git.On("CallTool", mock.Anything, mock.MatchedBy(func(r mcp.CallToolRequest) bool {
+		return r.Params.Name == "git_log"
+	})).Return(gitResult, nil)
+
+	host := newTestHost(map[string]*MockMCPClient{"filesystem": fs, "git": git})
+	ctx := context.Background()
+
+	result, err := host.CallTool(ctx, NewToolCallRequest("filesystem__read_file", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, fsResult, result)
+
+	result, err = host.CallTool(ctx, NewToolCallRequest("git_log", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, gitResult, result)
+
+	_, err = host.CallTool(ctx, NewToolCallRequest("missing", nil))
+	assert.Error(t, err)
+
+	fs.AssertNumberOfCalls(t, "CallTool", 1)
+	git.AssertNumberOfCalls(t, "CallTool", 1)
+}
+
+func TestNamespacedToolName(t *testing.T) {
+	assert.Equal(t, "filesystem__read_file", NamespacedToolName("filesystem", "read_file"))
+	assert.Equal(t, "github_com_servers_time__now", NamespacedToolName("github.com/servers/time", "now"))
+}
+
+func TestHost_ListResources(t *testing.T) {
+	fs := new(MockMCPClient)
+	broken := new(MockMCPClient)
+	fs.On("ListResources", mock.Anything, mock.Anything).Return(&mcp.ListResourcesResult{
+		Resources: []mcp.Resource{mcp.NewResource("files://wn", "files")},
+	}, nil)
+	broken.On("ListResources", mock.Anything, mock.Anything).Return(nil, errors.New("connection closed"))
+
+	host := newTestHost(map[string]*MockMCPClient{"filesystem": fs, "broken": broken})
+	resources, err := host.ListResources(context.Background(), mcp.ListResourcesRequest{})
+
+	assert.Len(t, resources, 1)
+	assert.Equal(t, "filesystem", resources[0].Server)
+	assert.Equal(t, "files://wn", resources[0].URI)
+
+	var serverErrs ServerErrors
+	assert.ErrorAs(t, err, &serverErrs)
+	assert.Contains(t, serverErrs, "broken")
+	assert.NotContains(t, serverErrs, "filesystem")
+}
+
+func TestHost_ReadResource(t *testing.T) {
+	fs := new(MockMCPClient)
+	web := new(MockMCPClient)
+	fs.On("ListResources", mock.Anything, mock.Anything).Return(&mcp.ListResourcesResult{
+		Resources: []mcp.Resource{mcp.NewResource("files://wn", "files")},
+	}, nil)
+	fs.On("ListResourceTemplates", mock.Anything, mock.Anything).Return(&mcp.ListResourceTemplatesResult{}, nil)
+	web.On("ListResources", mock.Anything, mock.Anything).Return(&mcp.ListResourcesResult{}, nil)
+	web.On("ListResourceTemplates", mock.Anything, mock.Anything).Return(&mcp.ListResourceTemplatesResult{
+		ResourceTemplates: []mcp.ResourceTemplate{mcp.NewResourceTemplate("https://{host}/{path}", "web")},
+	}, nil)
+
+	webResult := &mcp.ReadResourceResult{}
+	web.On("ReadResource", mock.Anything, mock.Anything).Return(webResult, nil)
+
+	host := newTestHost(map[string]*MockMCPClient{"filesystem": fs, "web": web})
+	result, err := host.ReadResource(context.Background(), NewReadResourceRequest("https://example.com/index", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, webResult, result)
+	fs.AssertNotCalled(t, "ReadResource", mock.Anything, mock.Anything)
+
+	_, err = host.ReadResource(context.Background(), NewReadResourceRequest("unknown://x", nil))
+	assert.Error(t, err)
+}
+
+func TestHost_GetPrompt(t *testing.T) {
+	a := new(MockMCPClient)
+	b := new(MockMCPClient)
+	a.On("ListPrompts", mock.Anything, mock.Anything).Return(&mcp.ListPromptsResult{
+		Prompts: []mcp.Prompt{mcp.NewPrompt("review"), mcp.NewPrompt("explain")},
+	}, nil)
+	b.On("ListPrompts", mock.Anything, mock.Anything).Return(&mcp.ListPromptsResult{
+		Prompts: []mcp.Prompt{mcp.NewPrompt("review")},
+	}, nil)
+
+	bResult := &mcp.GetPromptResult{Description: "b"}
+	b.On("GetPrompt", mock.Anything, mock.MatchedBy(func(r mcp.GetPromptRequest) bool {
+		return r.Params.Name == "review"
+	})).Return(bResult, nil)
+
+	host := newTestHost(map[string]*MockMCPClient{"a": a, "b": b})
+	prompts, err := host.ListPrompts(context.Background(), mcp.ListPromptsRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, prompts, 3)
+
+	result, err := host.GetPrompt(context.Background(), NewPromptRequest("b__review", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, bResult, result)
+	a.AssertNotCalled(t, "GetPrompt", mock.Anything, mock.Anything)
+}
+
+func TestHost_ListServerTools(t *testing.T) {
+	fs := new(MockMCPClient)
+	git := new(MockMCPClient)
+	fs.On("ListTools", mock.Anything, mock.Anything).Return(&mcp.ListToolsResult{
+		Tools: []mcp.Tool{mcp.NewTool("read_file")},
+	}, nil)
+	git.On("ListTools", mock.Anything, mock.Anything).Return(&mcp.ListToolsResult{
+		Tools: []mcp.Tool{mcp.NewTool("read_file"), mcp.NewTool("git_log")},
+	}, nil)
+
+	host := newTestHost(map[string]*MockMCPClient{"filesystem": fs, "git": git})
+	tools, err := host.ListServerTools(context.Background())
+	assert.NoError(t, err)
+
+	servers := make(map[string]string)
+	for _, tool := range tools {
+		servers[tool.Name] = tool.Server
+	}
+	assert.Equal(t, map[string]string{
+		"filesystem__read_file": "filesystem",
+		"git__read_file":        "git",
+		"git_log":               "git",
+	}, servers)
+}
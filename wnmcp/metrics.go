@@ -0,0 +1,162 @@
+package wnmcp
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// maxLatencySamples 每个方法最多保留的耗时样本数，超出后覆盖最早的样本
+const maxLatencySamples = 1000
+
+// MethodStats 单个服务器上某个方法的调用统计
+type MethodStats struct {
+	Server  string        `json:"server"`
+	Method  string        `json:"method"`
+	Count   int           `json:"count"`
+	Errors  int           `json:"errors"`
+	Average time.Duration `json:"average"`
+	P50     time.Duration `json:"p50"`
+	P90     time.Duration `json:"p90"`
+	P99     time.Duration `json:"p99"`
+	Max     time.Duration `json:"max"`
+}
+
+type methodKey struct {
+	server string
+	method string
+}
+
+type methodMetrics struct {
+	count   int
+	errors  int
+	total   time.Duration
+	max     time.Duration
+	samples []time.Duration
+	next    int
+}
+
+// Metrics 按服务器和方法汇总请求次数、错误数和耗时分位数
+type Metrics struct {
+	mu            sync.Mutex
+	methods       map[methodKey]*methodMetrics
+	notifications map[string]int
+}
+
+// NewMetrics 创建空的统计
+func NewMetrics() *Metrics {
+	return &Metrics{
+		methods:       make(map[methodKey]*methodMetrics),
+		notifications: make(map[string]int),
+	}
+}
+
+// Hook 返回指定服务器的 MetricsHook，可用于 WithServerHook
+func (m *Metrics) Hook(server string) Hook {
+	return &MetricsHook{metrics: m, server: server}
+}
+
+// Record 记录一次请求
+func (m *Metrics) Record(server, method string, duration time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := methodKey{server: server, method: method}
+	mm, ok := m.methods[key]
+	if !ok {
+		mm = &methodMetrics{}
+		m.methods[key] = mm
+	}
+
+	mm.count++
+	if err != nil {
+		mm.errors++
+	}
+	mm.total += duration
+	mm.max = max(mm.max, duration)
+	if len(mm.samples) < maxLatencySamples {
+		mm.samples = append(mm.samples, duration)
+	} else {
+		mm.samples[mm.next] = duration
+		mm.next = (mm.next + 1) % maxLatencySamples
+	}
+}
+
+// RecordNotification 记录服务器发来的通知
+func (m *Metrics) RecordNotification(server string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.notifications[server]++
+}
+
+// Notifications 返回每个服务器收到的通知数
+func (m *Metrics) Notifications() map[string]int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	result := make(map[string]int, len(m.notifications))
+	for server, count := range m.notifications {
+		result[server] = count
+	}
+	return result
+}
+
+// Snapshot 返回按服务器和方法排序的统计结果
+func (m *Metrics) Snapshot() []MethodStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := make([]MethodStats, 0, len(m.methods))
+	for key, mm := range m.methods {
+		samples := append([]time.Duration{}, mm.samples...)
+		sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+
+		stats = append(stats, MethodStats{
+			Server:  key.server,
+			Method:  key.method,
+			Count:   mm.count,
+			Errors:  mm.errors,
+			Average: mm.total / time.Duration(mm.count),
+			P50:     percentile(samples, 50),
+			P90:     percentile(samples, 90),
+			P99:     percentile(samples, 99),
+			Max:     mm.max,
+		})
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Server != stats[j].Server {
+			return stats[i].Server < stats[j].Server
+		}
+		return stats[i].Method < stats[j].Method
+	})
+	return stats
+}
+
+// percentile 使用最近秩法计算分位数，sorted 需要已排序
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank, 1)-1]
+}
+
+// MetricsHook 把请求耗时记录到 Metrics 中
+type MetricsHook struct {
+	metrics *Metrics
+	server  string
+}
+
+func (h *MetricsHook) BeforeRequest(ctx context.Context, method string, args interface{}) {}
+
+func (h *MetricsHook) AfterRequest(ctx context.Context, method string, response interface{}, err error) {
+	duration, _ := RequestDuration(ctx)
+	h.metrics.Record(h.server, method, duration, err)
+}
+
+func (h *MetricsHook) OnNotification(notification mcp.JSONRPCNotification) {
+	h.metrics.RecordNotification(h.server)
+}
@@ -0,0 +1,64 @@
+package wnmcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics_Snapshot(t *testing.T) {
+	metrics := NewMetrics()
+	for i := 1; i <= 100; i++ {
+		metrics.Record("fs", "CallTool", time.Duration(i)*time.Millisecond, nil)
+	}
+	metrics.Record("fs", "ListTools", 5*time.Millisecond, errors.New("boom"))
+	metrics.Record("git", "CallTool", time.Millisecond, nil)
+
+	stats := metrics.Snapshot()
+	assert.Len(t, stats, 3)
+
+	call := stats[0]
+	assert.Equal(t, "fs", call.Server)
+	assert.Equal(t, "CallTool", call.Method)
+	assert.Equal(t, 100, call.Count)
+	assert.Equal(t, 0, call.Errors)
+	assert.Equal(t, 50*time.Millisecond, call.P50)
+	assert.Equal(t, 90*time.Millisecond, call.P90)
+	assert.Equal(t, 99*time.Millisecond, call.P99)
+	assert.Equal(t, 100*time.Millisecond, call.Max)
+	assert.Equal(t, 50500*time.Microsecond, call.Average)
+
+	assert.Equal(t, "ListTools", stats[1].Method)
+	assert.Equal(t, 1, stats[1].Errors)
+	assert.Equal(t, "git", stats[2].Server)
+}
+
+func TestMetrics_SampleLimit(t *testing.T) {
+	metrics := NewMetrics()
+	for i := 0; i < maxLatencySamples+10; i++ {
+		metrics.Record("fs", "Ping", time.Millisecond, nil)
+	}
+	stats := metrics.Snapshot()
+	assert.Equal(t, maxLatencySamples+10, stats[0].Count)
+	assert.Len(t, metrics.methods[methodKey{"fs", "Ping"}].samples, maxLatencySamples)
+}
+
+func TestMetricsHook(t *testing.T) {
+	metrics := NewMetrics()
+	hook := metrics.Hook("fs")
+
+	ctx := withRequestStart(context.Background())
+	hook.BeforeRequest(ctx, "CallTool", nil)
+	time.Sleep(2 * time.Millisecond)
+	hook.AfterRequest(ctx, "CallTool", nil, nil)
+	hook.OnNotification(mcp.JSONRPCNotification{})
+
+	stats := metrics.Snapshot()
+	assert.Equal(t, 1, stats[0].Count)
+	assert.GreaterOrEqual(t, stats[0].Max, 2*time.Millisecond)
+	assert.Equal(t, map[string]int{"fs": 1}, metrics.Notifications())
+}
@@ -1,27 +1,87 @@
 package wnmcp
 
-import "github.com/mark3labs/mcp-go/mcp"
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
 
-// TODO 这类还需要处理其他的类型
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/sjzsdu/wn/llm"
+)
+
+// ToolCallResultToString 返回工具调用结果的文本形式，图片等二进制内容以占位符表示
 func ToolCallResultToString(resp *mcp.CallToolResult) string {
 	if resp == nil {
 		return ""
 	}
+	return llm.PartsText(ToolCallResultToParts(resp))
+}
 
-	// 如果没有内容，返回空字符串
-	if len(resp.Content) == 0 {
-		return ""
+// ToolCallResultToParts 把工具调用结果转换为消息内容片段
+// 图片保留原始数据，嵌入资源以带 URI 和 MIME 类型的文本内联
+func ToolCallResultToParts(resp *mcp.CallToolResult) []llm.ContentPart {
+	if resp == nil {
+		return nil
 	}
 
-	// 遍历所有内容并拼接
-	var result string
+	parts := make([]llm.ContentPart, 0, len(resp.Content))
 	for _, content := range resp.Content {
-		// 根据 Content 的定义，它可以是 TextContent, ImageContent, 或 EmbeddedResource
-		// 这里我们主要处理文本内容
-		if textContent, ok := content.(mcp.TextContent); ok {
-			result += textContent.Text
+		switch c := content.(type) {
+		case mcp.ImageContent:
+			parts = append(parts, llm.ContentPart{
+				Type:     llm.ContentTypeImage,
+				MIMEType: c.MIMEType,
+				Data:     c.Data,
+			})
+		default:
+			parts = append(parts, llm.ContentPart{
+				Type: llm.ContentTypeText,
+				Text: ContentToString(content),
+			})
 		}
 	}
+	return parts
+}
+
+// ToolResultMessage 创建发送给大模型的 tool 消息
+func ToolResultMessage(toolCallID string, resp *mcp.CallToolResult) llm.Message {
+	msg := llm.Message{
+		Role:       "tool",
+		ToolCallId: toolCallID,
+	}
+	if resp == nil {
+		return msg
+	}
+	msg.Parts = ToolCallResultToParts(resp)
+	msg.Content = llm.PartsText(msg.Parts)
+	msg.IsError = resp.IsError
+	return msg
+}
 
-	return result
+// ContentToString 把单个 MCP 内容转换为可读文本，二进制内容只显示摘要
+func ContentToString(content mcp.Content) string {
+	switch c := content.(type) {
+	case mcp.TextContent:
+		return c.Text
+	case mcp.ImageContent:
+		return llm.ContentPart{Type: llm.ContentTypeImage, MIMEType: c.MIMEType, Data: c.Data}.String()
+	case mcp.EmbeddedResource:
+		return ResourceContentsToString(c.Resource)
+	default:
+		data, _ := json.Marshal(content)
+		return string(data)
+	}
+}
+
+// ResourceContentsToString 把资源内容转换为带 URI 和 MIME 类型的文本，二进制资源只显示摘要
+func ResourceContentsToString(contents mcp.ResourceContents) string {
+	switch c := contents.(type) {
+	case mcp.TextResourceContents:
+		return fmt.Sprintf("[resource %s %s]\n%s", c.URI, c.MIMEType, c.Text)
+	case mcp.BlobResourceContents:
+		return fmt.Sprintf("[binary %s %s, %d bytes]", c.URI, c.MIMEType, base64.StdEncoding.DecodedLen(len(c.Blob)))
+	default:
+		data, _ := json.Marshal(contents)
+		return string(data)
+	}
 }
@@ -0,0 +1,45 @@
+package wnmcp
+
+import (
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/sjzsdu/wn/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContentToString(t *testing.T) {
+	assert.Equal(t, "hello", ContentToString(mcp.NewTextContent("hello")))
+	assert.Equal(t, "[image image/png, 3 bytes]", ContentToString(mcp.NewImageContent("YWJj", "image/png")))
+	assert.Equal(t, "[binary file:///a.bin application/octet-stream, 3 bytes]", ContentToString(mcp.EmbeddedResource{
+		Type:     "resource",
+		Resource: mcp.BlobResourceContents{URI: "file:///a.bin", MIMEType: "application/octet-stream", Blob: "YWJj"},
+	}))
+	assert.Equal(t, "[resource file:///a.md text/markdown]\n# A", ContentToString(mcp.EmbeddedResource{
+		Type:     "resource",
+		Resource: mcp.TextResourceContents{URI: "file:///a.md", MIMEType: "text/markdown", Text: "# A"},
+	}))
+}
+
+func TestToolResultMessage(t *testing.T) {
+	resp := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.NewTextContent("screenshot"),
+			mcp.NewImageContent("YWJj", "image/png"),
+		},
+		IsError: true,
+	}
+
+	msg := ToolResultMessage("call_1", resp)
+	assert.Equal(t, "tool", msg.Role)
+	assert.Equal(t, "call_1", msg.ToolCallId)
+	assert.True(t, msg.IsError)
+	assert.Equal(t, "screenshot\n[image image/png, 3 bytes]", msg.Content)
+	assert.Equal(t, []llm.ContentPart{
+		{Type: llm.ContentTypeText, Text: "screenshot"},
+		{Type: llm.ContentTypeImage, MIMEType: "image/png", Data: "YWJj"},
+	}, msg.Parts)
+
+	assert.Equal(t, "screenshot\n[image image/png, 3 bytes]", ToolCallResultToString(resp))
+	assert.Equal(t, "", ToolCallResultToString(nil))
+}
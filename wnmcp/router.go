@@ -0,0 +1,131 @@
+package wnmcp
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ToolNameSeparator 服务器名与工具名之间的分隔符，如 filesystem__read_file
+const ToolNameSeparator = "__"
+
+var invalidToolNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// route 记录暴露给 LLM 的工具或提示名所属的服务器及其原始名称
+type route struct {
+	Server string
+	Name   string
+}
+
+// NamespacedToolName 生成带服务器前缀的工具名
+// 服务器名中不被大模型接受的字符会被替换为下划线
+func NamespacedToolName(server, tool string) string {
+	return invalidToolNameChars.ReplaceAllString(server, "_") + ToolNameSeparator + tool
+}
+
+// RefreshTools 重新获取所有客户端的工具并建立工具到客户端的索引
+// 工具名在多个服务器之间冲突时，使用 NamespacedToolName 生成的名称
+func (c *Host) RefreshTools(ctx context.Context) ([]mcp.Tool, error) {
+	if c == nil {
+		return nil, nil
+	}
+
+	errs := make(ServerErrors)
+	names := c.clientNames()
+	listed := make(map[string][]mcp.Tool, len(names))
+	owners := make(map[string]int)
+
+	for _, name := range names {
+		result, err := c.Clients[name].ListTools(ctx, mcp.ListToolsRequest{})
+		if err != nil {
+			errs[name] = err
+			continue
+		}
+		if result == nil {
+			continue
+		}
+		listed[name] = result.Tools
+		for _, tool := range result.Tools {
+			owners[tool.Name]++
+		}
+	}
+
+	routes := make(map[string]route)
+	var tools []mcp.Tool
+	for _, name := range names {
+		for _, tool := range listed[name] {
+			exposed := tool.Name
+			if owners[tool.Name] > 1 {
+				exposed = NamespacedToolName(name, tool.Name)
+			}
+			routes[exposed] = route{Server: name, Name: tool.Name}
+			tool.Name = exposed
+			tools = append(tools, tool)
+		}
+	}
+
+	c.mu.Lock()
+	c.toolRoutes = routes
+	c.mu.Unlock()
+
+	return tools, errs.OrNil()
+}
+
+// ServerTool 带有来源服务器名称的工具，Name 为暴露给大模型的名称
+type ServerTool struct {
+	Server string `json:"server"`
+	mcp.Tool
+}
+
+// ListServerTools 刷新工具索引，并返回每个工具所属的服务器
+func (c *Host) ListServerTools(ctx context.Context) ([]ServerTool, error) {
+	tools, err := c.RefreshTools(ctx)
+	result := make([]ServerTool, 0, len(tools))
+	for _, tool := range tools {
+		r, _ := c.lookupTool(tool.Name)
+		result = append(result, ServerTool{Server: r.Server, Tool: tool})
+	}
+	return result, err
+}
+
+// ResolveTool 返回暴露给 LLM 的工具名对应的服务器名和原始工具名
+// 索引中找不到时会刷新一次索引再查找
+func (c *Host) ResolveTool(ctx context.Context, name string) (string, string, error) {
+	if c == nil {
+		return "", "", fmt.Errorf("未配置 MCP 服务器")
+	}
+
+	if r, ok := c.lookupTool(name); ok {
+		return r.Server, r.Name, nil
+	}
+
+	c.RefreshTools(ctx)
+	if r, ok := c.lookupTool(name); ok {
+		return r.Server, r.Name, nil
+	}
+
+	// 兼容直接使用 server__tool 形式调用未冲突的工具
+	if idx := strings.Index(name, ToolNameSeparator); idx > 0 {
+		server, tool := name[:idx], name[idx+len(ToolNameSeparator):]
+		for _, clientName := range c.clientNames() {
+			if invalidToolNameChars.ReplaceAllString(clientName, "_") != server {
+				continue
+			}
+			if r, ok := c.lookupTool(tool); ok && r.Server == clientName {
+				return r.Server, r.Name, nil
+			}
+		}
+	}
+
+	return "", "", fmt.Errorf("未找到工具: %s", name)
+}
+
+func (c *Host) lookupTool(name string) (route, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	r, ok := c.toolRoutes[name]
+	return r, ok
+}
@@ -0,0 +1,175 @@
+package wnmcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/sjzsdu/wn/llm"
+	"github.com/sjzsdu/wn/share"
+)
+
+// MethodSamplingCreateMessage 服务器请求客户端调用大模型的方法名
+const MethodSamplingCreateMessage = "sampling/createMessage"
+
+// SamplingApprover 询问用户是否允许服务器使用大模型，返回 false 表示拒绝
+type SamplingApprover func(ctx context.Context, server string, req mcp.CreateMessageRequest) (bool, error)
+
+// Sampler 使用本地配置的大模型处理服务器的 sampling 请求
+type Sampler struct {
+	providerName string
+	model        string
+	maxTokens    int
+	approver     SamplingApprover
+
+	mu       sync.Mutex
+	provider llm.Provider
+}
+
+// NewSampler 创建 Sampler，maxTokens 限制单次请求最多生成的 token 数，approver 为 nil 时直接允许
+func NewSampler(providerName, model string, maxTokens int, approver SamplingApprover) *Sampler {
+	if maxTokens <= 0 {
+		maxTokens = share.DEFAULT_SAMPLING_MAX_TOKENS
+	}
+	return &Sampler{
+		providerName: providerName,
+		model:        model,
+		maxTokens:    maxTokens,
+		approver:     approver,
+	}
+}
+
+// getProvider 第一次收到请求时才创建大模型提供商
+func (s *Sampler) getProvider() (llm.Provider, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.provider != nil {
+		return s.provider, nil
+	}
+	provider, err := llm.CreateProvider(s.providerName, nil)
+	if err != nil {
+		return nil, err
+	}
+	if s.model != "" {
+		provider.SetModel(s.model)
+	}
+	s.provider = provider
+	return provider, nil
+}
+
+// Handler 返回可以注册到 StdioClient 的请求处理函数
+func (s *Sampler) Handler(server string) RequestHandler {
+	return func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		var req mcp.CreateMessageRequest
+		req.Method = MethodSamplingCreateMessage
+		if err := json.Unmarshal(params, &req.Params); err != nil {
+			return nil, fmt.Errorf("invalid sampling params: %w", err)
+		}
+		return s.CreateMessage(ctx, server, req)
+	}
+}
+
+// CreateMessage 经用户授权后调用大模型，并把回复转换为 MCP 的结果
+func (s *Sampler) CreateMessage(ctx context.Context, server string, req mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+	messages, err := samplingMessages(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.approver != nil {
+		approved, err := s.approver(ctx, server, req)
+		if err != nil {
+			return nil, err
+		}
+		if !approved {
+			return nil, fmt.Errorf("user rejected sampling request")
+		}
+	}
+
+	provider, err := s.getProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	maxTokens := s.maxTokens
+	if req.Params.MaxTokens > 0 && req.Params.MaxTokens < maxTokens {
+		maxTokens = req.Params.MaxTokens
+	}
+
+	completion := llm.CompletionRequest{
+		Messages:  messages,
+		MaxTokens: maxTokens,
+		Model:     provider.GetModel(),
+	}
+	completion.Stop = req.Params.StopSequences
+	// temperature 为 0 时无法区分是否设置，使用模型默认值
+	if req.Params.Temperature != 0 {
+		temperature := req.Params.Temperature
+		completion.Temperature = &temperature
+	}
+
+	resp, err := provider.Complete(ctx, completion)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mcp.CreateMessageResult{
+		SamplingMessage: mcp.SamplingMessage{
+			Role:    mcp.RoleAssistant,
+			Content: mcp.NewTextContent(resp.Content),
+		},
+		Model:      provider.GetModel(),
+		StopReason: stopReason(resp.FinishReason),
+	}, nil
+}
+
+// samplingMessages 把 sampling 请求转换为大模型消息，目前只支持文本内容
+func samplingMessages(req mcp.CreateMessageRequest) ([]llm.Message, error) {
+	var messages []llm.Message
+	if req.Params.SystemPrompt != "" {
+		messages = append(messages, llm.Message{Role: "system", Content: req.Params.SystemPrompt})
+	}
+
+	for _, msg := range req.Params.Messages {
+		text, err := samplingText(msg.Content)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, llm.Message{Role: string(msg.Role), Content: text})
+	}
+	return messages, nil
+}
+
+func samplingText(content interface{}) (string, error) {
+	switch c := content.(type) {
+	case mcp.TextContent:
+		return c.Text, nil
+	case *mcp.TextContent:
+		return c.Text, nil
+	case map[string]interface{}:
+		if c["type"] != "text" {
+			return "", fmt.Errorf("unsupported sampling content type: %v", c["type"])
+		}
+		text, _ := c["text"].(string)
+		return text, nil
+	default:
+		return "", fmt.Errorf("unsupported sampling content: %T", content)
+	}
+}
+
+// stopReason 把大模型的结束原因转换为 MCP 约定的值
+func stopReason(finishReason string) string {
+	switch finishReason {
+	case "stop", "end_turn", "":
+		return "endTurn"
+	case "length", "max_tokens":
+		return "maxTokens"
+	case "stop_sequence":
+		return "stopSequence"
+	default:
+		return finishReason
+	}
+}
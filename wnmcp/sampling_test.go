@@ -0,0 +1,99 @@
+package wnmcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/sjzsdu/wn/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeProvider struct {
+	request llm.CompletionRequest
+	reply   string
+}
+
+func (p *fakeProvider) Complete(ctx context.Context, req llm.CompletionRequest) (*llm.CompletionResponse, error) {
+	p.request = req
+	return &llm.CompletionResponse{Content: p.reply, FinishReason: "length"}, nil
+}
+
+func (p *fakeProvider) CompleteStream(ctx context.Context, req llm.CompletionRequest, handler llm.StreamHandler) error {
+	return nil
+}
+
+func (p *fakeProvider) AvailableModels() []string    { return []string{"fake-model"} }
+func (p *fakeProvider) GetName() string              { return "fake" }
+func (p *fakeProvider) SetModel(model string) string { return model }
+func (p *fakeProvider) GetModel() string             { return "fake-model" }
+
+func newSamplingRequest(maxTokens int) mcp.CreateMessageRequest {
+	var req mcp.CreateMessageRequest
+	req.Params.SystemPrompt = "be brief"
+	req.Params.MaxTokens = maxTokens
+	req.Params.Messages = []mcp.SamplingMessage{
+		{Role: mcp.RoleUser, Content: map[string]interface{}{"type": "text", "text": "hello"}},
+	}
+	return req
+}
+
+func TestSampler_CreateMessage(t *testing.T) {
+	provider := &fakeProvider{reply: "hi"}
+	sampler := NewSampler("fake", "", 100, nil)
+	sampler.provider = provider
+
+	result, err := sampler.CreateMessage(context.Background(), "demo", newSamplingRequest(500))
+	assert.NoError(t, err)
+	assert.Equal(t, mcp.RoleAssistant, result.Role)
+	assert.Equal(t, "hi", result.Content.(mcp.TextContent).Text)
+	assert.Equal(t, "fake-model", result.Model)
+	assert.Equal(t, "maxTokens", result.StopReason)
+
+	// 请求的 maxTokens 不能超过配置的上限
+	assert.Equal(t, 100, provider.request.MaxTokens)
+	assert.Equal(t, []llm.Message{
+		{Role: "system", Content: "be brief"},
+		{Role: "user", Content: "hello"},
+	}, provider.request.Messages)
+
+	_, err = sampler.CreateMessage(context.Background(), "demo", newSamplingRequest(20))
+	assert.NoError(t, err)
+	assert.Equal(t, 20, provider.request.MaxTokens)
+}
+
+func TestSampler_Rejected(t *testing.T) {
+	provider := &fakeProvider{reply: "hi"}
+	var asked string
+	sampler := NewSampler("fake", "", 0, func(ctx context.Context, server string, req mcp.CreateMessageRequest) (bool, error) {
+		asked = server
+		return false, nil
+	})
+	sampler.provider = provider
+
+	_, err := sampler.CreateMessage(context.Background(), "demo", newSamplingRequest(0))
+	assert.Error(t, err)
+	assert.Equal(t, "demo", asked)
+	assert.Empty(t, provider.request.Messages)
+}
+
+func TestSampler_UnsupportedContent(t *testing.T) {
+	sampler := NewSampler("fake", "", 0, nil)
+	sampler.provider = &fakeProvider{}
+
+	req := newSamplingRequest(0)
+	req.Params.Messages[0].Content = map[string]interface{}{"type": "image", "data": "", "mimeType": "image/png"}
+	_, err := sampler.CreateMessage(context.Background(), "demo", req)
+	assert.ErrorContains(t, err, "image")
+}
+
+func TestSampler_Handler(t *testing.T) {
+	sampler := NewSampler("fake", "", 0, nil)
+	sampler.provider = &fakeProvider{reply: "pong"}
+
+	params, _ := json.Marshal(newSamplingRequest(0).Params)
+	result, err := sampler.Handler("demo")(context.Background(), params)
+	assert.NoError(t, err)
+	assert.Equal(t, "pong", result.(*mcp.CreateMessageResult).Content.(mcp.TextContent).Text)
+}
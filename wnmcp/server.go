@@ -5,10 +5,16 @@ import (
 	"github.com/sjzsdu/wn/share"
 )
 
-var mcpServer *server.MCPServer = server.NewMCPServer(
-	share.MCP_SERVER_NAME,
-	share.VERSION,
-)
+var mcpServer *server.MCPServer = NewMcpServer()
+
+// NewMcpServer 创建一个新的 MCP 服务器
+func NewMcpServer() *server.MCPServer {
+	return server.NewMCPServer(
+		share.MCP_SERVER_NAME,
+		share.VERSION,
+		server.WithResourceCapabilities(true, false),
+	)
+}
 
 func McpServer() *server.MCPServer {
 	return mcpServer
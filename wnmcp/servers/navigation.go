@@ -0,0 +1,370 @@
+package servers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/sjzsdu/wn/project"
+)
+
+// defaultPageSize 分页工具每页默认返回的条目数
+const defaultPageSize = 200
+
+func listDirectory(s *server.MCPServer, project *project.Project, name string) {
+	tool := mcp.NewTool("list_directory", withPagination(
+		mcp.WithDescription("列出目录下的文件和子目录，目录以 / 结尾"),
+		mcp.WithString("path",
+			mcp.Description("相对项目根目录的目录路径，默认为根目录"),
+		),
+		mcp.WithNumber("depth",
+			mcp.Description("递归层数，1 表示只列出直接子项"),
+			mcp.DefaultNumber(1),
+			mcp.Min(1),
+		),
+	)...)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := request.Params.Arguments
+		path := stringArg(args, "path", ".")
+
+		entries, err := project.ListDir(path, intArg(args, "depth", 1))
+		if err != nil {
+			return nil, fmt.Errorf("列出目录失败: %v", err)
+		}
+
+		items := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			if entry.IsDir {
+				items = append(items, entry.Path+"/")
+			} else {
+				items = append(items, fmt.Sprintf("%s (%d bytes)", entry.Path, entry.Size))
+			}
+		}
+		return paginate(items, args, "\n"), nil
+	})
+}
+
+func glob(s *server.MCPServer, project *project.Project, name string) {
+	tool := mcp.NewTool("glob", withPagination(
+		mcp.WithDescription("按 glob 模式查找文件，支持 ** 匹配任意层级目录，如 **/*.go"),
+		mcp.WithString("pattern",
+			mcp.Description("glob 模式，不包含 / 时只匹配文件名"),
+			mcp.Required(),
+		),
+	)...)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := request.Params.Arguments
+		pattern, ok := args["pattern"].(string)
+		if !ok || pattern == "" {
+			return nil, fmt.Errorf("invalid pattern parameter")
+		}
+
+		files, err := project.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("查找文件失败: %v", err)
+		}
+		return paginate(files, args, "\n"), nil
+	})
+}
+
+func grep(s *server.MCPServer, proj *project.Project, name string) {
+	tool := mcp.NewTool("grep", withPagination(
+		mcp.WithDescription("按正则表达式搜索文件内容，匹配行格式为 path:line:text，上下文行格式为 path-line-text"),
+		mcp.WithString("pattern",
+			mcp.Description("Go 正则表达式"),
+			mcp.Required(),
+		),
+		mcp.WithArray("include",
+			mcp.Description("只搜索匹配这些 glob 的文件，如 [\"*.go\"]"),
+			mcp.Items(map[string]interface{}{"type": "string"}),
+		),
+		mcp.WithArray("exclude",
+			mcp.Description("跳过匹配这些 glob 的文件"),
+			mcp.Items(map[string]interface{}{"type": "string"}),
+		),
+		mcp.WithNumber("context",
+			mcp.Description("匹配行前后显示的上下文行数"),
+			mcp.DefaultNumber(0),
+			mcp.Min(0),
+		),
+		mcp.WithBoolean("ignoreCase",
+			mcp.Description("忽略大小写"),
+		),
+	)...)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := request.Params.Arguments
+		pattern, ok := args["pattern"].(string)
+		if !ok || pattern == "" {
+			return nil, fmt.Errorf("invalid pattern parameter")
+		}
+		ignoreCase, _ := args["ignoreCase"].(bool)
+
+		matches, err := proj.Grep(project.GrepOptions{
+			Pattern:    pattern,
+			Include:    stringsArg(args, "include"),
+			Exclude:    stringsArg(args, "exclude"),
+			Context:    intArg(args, "context", 0),
+			IgnoreCase: ignoreCase,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("搜索失败: %v", err)
+		}
+
+		items := make([]string, 0, len(matches))
+		for _, match := range matches {
+			var lines []string
+			for i, line := range match.Before {
+				lines = append(lines, fmt.Sprintf("%s-%d-%s", match.Path, match.Line-len(match.Before)+i, line))
+			}
+			lines = append(lines, fmt.Sprintf("%s:%d:%s", match.Path, match.Line, match.Text))
+			for i, line := range match.After {
+				lines = append(lines, fmt.Sprintf("%s-%d-%s", match.Path, match.Line+1+i, line))
+			}
+			items = append(items, strings.Join(lines, "\n"))
+		}
+
+		separator := "\n"
+		if intArg(args, "context", 0) > 0 {
+			separator = "\n--\n"
+		}
+		return paginate(items, args, separator), nil
+	})
+}
+
+func fileOutline(s *server.MCPServer, project *project.Project, name string) {
+	tool := mcp.NewTool("file_outline", withPagination(
+		mcp.WithDescription("返回文件中的函数、类、接口、变量等符号，来自 wn project 缓存的分析结果"),
+		mcp.WithString("path",
+			mcp.Description("相对项目根目录的文件路径"),
+			mcp.Required(),
+		),
+	)...)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := request.Params.Arguments
+		path, ok := args["path"].(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid path parameter")
+		}
+
+		resp, err := project.CachedLLMResponse(path)
+		if err != nil {
+			return nil, fmt.Errorf("读取文件分析结果失败: %v", err)
+		}
+		if resp == nil || resp.IsNotProgramResponse() {
+			return mcp.NewToolResultText(fmt.Sprintf("没有 %s 的符号信息，请先运行 wn project 生成分析结果", path)), nil
+		}
+
+		items := outlineItems(resp)
+		if resp.Feature != "" {
+			items = append([]string{"feature: " + resp.Feature}, items...)
+		}
+		return paginate(items, args, "\n"), nil
+	})
+}
+
+func projectSummary(s *server.MCPServer, project *project.Project, name string) {
+	tool := mcp.NewTool("project_summary", withPagination(
+		mcp.WithDescription("返回项目整体功能概述及根目录下各文件和目录的功能说明"),
+	)...)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := request.Params.Arguments
+
+		items := []string{"project: " + name}
+		if resp, err := project.CachedLLMResponse("/"); err == nil && resp != nil {
+			items = append(items, "feature: "+resp.Feature)
+		}
+
+		entries, err := project.ListDir(".", 1)
+		if err != nil {
+			return nil, fmt.Errorf("列出目录失败: %v", err)
+		}
+		for _, entry := range entries {
+			label := entry.Path
+			if entry.IsDir {
+				label += "/"
+			}
+			resp, err := project.CachedLLMResponse(entry.Path)
+			if err != nil || resp == nil || resp.IsNotProgramResponse() || resp.Feature == "" {
+				items = append(items, label)
+				continue
+			}
+			items = append(items, fmt.Sprintf("%s: %s", label, resp.Feature))
+		}
+		return paginate(items, args, "\n"), nil
+	})
+}
+
+func applyEdit(s *server.MCPServer, proj *project.Project, name string) {
+	tool := mcp.NewTool("apply_edit",
+		mcp.WithDescription("通过查找替换修改文件，oldText 必须在文件中唯一出现，除非设置 replaceAll，返回修改的 diff"),
+		mcp.WithString("path",
+			mcp.Description("相对项目根目录的文件路径"),
+			mcp.Required(),
+		),
+		mcp.WithArray("edits",
+			mcp.Description("按顺序应用的编辑列表"),
+			mcp.Required(),
+			mcp.MinItems(1),
+			mcp.Items(map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"oldText":    map[string]interface{}{"type": "string", "description": "要替换的原文，需包含足够的上下文"},
+					"newText":    map[string]interface{}{"type": "string", "description": "替换后的文本"},
+					"replaceAll": map[string]interface{}{"type": "boolean", "description": "替换所有出现的位置"},
+				},
+				"required": []string{"oldText", "newText"},
+			}),
+		),
+		mcp.WithBoolean("dryRun",
+			mcp.Description("为 true 时只返回差异预览，不写入文件"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := request.Params.Arguments
+		path, ok := args["path"].(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid path parameter")
+		}
+
+		var edits []project.Edit
+		raw, err := json.Marshal(args["edits"])
+		if err != nil || json.Unmarshal(raw, &edits) != nil || len(edits) == 0 {
+			return nil, fmt.Errorf("invalid edits parameter")
+		}
+		dryRun, _ := args["dryRun"].(bool)
+
+		rel, old, err := readDisk(proj, path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, fmt.Errorf("文件不存在: %s", path)
+			}
+			return nil, fmt.Errorf("读取文件失败: %v", err)
+		}
+
+		content, err := project.ApplyEdits(old, edits)
+		if err != nil {
+			return nil, err
+		}
+		return saveWithDiff(proj, path, rel, old, content, dryRun)
+	})
+}
+
+// outlineItems 将分析结果展开为每行一个符号
+func outlineItems(resp *project.LLMResponse) []string {
+	var items []string
+	for _, f := range resp.Functions {
+		items = append(items, fmt.Sprintf("func %s(%s) %s: %s", f.Name, f.Parameters, f.ReturnType, f.Feature))
+	}
+	for _, c := range resp.Classes {
+		items = append(items, fmt.Sprintf("class %s: %s", c.Name, c.Feature))
+		for _, v := range c.Variables {
+			items = append(items, fmt.Sprintf("  var %s %s: %s", v.Name, v.Type, v.Feature))
+		}
+		for _, m := range c.Methods {
+			items = append(items, fmt.Sprintf("  method %s(%s) %s: %s", m.Name, m.Parameters, m.ReturnType, m.Feature))
+		}
+	}
+	for _, i := range resp.Interfaces {
+		items = append(items, fmt.Sprintf("interface %s: %s", i.Name, i.Feature))
+		for _, m := range i.Methods {
+			items = append(items, fmt.Sprintf("  method %s(%s) %s: %s", m.Name, m.Parameters, m.ReturnType, m.Feature))
+		}
+	}
+	for _, v := range resp.Variables {
+		items = append(items, fmt.Sprintf("var %s %s: %s", v.Name, v.Type, v.Feature))
+	}
+	for _, s := range resp.OtherSymbols {
+		items = append(items, fmt.Sprintf("%s %s: %s", s.Type, s.Name, s.Feature))
+	}
+	return items
+}
+
+// withPagination 为工具添加 offset 和 limit 分页参数
+func withPagination(opts ...mcp.ToolOption) []mcp.ToolOption {
+	return append(opts,
+		mcp.WithNumber("offset",
+			mcp.Description("跳过的条目数，用于获取下一页"),
+			mcp.DefaultNumber(0),
+			mcp.Min(0),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("每页最多返回的条目数"),
+			mcp.DefaultNumber(defaultPageSize),
+			mcp.Min(1),
+		),
+	)
+}
+
+// paginate 按 offset 和 limit 截取结果，还有剩余条目时在末尾提示下一页的 offset
+func paginate(items []string, args map[string]interface{}, separator string) *mcp.CallToolResult {
+	total := len(items)
+	if total == 0 {
+		return mcp.NewToolResultText("没有找到结果")
+	}
+
+	offset := intArg(args, "offset", 0)
+	limit := intArg(args, "limit", defaultPageSize)
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	if offset >= total {
+		return mcp.NewToolResultText(fmt.Sprintf("offset %d 超出结果总数 %d", offset, total))
+	}
+
+	end := min(total, offset+limit)
+	text := strings.Join(items[offset:end], separator)
+	if end < total {
+		text += fmt.Sprintf("\n\n[显示第 %d-%d 条，共 %d 条，使用 offset=%d 获取下一页]", offset+1, end, total, end)
+	}
+	return mcp.NewToolResultText(text)
+}
+
+func intArg(args map[string]interface{}, name string, def int) int {
+	switch v := args[name].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	}
+	return def
+}
+
+func stringArg(args map[string]interface{}, name, def string) string {
+	if v, ok := args[name].(string); ok && v != "" {
+		return v
+	}
+	return def
+}
+
+func stringsArg(args map[string]interface{}, name string) []string {
+	switch v := args[name].(type) {
+	case []interface{}:
+		result := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok && s != "" {
+				result = append(result, s)
+			}
+		}
+		return result
+	case []string:
+		return v
+	case string:
+		if v != "" {
+			return strings.Split(v, ",")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package servers
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/sjzsdu/wn/helper"
+	"github.com/sjzsdu/wn/project"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaginate(t *testing.T) {
+	items := []string{"a", "b", "c", "d", "e"}
+
+	tests := []struct {
+		name  string
+		items []string
+		args  map[string]interface{}
+		want  string
+	}{
+		{"empty", nil, nil, "没有找到结果"},
+		{"default page", items, nil, "a\nb\nc\nd\ne"},
+		{"first page", items, map[string]interface{}{"limit": 2.0}, "a\nb\n\n[显示第 1-2 条，共 5 条，使用 offset=2 获取下一页]"},
+		{"next page", items, map[string]interface{}{"offset": 2.0, "limit": 2.0}, "c\nd\n\n[显示第 3-4 条，共 5 条，使用 offset=4 获取下一页]"},
+		{"last page", items, map[string]interface{}{"offset": 4.0, "limit": 2.0}, "e"},
+		{"limit beyond total", items, map[string]interface{}{"offset": 3.0, "limit": 100.0}, "d\ne"},
+		{"negative offset", items, map[string]interface{}{"offset": -1.0, "limit": 1.0}, "a\n\n[显示第 1-1 条，共 5 条，使用 offset=1 获取下一页]"},
+		{"zero limit uses default", items, map[string]interface{}{"limit": 0.0}, "a\nb\nc\nd\ne"},
+		{"offset at total", items, map[string]interface{}{"offset": 5.0}, "offset 5 超出结果总数 5"},
+		{"offset beyond total", items, map[string]interface{}{"offset": 9.0}, "offset 9 超出结果总数 5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := paginate(tt.items, tt.args, "\n")
+			assert.Equal(t, tt.want, resultText(t, result))
+		})
+	}
+}
+
+func TestPaginate_DefaultPageSize(t *testing.T) {
+	items := make([]string, defaultPageSize+1)
+	for i := range items {
+		items[i] = "x"
+	}
+	text := resultText(t, paginate(items, nil, "\n"))
+	assert.True(t, strings.HasSuffix(text, "使用 offset=200 获取下一页]"))
+}
+
+func TestNavigationTools(t *testing.T) {
+	root := t.TempDir()
+	for _, file := range []string{"a.go", "b.go", "c.txt", "pkg/d.go", "pkg/e.go"} {
+		path := filepath.Join(root, file)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte("package x\n// TODO "+file+"\n"), 0644))
+	}
+	proj, err := project.BuildProjectTree(root, helper.WalkDirOptions{DisableGitIgnore: true})
+	require.NoError(t, err)
+	s := server.NewMCPServer("test", "1.0.0")
+	NewTool(s, proj)
+
+	tests := []struct {
+		name string
+		tool string
+		args map[string]interface{}
+		want string
+	}{
+		{"glob first page", "glob", map[string]interface{}{"pattern": "**/*.go", "limit": 2}, "a.go\nb.go\n\n[显示第 1-2 条，共 4 条，使用 offset=2 获取下一页]"},
+		{"glob next page", "glob", map[string]interface{}{"pattern": "**/*.go", "offset": 2, "limit": 2}, "pkg/d.go\npkg/e.go"},
+		{"glob out of range", "glob", map[string]interface{}{"pattern": "**/*.go", "offset": 10}, "offset 10 超出结果总数 4"},
+		{"glob no match", "glob", map[string]interface{}{"pattern": "*.md"}, "没有找到结果"},
+		{"list directory page", "list_directory", map[string]interface{}{"path": "pkg", "limit": 1}, "pkg/d.go (27 bytes)\n\n[显示第 1-1 条，共 2 条，使用 offset=1 获取下一页]"},
+		{"grep page", "grep", map[string]interface{}{"pattern": "TODO", "include": []string{"*.go"}, "offset": 3}, "pkg/e.go:2:// TODO pkg/e.go"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, callTool(t, s, tt.tool, tt.args))
+		})
+	}
+}
+
+// callTool 通过 JSON-RPC 调用服务器上的工具，返回文本结果
+func callTool(t *testing.T, s *server.MCPServer, name string, args map[string]interface{}) string {
+	message, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "tools/call",
+		"params":  map[string]interface{}{"name": name, "arguments": args},
+	})
+	require.NoError(t, err)
+
+	resp, ok := s.HandleMessage(context.Background(), message).(mcp.JSONRPCResponse)
+	require.True(t, ok, "tool call failed")
+	result := resp.Result.(mcp.CallToolResult)
+	return resultText(t, &result)
+}
+
+func resultText(t *testing.T, result *mcp.CallToolResult) string {
+	require.Len(t, result.Content, 1)
+	text, ok := result.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	return text.Text
+}
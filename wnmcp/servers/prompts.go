@@ -2,106 +2,73 @@ package servers
 
 import (
 	"context"
-	"fmt"
+	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/sjzsdu/wn/agent"
 	"github.com/sjzsdu/wn/project"
-	"github.com/sjzsdu/wn/wnmcp"
 )
 
-func NewPrompt(project *project.Project) {
-	name := project.GetName()
+// maxPromptDescription 没有元数据描述时，从正文截取的描述的最大长度
+const maxPromptDescription = 80
 
-	greeting(project, name)
-	codeReview(project, name)
-	queryBuilder(project, name)
+// NewPrompt 将系统和用户 agent 发布为 MCP 提示
+func NewPrompt(s *server.MCPServer, project *project.Project) {
+	for _, a := range agent.AllAgents() {
+		agentPrompt(s, a)
+	}
 }
 
-func greeting(project *project.Project, name string) {
-	greetingPrompt := mcp.NewPrompt("greeting",
-		mcp.WithPromptDescription("友好的问候提示"),
-		mcp.WithArgument("name",
-			mcp.ArgumentDescription("要问候的人的名字"),
-		),
-	)
-
-	wnmcp.McpServer().AddPrompt(greetingPrompt, func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
-		userName := request.Params.Arguments["name"]
-		if userName == "" {
-			userName = "朋友"
+func agentPrompt(s *server.MCPServer, a agent.Agent) {
+	opts := []mcp.PromptOption{
+		mcp.WithPromptDescription(agentDescription(a)),
+	}
+	for _, arg := range a.Arguments {
+		argOpts := []mcp.ArgumentOption{
+			mcp.ArgumentDescription(arg.Description),
 		}
-
-		result := &mcp.GetPromptResult{
-			Description: "友好的问候",
-			Messages: []mcp.PromptMessage{
-				{
-					Role: mcp.RoleAssistant,
-					Content: mcp.TextContent{
-						Text: fmt.Sprintf("你好，%s！今天我能帮你什么？", userName),
-					},
-				},
-			},
+		if arg.Required {
+			argOpts = append(argOpts, mcp.RequiredArgument())
 		}
-		return result, nil
-	})
-}
+		opts = append(opts, mcp.WithArgument(arg.Name, argOpts...))
+	}
 
-func codeReview(project *project.Project, name string) {
-	codeReviewPrompt := mcp.NewPrompt("code_review",
-		mcp.WithPromptDescription("代码审查辅助"),
-		mcp.WithArgument("pr_number",
-			mcp.ArgumentDescription("要审查的PR编号"),
-			mcp.RequiredArgument(),
-		),
-	)
+	s.AddPrompt(mcp.NewPrompt(a.Name, opts...), func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		// 重新获取 agent，使用户 agent 的修改无需重启服务即可生效
+		current, ok := agent.GetAgent(a.Name)
+		if !ok {
+			current = a
+		}
 
-	wnmcp.McpServer().AddPrompt(codeReviewPrompt, func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
-		prNumber := request.Params.Arguments["pr_number"]
-		if prNumber == "" {
-			return nil, fmt.Errorf("需要提供PR编号")
+		text, err := current.Render(request.Params.Arguments)
+		if err != nil {
+			return nil, err
 		}
 
-		result := &mcp.GetPromptResult{
-			Description: "代码审查辅助",
+		return &mcp.GetPromptResult{
+			Description: agentDescription(current),
 			Messages: []mcp.PromptMessage{
-				{
-					Role: "system",
-					Content: mcp.TextContent{
-						Text: "我是一个代码审查助手。我会审查变更并提供建设性的反馈。",
-					},
-				},
+				mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(text)),
 			},
-		}
-		return result, nil
+		}, nil
 	})
 }
 
-func queryBuilder(project *project.Project, name string) {
-	queryBuilderPrompt := mcp.NewPrompt("query_builder",
-		mcp.WithPromptDescription("SQL查询构建辅助"),
-		mcp.WithArgument("table",
-			mcp.ArgumentDescription("要查询的表名"),
-			mcp.RequiredArgument(),
-		),
-	)
-
-	wnmcp.McpServer().AddPrompt(queryBuilderPrompt, func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
-		tableName := request.Params.Arguments["table"]
-		if tableName == "" {
-			return nil, fmt.Errorf("需要提供表名")
+// agentDescription 返回元数据中的描述，没有时使用正文的第一行
+func agentDescription(a agent.Agent) string {
+	if a.Description != "" {
+		return a.Description
+	}
+	for _, line := range strings.Split(a.Body, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(line, "# "))
+		if line == "" {
+			continue
 		}
-
-		result := &mcp.GetPromptResult{
-			Description: "SQL查询构建辅助",
-			Messages: []mcp.PromptMessage{
-				{
-					Role: "system",
-					Content: mcp.TextContent{
-						Text: "我是一个SQL专家。我会帮助构建高效和安全的查询。",
-					},
-				},
-			},
+		if runes := []rune(line); len(runes) > maxPromptDescription {
+			line = string(runes[:maxPromptDescription]) + "..."
 		}
-		return result, nil
-	})
+		return line
+	}
+	return a.Name
 }
@@ -5,22 +5,23 @@ import (
 	"encoding/json"
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
 	"github.com/sjzsdu/wn/helper"
 	"github.com/sjzsdu/wn/project"
 	"github.com/sjzsdu/wn/wnmcp"
 )
 
-func NewResource(project *project.Project) {
-	name := project.GetName()
-
-	filesResouce(project, name)
-	statsResource(project, name)
-	templateResource(project, name)
+// NewResource 注册项目的文件列表、统计信息和文件内容资源，name 为资源 URI 的命名空间
+func NewResource(s *server.MCPServer, project *project.Project, name string) {
+	filesResouce(s, project, name)
+	statsResource(s, project, name)
+	templateResource(s, project, name)
 }
 
-func filesResouce(project *project.Project, name string) {
+func filesResouce(s *server.MCPServer, project *project.Project, name string) {
 	// 添加项目文件列表资源
 	fileListResource := mcp.NewResource(
 		"files://"+name,
@@ -29,7 +30,7 @@ func filesResouce(project *project.Project, name string) {
 		mcp.WithMIMEType("application/json"),
 	)
 
-	wnmcp.McpServer().AddResource(fileListResource, func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	s.AddResource(fileListResource, func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
 		files, _ := project.GetAllFiles()
 
 		fileList, err := json.Marshal(files)
@@ -39,7 +40,7 @@ func filesResouce(project *project.Project, name string) {
 
 		return []mcp.ResourceContents{
 			mcp.TextResourceContents{
-				URI:      "files://list",
+				URI:      request.Params.URI,
 				MIMEType: "application/json",
 				Text:     string(fileList),
 			},
@@ -47,21 +48,23 @@ func filesResouce(project *project.Project, name string) {
 	})
 }
 
-func templateResource(project *project.Project, name string) {
+func templateResource(s *server.MCPServer, project *project.Project, name string) {
 
-	// 添加动态资源模板
+	// 添加动态资源模板，{+path} 可以匹配包含 / 的路径
 	template := mcp.NewResourceTemplate(
-		name+"://{path}",
+		name+"://{+path}",
 		"Project Files",
 		mcp.WithTemplateDescription("Access project files"),
 		mcp.WithTemplateMIMEType("text/plain"),
 	)
 
 	// 添加资源处理器
-	wnmcp.McpServer().AddResourceTemplate(template, func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	s.AddResourceTemplate(template, func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
 		// 从 URI 中提取文件路径
-		fmt.Println("request.Params.URI: ", request.Params.URI)
-		filePath := request.Params.URI
+		filePath, err := parseFileURI(template, request.Params.URI)
+		if err != nil {
+			return nil, err
+		}
 		content, err := project.ReadFile(filePath)
 
 		if err != nil {
@@ -81,7 +84,7 @@ func templateResource(project *project.Project, name string) {
 	})
 }
 
-func statsResource(project *project.Project, name string) {
+func statsResource(s *server.MCPServer, project *project.Project, name string) {
 	// 添加项目统计信息资源
 	statsResource := mcp.NewResource(
 		"stats://"+name,
@@ -90,7 +93,7 @@ func statsResource(project *project.Project, name string) {
 		mcp.WithMIMEType("application/json"),
 	)
 
-	wnmcp.McpServer().AddResource(statsResource, func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	s.AddResource(statsResource, func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
 		files, _ := project.GetAllFiles()
 
 		// 统计各类型文件数量
@@ -119,3 +122,33 @@ func statsResource(project *project.Project, name string) {
 		}, nil
 	})
 }
+
+// NotifyChanges 通知订阅了变化文件的客户端，文件增删时同时通知文件列表和统计信息的订阅者
+func NotifyChanges(subs *wnmcp.Subscriptions, name string, changes []project.Change) {
+	listChanged := false
+	for _, change := range changes {
+		subs.Notify(fileURI(name, change.Path))
+		if change.Type != project.FileModified {
+			listChanged = true
+		}
+	}
+	if listChanged {
+		subs.Notify("files://" + name)
+		subs.Notify("stats://" + name)
+	}
+}
+
+// fileURI 返回文件对应的资源 URI
+func fileURI(name, path string) string {
+	return name + "://" + strings.TrimPrefix(filepath.ToSlash(path), "/")
+}
+
+// parseFileURI 从资源 URI 中解析出项目内的文件路径
+func parseFileURI(template mcp.ResourceTemplate, uri string) (string, error) {
+	values := template.URITemplate.Match(uri)
+	path := strings.TrimPrefix(values.Get("path").String(), "/")
+	if path == "" {
+		return "", fmt.Errorf("invalid resource uri: %s", uri)
+	}
+	return path, nil
+}
@@ -4,20 +4,28 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/sjzsdu/wn/helper"
 	"github.com/sjzsdu/wn/project"
-	"github.com/sjzsdu/wn/wnmcp"
 )
 
-func NewTool(project *project.Project) {
+func NewTool(s *server.MCPServer, project *project.Project) {
 	name := project.GetName()
 
-	read(project, name)
-	write(project, name)
+	read(s, project, name)
+	write(s, project, name)
+	listDirectory(s, project, name)
+	glob(s, project, name)
+	grep(s, project, name)
+	fileOutline(s, project, name)
+	projectSummary(s, project, name)
+	applyEdit(s, project, name)
 }
 
-func read(project *project.Project, name string) {
+func read(s *server.MCPServer, project *project.Project, name string) {
 	readFileTool := mcp.NewTool("readFile",
 		mcp.WithDescription("读取指定文件的内容"),
 		mcp.WithString("path",
@@ -26,7 +34,7 @@ func read(project *project.Project, name string) {
 		),
 	)
 
-	wnmcp.McpServer().AddTool(readFileTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.AddTool(readFileTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		path, ok := request.Params.Arguments["path"].(string)
 		if !ok {
 			return nil, fmt.Errorf("invalid path parameter")
@@ -40,7 +48,7 @@ func read(project *project.Project, name string) {
 	})
 }
 
-func write(project *project.Project, name string) {
+func write(s *server.MCPServer, project *project.Project, name string) {
 	writeFileTool := mcp.NewTool("writeFile",
 		mcp.WithDescription("写入内容到指定文件"),
 		mcp.WithString("path",
@@ -51,9 +59,12 @@ func write(project *project.Project, name string) {
 			mcp.Description("要写入的内容"),
 			mcp.Required(),
 		),
+		mcp.WithBoolean("dryRun",
+			mcp.Description("为 true 时只返回差异预览，不写入文件"),
+		),
 	)
 
-	wnmcp.McpServer().AddTool(writeFileTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.AddTool(writeFileTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		path, ok := request.Params.Arguments["path"].(string)
 		if !ok {
 			return nil, fmt.Errorf("invalid path parameter")
@@ -64,10 +75,41 @@ func write(project *project.Project, name string) {
 			return nil, fmt.Errorf("invalid content parameter")
 		}
 
-		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
-			return nil, fmt.Errorf("写入文件失败: %v", err)
+		dryRun, _ := request.Params.Arguments["dryRun"].(bool)
+
+		rel, old, err := readDisk(project, path)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("读取文件失败: %v", err)
 		}
 
-		return mcp.NewToolResultText(fmt.Sprintf("成功写入文件: %s", path)), nil
+		return saveWithDiff(project, path, rel, old, content, dryRun)
 	})
 }
+
+// readDisk 读取项目目录内文件在磁盘上的当前内容，返回相对路径
+func readDisk(project *project.Project, path string) (string, string, error) {
+	abs, rel, err := project.ResolvePath(path)
+	if err != nil {
+		return "", "", err
+	}
+	rel = filepath.ToSlash(rel)
+	content, err := os.ReadFile(abs)
+	return rel, string(content), err
+}
+
+// saveWithDiff 生成 diff 并写入文件，dryRun 时只返回 diff
+func saveWithDiff(project *project.Project, path, rel, old, content string, dryRun bool) (*mcp.CallToolResult, error) {
+	diff := helper.UnifiedDiff(rel, old, content)
+	if diff == "" {
+		return mcp.NewToolResultText(fmt.Sprintf("文件内容未变化: %s", rel)), nil
+	}
+	if dryRun {
+		return mcp.NewToolResultText(diff), nil
+	}
+
+	if err := project.SaveFile(path, []byte(content)); err != nil {
+		return nil, fmt.Errorf("写入文件失败: %v", err)
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("成功写入文件: %s\n%s", rel, diff)), nil
+}
@@ -0,0 +1,42 @@
+package servers
+
+import (
+	"context"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/sjzsdu/wn/helper"
+	"github.com/sjzsdu/wn/project"
+	"github.com/sjzsdu/wn/wnmcp"
+)
+
+// Workspace 对外提供 MCP 服务的一个项目，拥有独立的服务器和资源 URI 命名空间
+type Workspace struct {
+	Name    string
+	Project *project.Project
+	Server  *server.MCPServer
+	Subs    *wnmcp.Subscriptions
+}
+
+// NewWorkspace 为项目创建独立的 MCP 服务器，并注册资源、工具和提示
+func NewWorkspace(name string, proj *project.Project) *Workspace {
+	s := wnmcp.NewMcpServer()
+	NewResource(s, proj, name)
+	NewTool(s, proj)
+	NewPrompt(s, proj)
+
+	return &Workspace{
+		Name:    name,
+		Project: proj,
+		Server:  s,
+		Subs:    wnmcp.NewSubscriptions(s),
+	}
+}
+
+// Watch 轮询监控项目目录，并通知订阅了变化资源的客户端，直到 ctx 结束
+func (w *Workspace) Watch(ctx context.Context, options helper.WalkDirOptions, interval time.Duration) {
+	watcher := project.NewWatcher(w.Project, options, interval)
+	watcher.Run(ctx, func(changes []project.Change) {
+		NotifyChanges(w.Subs, w.Name, changes)
+	})
+}
@@ -0,0 +1,134 @@
+package wnmcp
+
+import (
+	"context"
+	"time"
+)
+
+// State 表示 MCP 服务器连接的状态
+type State string
+
+const (
+	StateIdle     State = "idle"     // 尚未使用，未启动
+	StateStarting State = "starting" // 正在连接和初始化
+	StateReady    State = "ready"    // 可以正常使用
+	StateFailed   State = "failed"   // 启动失败或连接断开，等待重连
+	StateStopped  State = "stopped"  // 已关闭
+)
+
+// Status 表示 MCP 服务器的当前状态
+type Status struct {
+	State     State     `json:"state"`
+	LastError string    `json:"lastError,omitempty"`
+	Since     time.Time `json:"since"`
+	Failures  int       `json:"failures,omitempty"`
+}
+
+// Status 返回客户端的当前状态
+func (c *Client) Status() Status {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	status := c.status
+	status.Failures = c.failures
+	return status
+}
+
+func (c *Client) setState(state State, lastError string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.status = Status{State: state, LastError: lastError, Since: time.Now()}
+}
+
+func (c *Client) setReady() {
+	c.mu.Lock()
+	c.failures = 0
+	c.retryAt = time.Time{}
+	c.mu.Unlock()
+	c.setState(StateReady, "")
+}
+
+// setFailed 记录错误并关闭连接，下一次请求在退避时间之后重新连接
+func (c *Client) setFailed(err error) {
+	c.mu.Lock()
+	c.failures++
+	c.retryAt = time.Now().Add(reconnectDelay(c.failures))
+	var conn = c.conn
+	if c.dial != nil {
+		c.conn = nil
+	} else {
+		conn = nil
+	}
+	c.status = Status{State: StateFailed, LastError: err.Error(), Since: time.Now()}
+	c.mu.Unlock()
+
+	if conn != nil {
+		conn.Close()
+	}
+}
+
+// reconnectDelay 返回第 failures 次失败后的等待时间
+func reconnectDelay(failures int) time.Duration {
+	if failures <= 1 {
+		return 0
+	}
+	delay := reconnectBaseDelay << (failures - 2)
+	if delay <= 0 || delay > reconnectMaxDelay {
+		return reconnectMaxDelay
+	}
+	return delay
+}
+
+// checkHealth 对已启动的客户端执行 Ping，失败的客户端到达重连时间后尝试重新连接
+func (c *Client) checkHealth(ctx context.Context) {
+	status := c.Status()
+	switch status.State {
+	case StateReady:
+		// Ping 遇到连接错误时已经标记失败，其他错误在这里标记
+		if err := c.Ping(ctx); err != nil && c.Status().State == StateReady {
+			c.setFailed(err)
+		}
+	case StateFailed:
+		c.mu.Lock()
+		due := c.dial != nil && !time.Now().Before(c.retryAt)
+		c.mu.Unlock()
+		if due {
+			c.connection(ctx)
+		}
+	}
+}
+
+// ServerStatus 带有服务器名称的状态
+type ServerStatus struct {
+	Server string `json:"server"`
+	Status
+}
+
+// Status 返回按名称排序的所有服务器状态
+func (c *Host) Status() []ServerStatus {
+	var statuses []ServerStatus
+	for _, name := range c.clientNames() {
+		statuses = append(statuses, ServerStatus{Server: name, Status: c.Clients[name].Status()})
+	}
+	return statuses
+}
+
+// StartHealthCheck 定期检查服务器状态，直到 ctx 结束
+func (c *Host) StartHealthCheck(ctx context.Context, interval time.Duration) {
+	if c == nil || interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				for _, name := range c.clientNames() {
+					c.Clients[name].checkHealth(ctx)
+				}
+			}
+		}
+	}()
+}
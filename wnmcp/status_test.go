@@ -0,0 +1,156 @@
+package wnmcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// closableConn 可以模拟进程退出的连接
+type closableConn struct {
+	*MockMCPClient
+	done   chan struct{}
+	closed int
+	ping   func(ctx context.Context) error
+}
+
+func (c *closableConn) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *closableConn) Close() error {
+	c.closed++
+	return nil
+}
+
+func (c *closableConn) Ping(ctx context.Context) error {
+	if c.ping != nil {
+		return c.ping(ctx)
+	}
+	return nil
+}
+
+func newClosableConn() *closableConn {
+	conn := &closableConn{MockMCPClient: new(MockMCPClient), done: make(chan struct{})}
+	conn.On("Initialize", mock.Anything, mock.Anything).Return(&mcp.InitializeResult{}, nil)
+	conn.On("ListTools", mock.Anything, mock.Anything).Return(&mcp.ListToolsResult{}, nil)
+	return conn
+}
+
+func TestLazyClient_StartsOnFirstUse(t *testing.T) {
+	dials := 0
+	conn := newClosableConn()
+	c := NewLazyClient(func() (client.MCPClient, error) {
+		dials++
+		return conn, nil
+	}, nil)
+
+	assert.Equal(t, StateIdle, c.Status().State)
+	assert.Equal(t, 0, dials)
+
+	_, err := c.ListTools(context.Background(), mcp.ListToolsRequest{})
+	assert.NoError(t, err)
+	_, err = c.ListTools(context.Background(), mcp.ListToolsRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, dials)
+	assert.Equal(t, StateReady, c.Status().State)
+}
+
+func TestLazyClient_InitializeOnce(t *testing.T) {
+	conn := newClosableConn()
+	c := NewLazyClient(func() (client.MCPClient, error) {
+		return conn, nil
+	}, nil)
+
+	for i := 0; i < 2; i++ {
+		result, err := c.Initialize(context.Background(), NewInitializeRequest())
+		assert.NoError(t, err)
+		assert.NotNil(t, result)
+	}
+	conn.AssertNumberOfCalls(t, "Initialize", 1)
+	assert.Equal(t, StateReady, c.Status().State)
+}
+
+func TestLazyClient_ReconnectsAfterExit(t *testing.T) {
+	conns := []*closableConn{newClosableConn(), newClosableConn()}
+	dials := 0
+	c := NewLazyClient(func() (client.MCPClient, error) {
+		conn := conns[dials]
+		dials++
+		return conn, nil
+	}, nil)
+
+	_, err := c.ListTools(context.Background(), mcp.ListToolsRequest{})
+	assert.NoError(t, err)
+
+	// 模拟服务器进程退出，下一次请求自动重连
+	close(conns[0].done)
+	_, err = c.ListTools(context.Background(), mcp.ListToolsRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, dials)
+	assert.Equal(t, StateReady, c.Status().State)
+	assert.Equal(t, 1, conns[0].closed)
+}
+
+func TestLazyClient_Backoff(t *testing.T) {
+	dials := 0
+	c := NewLazyClient(func() (client.MCPClient, error) {
+		dials++
+		return nil, errors.New("command not found")
+	}, nil)
+
+	// 第一次失败后立即重试，第二次失败后进入退避
+	for i := 0; i < 3; i++ {
+		_, err := c.ListTools(context.Background(), mcp.ListToolsRequest{})
+		assert.Error(t, err)
+	}
+	assert.Equal(t, 2, dials)
+
+	status := c.Status()
+	assert.Equal(t, StateFailed, status.State)
+	assert.Equal(t, "command not found", status.LastError)
+	assert.Equal(t, 2, status.Failures)
+}
+
+func TestClient_InitializeError(t *testing.T) {
+	conn := new(MockMCPClient)
+	conn.On("Initialize", mock.Anything, mock.Anything).Return(nil, errors.New("bad handshake"))
+
+	c := NewClient(conn, nil)
+	status := c.Status()
+	assert.Equal(t, StateFailed, status.State)
+	assert.Equal(t, "bad handshake", status.LastError)
+}
+
+func TestClient_Timeout(t *testing.T) {
+	conn := newClosableConn()
+	conn.ping = func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	c := NewLazyClient(func() (client.MCPClient, error) { return conn, nil }, nil, WithTimeout(20*time.Millisecond))
+
+	start := time.Now()
+	err := c.Ping(context.Background())
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+
+	// 健康检查失败后标记为 failed
+	c.checkHealth(context.Background())
+	assert.Equal(t, StateFailed, c.Status().State)
+}
+
+func TestReconnectDelay(t *testing.T) {
+	assert.Equal(t, time.Duration(0), reconnectDelay(1))
+	assert.Equal(t, time.Second, reconnectDelay(2))
+	assert.Equal(t, 4*time.Second, reconnectDelay(4))
+	assert.Equal(t, time.Minute, reconnectDelay(20))
+	assert.Equal(t, time.Minute, reconnectDelay(200))
+}
@@ -0,0 +1,387 @@
+package wnmcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"sync/atomic"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ErrConnectionClosed 表示与服务器的连接已经断开
+var ErrConnectionClosed = errors.New("connection closed")
+
+// RequestHandler 处理服务器发给客户端的请求，返回值作为响应的 result
+type RequestHandler func(ctx context.Context, params json.RawMessage) (interface{}, error)
+
+// StdioClient 通过子进程的标准输入输出与 MCP 服务器通信
+// 与 mcp-go 的 StdioMCPClient 不同，它可以响应服务器发起的请求，如 sampling/createMessage
+type StdioClient struct {
+	cmd       *exec.Cmd
+	stdin     io.WriteCloser
+	stdout    *bufio.Reader
+	writeMu   sync.Mutex
+	requestID atomic.Int64
+
+	mu            sync.RWMutex
+	responses     map[int64]chan rpcResponse
+	handlers      map[string]RequestHandler
+	notifications []func(mcp.JSONRPCNotification)
+	initialized   bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+type rpcResponse struct {
+	result json.RawMessage
+	err    error
+}
+
+// rpcMessage 可以是请求、响应或通知，ID 可以是数字或字符串，响应时原样返回
+type rpcMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// NewStdioClient 启动子进程并创建客户端
+func NewStdioClient(command string, env []string, args ...string) (*StdioClient, error) {
+	cmd := exec.Command(command, args...)
+	cmd.Env = append(os.Environ(), env...)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdin pipe: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start command: %w", err)
+	}
+
+	c := newStdioClient(stdin, stdout)
+	c.cmd = cmd
+	return c, nil
+}
+
+func newStdioClient(stdin io.WriteCloser, stdout io.Reader) *StdioClient {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &StdioClient{
+		stdin:     stdin,
+		stdout:    bufio.NewReader(stdout),
+		responses: make(map[int64]chan rpcResponse),
+		handlers:  make(map[string]RequestHandler),
+		ctx:       ctx,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+	}
+	go c.readMessages()
+	return c
+}
+
+// OnRequest 注册服务器请求的处理函数，需要在 Initialize 之前注册才会声明对应的能力
+func (c *StdioClient) OnRequest(method string, handler RequestHandler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.handlers[method] = handler
+}
+
+// OnNotification 注册通知处理函数
+func (c *StdioClient) OnNotification(handler func(notification mcp.JSONRPCNotification)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.notifications = append(c.notifications, handler)
+}
+
+// Done 在服务器断开连接（如进程退出）后关闭
+func (c *StdioClient) Done() <-chan struct{} {
+	return c.done
+}
+
+// Close 关闭标准输入并等待子进程退出
+func (c *StdioClient) Close() error {
+	c.cancel()
+	if err := c.stdin.Close(); err != nil {
+		return fmt.Errorf("failed to close stdin: %w", err)
+	}
+	if c.cmd != nil {
+		return c.cmd.Wait()
+	}
+	return nil
+}
+
+func (c *StdioClient) readMessages() {
+	defer close(c.done)
+	for {
+		line, err := c.stdout.ReadBytes('\n')
+		if err != nil {
+			c.failPending(fmt.Errorf("%w: %v", ErrConnectionClosed, err))
+			return
+		}
+
+		var msg rpcMessage
+		if err := json.Unmarshal(line, &msg); err != nil {
+			continue
+		}
+
+		switch {
+		case len(msg.ID) == 0 || string(msg.ID) == "null":
+			var notification mcp.JSONRPCNotification
+			if err := json.Unmarshal(line, &notification); err != nil {
+				continue
+			}
+			c.mu.RLock()
+			handlers := append([]func(mcp.JSONRPCNotification){}, c.notifications...)
+			c.mu.RUnlock()
+			for _, handler := range handlers {
+				handler(notification)
+			}
+		case msg.Method != "":
+			go c.handleRequest(msg)
+		default:
+			// 客户端发出的请求都使用数字 ID
+			var id int64
+			if err := json.Unmarshal(msg.ID, &id); err != nil {
+				continue
+			}
+			c.mu.Lock()
+			ch, ok := c.responses[id]
+			delete(c.responses, id)
+			c.mu.Unlock()
+			if !ok {
+				continue
+			}
+			if msg.Error != nil {
+				ch <- rpcResponse{err: errors.New(msg.Error.Message)}
+			} else {
+				ch <- rpcResponse{result: msg.Result}
+			}
+		}
+	}
+}
+
+// handleRequest 调用注册的处理函数并把结果写回服务器
+func (c *StdioClient) handleRequest(msg rpcMessage) {
+	c.mu.RLock()
+	handler, ok := c.handlers[msg.Method]
+	c.mu.RUnlock()
+
+	response := map[string]interface{}{
+		"jsonrpc": mcp.JSONRPC_VERSION,
+		"id":      msg.ID,
+	}
+	if !ok {
+		response["error"] = rpcError{Code: mcp.METHOD_NOT_FOUND, Message: fmt.Sprintf("Method %s not found", msg.Method)}
+	} else if result, err := handler(c.ctx, msg.Params); err != nil {
+		response["error"] = rpcError{Code: mcp.INTERNAL_ERROR, Message: err.Error()}
+	} else {
+		response["result"] = result
+	}
+	c.write(response)
+}
+
+func (c *StdioClient) failPending(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, ch := range c.responses {
+		ch <- rpcResponse{err: err}
+		delete(c.responses, id)
+	}
+}
+
+func (c *StdioClient) write(message interface{}) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_, err = c.stdin.Write(append(data, '\n'))
+	return err
+}
+
+// call 发送请求并把响应的 result 解析到 result 中，result 为 nil 时忽略响应内容
+func (c *StdioClient) call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	c.mu.RLock()
+	initialized := c.initialized
+	c.mu.RUnlock()
+	if !initialized && method != string(mcp.MethodInitialize) {
+		return fmt.Errorf("client not initialized")
+	}
+
+	id := c.requestID.Add(1)
+	ch := make(chan rpcResponse, 1)
+	c.mu.Lock()
+	c.responses[id] = ch
+	c.mu.Unlock()
+
+	request := mcp.JSONRPCRequest{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		ID:      id,
+		Request: mcp.Request{Method: method},
+		Params:  params,
+	}
+	if err := c.write(request); err != nil {
+		c.mu.Lock()
+		delete(c.responses, id)
+		c.mu.Unlock()
+		return fmt.Errorf("%w: failed to write request: %v", ErrConnectionClosed, err)
+	}
+
+	select {
+	case <-c.done:
+		// 读取循环退出前可能已经把响应写入 ch
+		select {
+		case resp := <-ch:
+			return c.decode(resp, result)
+		default:
+		}
+		return ErrConnectionClosed
+	case <-ctx.Done():
+		c.mu.Lock()
+		delete(c.responses, id)
+		c.mu.Unlock()
+		return ctx.Err()
+	case resp := <-ch:
+		return c.decode(resp, result)
+	}
+}
+
+func (c *StdioClient) decode(resp rpcResponse, result interface{}) error {
+	if resp.err != nil {
+		return resp.err
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(resp.result, result); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return nil
+}
+
+func (c *StdioClient) Initialize(ctx context.Context, request mcp.InitializeRequest) (*mcp.InitializeResult, error) {
+	params := request.Params
+	c.mu.RLock()
+	if _, ok := c.handlers[MethodSamplingCreateMessage]; ok {
+		params.Capabilities.Sampling = &struct{}{}
+	}
+	c.mu.RUnlock()
+
+	var result mcp.InitializeResult
+	if err := c.call(ctx, string(mcp.MethodInitialize), params, &result); err != nil {
+		return nil, err
+	}
+
+	err := c.write(mcp.JSONRPCNotification{
+		JSONRPC:      mcp.JSONRPC_VERSION,
+		Notification: mcp.Notification{Method: "notifications/initialized"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send initialized notification: %w", err)
+	}
+
+	c.mu.Lock()
+	c.initialized = true
+	c.mu.Unlock()
+	return &result, nil
+}
+
+func (c *StdioClient) Ping(ctx context.Context) error {
+	return c.call(ctx, string(mcp.MethodPing), nil, nil)
+}
+
+func (c *StdioClient) ListResources(ctx context.Context, request mcp.ListResourcesRequest) (*mcp.ListResourcesResult, error) {
+	var result mcp.ListResourcesResult
+	if err := c.call(ctx, string(mcp.MethodResourcesList), request.Params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *StdioClient) ListResourceTemplates(ctx context.Context, request mcp.ListResourceTemplatesRequest) (*mcp.ListResourceTemplatesResult, error) {
+	var result mcp.ListResourceTemplatesResult
+	if err := c.call(ctx, string(mcp.MethodResourcesTemplatesList), request.Params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *StdioClient) ReadResource(ctx context.Context, request mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	var raw json.RawMessage
+	if err := c.call(ctx, string(mcp.MethodResourcesRead), request.Params, &raw); err != nil {
+		return nil, err
+	}
+	return mcp.ParseReadResourceResult(&raw)
+}
+
+func (c *StdioClient) Subscribe(ctx context.Context, request mcp.SubscribeRequest) error {
+	return c.call(ctx, methodResourcesSubscribe, request.Params, nil)
+}
+
+func (c *StdioClient) Unsubscribe(ctx context.Context, request mcp.UnsubscribeRequest) error {
+	return c.call(ctx, methodResourcesUnsubscribe, request.Params, nil)
+}
+
+func (c *StdioClient) ListPrompts(ctx context.Context, request mcp.ListPromptsRequest) (*mcp.ListPromptsResult, error) {
+	var result mcp.ListPromptsResult
+	if err := c.call(ctx, string(mcp.MethodPromptsList), request.Params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *StdioClient) GetPrompt(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	var raw json.RawMessage
+	if err := c.call(ctx, string(mcp.MethodPromptsGet), request.Params, &raw); err != nil {
+		return nil, err
+	}
+	return mcp.ParseGetPromptResult(&raw)
+}
+
+func (c *StdioClient) ListTools(ctx context.Context, request mcp.ListToolsRequest) (*mcp.ListToolsResult, error) {
+	var result mcp.ListToolsResult
+	if err := c.call(ctx, string(mcp.MethodToolsList), request.Params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *StdioClient) CallTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var raw json.RawMessage
+	if err := c.call(ctx, string(mcp.MethodToolsCall), request.Params, &raw); err != nil {
+		return nil, err
+	}
+	return mcp.ParseCallToolResult(&raw)
+}
+
+func (c *StdioClient) SetLevel(ctx context.Context, request mcp.SetLevelRequest) error {
+	return c.call(ctx, "logging/setLevel", request.Params, nil)
+}
+
+func (c *StdioClient) Complete(ctx context.Context, request mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+	var result mcp.CompleteResult
+	if err := c.call(ctx, "completion/complete", request.Params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,117 @@
+package wnmcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pipeServer 模拟通过标准输入输出通信的服务器
+type pipeServer struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func newPipeClient() (*StdioClient, *pipeServer) {
+	clientIn, serverOut := io.Pipe()
+	serverIn, clientOut := io.Pipe()
+	c := newStdioClient(clientOut, clientIn)
+	return c, &pipeServer{in: bufio.NewReader(serverIn), out: serverOut}
+}
+
+func (s *pipeServer) read(t *testing.T) map[string]interface{} {
+	line, err := s.in.ReadBytes('\n')
+	require.NoError(t, err)
+	var msg map[string]interface{}
+	require.NoError(t, json.Unmarshal(line, &msg))
+	return msg
+}
+
+func (s *pipeServer) write(t *testing.T, msg interface{}) {
+	data, err := json.Marshal(msg)
+	require.NoError(t, err)
+	_, err = s.out.Write(append(data, '\n'))
+	require.NoError(t, err)
+}
+
+func TestStdioClient_InitializeAndSampling(t *testing.T) {
+	c, server := newPipeClient()
+	c.OnRequest(MethodSamplingCreateMessage, func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		return map[string]string{"echo": string(params)}, nil
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.Initialize(context.Background(), NewInitializeRequest())
+		done <- err
+	}()
+
+	req := server.read(t)
+	assert.Equal(t, "initialize", req["method"])
+	capabilities := req["params"].(map[string]interface{})["capabilities"].(map[string]interface{})
+	assert.Contains(t, capabilities, "sampling")
+
+	server.write(t, map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      req["id"],
+		"result":  map[string]interface{}{"protocolVersion": mcp.LATEST_PROTOCOL_VERSION},
+	})
+	assert.Equal(t, "notifications/initialized", server.read(t)["method"])
+	require.NoError(t, <-done)
+
+	// 服务器发起 sampling 请求
+	server.write(t, map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      7,
+		"method":  MethodSamplingCreateMessage,
+		"params":  map[string]interface{}{"maxTokens": 1},
+	})
+	resp := server.read(t)
+	assert.Equal(t, float64(7), resp["id"])
+	assert.Equal(t, `{"maxTokens":1}`, resp["result"].(map[string]interface{})["echo"])
+
+	// 未注册的方法返回 method not found
+	server.write(t, map[string]interface{}{"jsonrpc": "2.0", "id": 8, "method": "roots/list"})
+	resp = server.read(t)
+	assert.Equal(t, float64(mcp.METHOD_NOT_FOUND), resp["error"].(map[string]interface{})["code"])
+
+	// 字符串 ID 原样返回
+	server.write(t, map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      "req-9",
+		"method":  MethodSamplingCreateMessage,
+		"params":  map[string]interface{}{"maxTokens": 2},
+	})
+	resp = server.read(t)
+	assert.Equal(t, "req-9", resp["id"])
+	assert.Equal(t, `{"maxTokens":2}`, resp["result"].(map[string]interface{})["echo"])
+}
+
+func TestStdioClient_Notification(t *testing.T) {
+	c, server := newPipeClient()
+	received := make(chan mcp.JSONRPCNotification, 1)
+	c.OnNotification(func(n mcp.JSONRPCNotification) {
+		received <- n
+	})
+
+	server.write(t, map[string]interface{}{"jsonrpc": "2.0", "method": "notifications/tools/list_changed"})
+	select {
+	case n := <-received:
+		assert.Equal(t, "notifications/tools/list_changed", n.Method)
+	case <-time.After(time.Second):
+		t.Fatal("notification not received")
+	}
+}
+
+func TestStdioClient_NotInitialized(t *testing.T) {
+	c, _ := newPipeClient()
+	err := c.Ping(context.Background())
+	assert.Error(t, err)
+}
@@ -0,0 +1,177 @@
+package wnmcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	methodResourcesSubscribe   = "resources/subscribe"
+	methodResourcesUnsubscribe = "resources/unsubscribe"
+	methodResourcesUpdated     = "notifications/resources/updated"
+)
+
+// Subscriptions 记录客户端订阅的资源，并在资源变化时发送通知
+// mcp-go 不处理 resources/subscribe 请求，因此在传输层拦截订阅请求，
+// 记录后改写为 ping 请求交给服务器，两者的响应都是空结果
+type Subscriptions struct {
+	server   *server.MCPServer
+	mu       sync.RWMutex
+	stdio    string                          // stdio 会话的 ID
+	sessions map[string]server.ClientSession // 会话 ID 到会话
+	uris     map[string]map[string]bool      // 会话 ID 到订阅的 URI
+}
+
+// NewSubscriptions 创建订阅管理器
+func NewSubscriptions(s *server.MCPServer) *Subscriptions {
+	return &Subscriptions{
+		server:   s,
+		sessions: make(map[string]server.ClientSession),
+		uris:     make(map[string]map[string]bool),
+	}
+}
+
+// ServeStdio 通过标准输入输出提供服务，并处理订阅请求，直到 ctx 结束或输入关闭
+func (s *Subscriptions) ServeStdio(ctx context.Context, in io.Reader, out io.Writer) error {
+	stdio := server.NewStdioServer(s.server)
+	stdio.SetContextFunc(func(ctx context.Context) context.Context {
+		if session := server.ClientSessionFromContext(ctx); session != nil {
+			s.mu.Lock()
+			s.stdio = session.SessionID()
+			s.sessions[session.SessionID()] = session
+			s.mu.Unlock()
+		}
+		return ctx
+	})
+
+	reader, writer := io.Pipe()
+	go func() {
+		scanner := bufio.NewScanner(in)
+		scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+		for scanner.Scan() {
+			s.mu.RLock()
+			sessionID := s.stdio
+			s.mu.RUnlock()
+
+			line := s.intercept(sessionID, scanner.Bytes())
+			if _, err := writer.Write(append(line, '\n')); err != nil {
+				return
+			}
+		}
+		writer.CloseWithError(scanner.Err())
+	}()
+
+	return stdio.Listen(ctx, reader, out)
+}
+
+// SSEContextFunc 记录 SSE 会话，通过 server.WithSSEContextFunc 注册
+func (s *Subscriptions) SSEContextFunc(ctx context.Context, r *http.Request) context.Context {
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		s.mu.Lock()
+		s.sessions[session.SessionID()] = session
+		s.mu.Unlock()
+	}
+	return ctx
+}
+
+// WrapSSE 拦截发往 SSE 消息端点的订阅请求
+func (s *Subscriptions) WrapSSE(sse *server.SSEServer) http.Handler {
+	messagePath := sse.CompleteMessagePath()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path == messagePath {
+			body, err := io.ReadAll(r.Body)
+			if err == nil {
+				body = s.intercept(r.URL.Query().Get("sessionId"), body)
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			r.ContentLength = int64(len(body))
+		}
+		sse.ServeHTTP(w, r)
+	})
+}
+
+// Subscribed 返回是否有客户端订阅了 URI
+func (s *Subscriptions) Subscribed(uri string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, uris := range s.uris {
+		if uris[uri] {
+			return true
+		}
+	}
+	return false
+}
+
+// Notify 向订阅了 URI 的客户端发送 notifications/resources/updated
+// 发送失败的会话视为已断开，会被移除
+func (s *Subscriptions) Notify(uri string) {
+	s.mu.RLock()
+	var targets []server.ClientSession
+	for sessionID, uris := range s.uris {
+		if session, ok := s.sessions[sessionID]; ok && uris[uri] {
+			targets = append(targets, session)
+		}
+	}
+	s.mu.RUnlock()
+
+	for _, session := range targets {
+		ctx := s.server.WithContext(context.Background(), session)
+		err := s.server.SendNotificationToClient(ctx, methodResourcesUpdated, map[string]any{"uri": uri})
+		if err != nil {
+			s.remove(session.SessionID())
+		}
+	}
+}
+
+// intercept 记录订阅或取消订阅，并将请求改写为 ping，其余消息原样返回
+func (s *Subscriptions) intercept(sessionID string, message []byte) []byte {
+	var req struct {
+		ID     any    `json:"id"`
+		Method string `json:"method"`
+		Params struct {
+			URI string `json:"uri"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(message, &req); err != nil || req.ID == nil {
+		return message
+	}
+	if req.Method != methodResourcesSubscribe && req.Method != methodResourcesUnsubscribe {
+		return message
+	}
+
+	s.mu.Lock()
+	if req.Method == methodResourcesSubscribe {
+		if s.uris[sessionID] == nil {
+			s.uris[sessionID] = make(map[string]bool)
+		}
+		s.uris[sessionID][req.Params.URI] = true
+	} else {
+		delete(s.uris[sessionID], req.Params.URI)
+	}
+	s.mu.Unlock()
+
+	ping, err := json.Marshal(map[string]any{
+		"jsonrpc": mcp.JSONRPC_VERSION,
+		"id":      req.ID,
+		"method":  string(mcp.MethodPing),
+	})
+	if err != nil {
+		return message
+	}
+	return ping
+}
+
+func (s *Subscriptions) remove(sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, sessionID)
+	delete(s.uris, sessionID)
+}
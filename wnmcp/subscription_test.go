@@ -0,0 +1,69 @@
+package wnmcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscriptions_ServeStdio(t *testing.T) {
+	srv := server.NewMCPServer("test", "1.0.0", server.WithResourceCapabilities(true, false))
+	subs := NewSubscriptions(srv)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	inReader, inWriter := io.Pipe()
+	outReader, outWriter := io.Pipe()
+	go subs.ServeStdio(ctx, inReader, outWriter)
+
+	lines := bufio.NewScanner(outReader)
+	send := func(msg string) {
+		_, err := inWriter.Write([]byte(msg + "\n"))
+		require.NoError(t, err)
+	}
+	receive := func() map[string]any {
+		done := make(chan bool)
+		var msg map[string]any
+		go func() {
+			if lines.Scan() {
+				json.Unmarshal(lines.Bytes(), &msg)
+			}
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(2 * time.Second):
+			t.Fatal("timeout waiting for message")
+		}
+		return msg
+	}
+
+	send(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","clientInfo":{"name":"t","version":"1"},"capabilities":{}}}`)
+	receive()
+	send(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+
+	send(`{"jsonrpc":"2.0","id":2,"method":"resources/subscribe","params":{"uri":"wn://a.go"}}`)
+	resp := receive()
+	assert.EqualValues(t, 2, resp["id"])
+	assert.Nil(t, resp["error"])
+	assert.True(t, subs.Subscribed("wn://a.go"))
+
+	subs.Notify("wn://b.go")
+	subs.Notify("wn://a.go")
+	notification := receive()
+	assert.Equal(t, "notifications/resources/updated", notification["method"])
+	assert.Equal(t, map[string]any{"uri": "wn://a.go"}, notification["params"])
+
+	send(`{"jsonrpc":"2.0","id":3,"method":"resources/unsubscribe","params":{"uri":"wn://a.go"}}`)
+	resp = receive()
+	assert.EqualValues(t, 3, resp["id"])
+	assert.False(t, subs.Subscribed("wn://a.go"))
+}